@@ -0,0 +1,92 @@
+package annotation
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"sync"
+)
+
+// Source 带位置信息的注释内容
+type Source struct {
+	Body string
+	Pos  token.Position // 注释内容第一个字符的位置
+}
+
+// Position 计算注释内容中 offset 处的位置
+func (s Source) Position(offset int) token.Position {
+	pos := s.Pos
+	if offset > 0 && offset <= len(s.Body) {
+		pos.Offset += offset
+		pos.Column += offset
+	}
+	return pos
+}
+
+// Parse 解析注释内容, 错误会带上位置信息
+func (s Source) Parse() (*Body, error) {
+	body, err := Parse(s.Body)
+	if err != nil {
+		return nil, s.Wrap(err)
+	}
+	return body, nil
+}
+
+// Wrap 将 *Error 转换为带文件位置的 *scanner.Error
+func (s Source) Wrap(err error) error {
+	var e *scanner.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var ae *Error
+	if errors.As(err, &ae) {
+		return &scanner.Error{Pos: s.Position(ae.Offset), Msg: ae.Msg}
+	}
+	return &scanner.Error{Pos: s.Pos, Msg: err.Error()}
+}
+
+// Diagnostics 收集所有错误, 在生成结束时统一报告
+type Diagnostics struct {
+	mu   sync.Mutex
+	list scanner.ErrorList
+}
+
+// Report 记录 src 中的错误
+func (d *Diagnostics) Report(src Source, err error) {
+	if err == nil {
+		return
+	}
+	d.add(src.Wrap(err).(*scanner.Error))
+}
+
+// ReportList 记录已带位置信息的错误, 例如 FindAnnotations 返回的 scanner.ErrorList
+func (d *Diagnostics) ReportList(err error) {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		d.Report(Source{}, err)
+		return
+	}
+	for _, e := range list {
+		d.add(e)
+	}
+}
+
+// Errorf 记录一个指定位置的错误
+func (d *Diagnostics) Errorf(pos token.Position, format string, args ...any) {
+	d.add(&scanner.Error{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (d *Diagnostics) add(err *scanner.Error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.list = append(d.list, err)
+}
+
+// Err 返回按位置排序后的错误列表, 没有错误时返回 nil
+func (d *Diagnostics) Err() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.list.Sort()
+	return d.list.Err()
+}
@@ -0,0 +1,138 @@
+package annotation
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind 词法单元类型
+type TokenKind int
+
+const (
+	EOF    TokenKind = iota
+	WORD             // 标识符或裸值片段, 例如 func:name, args::field, true, CreateHookRejected
+	STRING           // 双引号字符串, Text 为反转义后的内容
+	ASSIGN           // =
+	SEMI             // ;
+	COMMA            // ,
+	LBRACK           // [
+	RBRACK           // ]
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case EOF:
+		return "end of annotation"
+	case WORD:
+		return "word"
+	case STRING:
+		return "string"
+	case ASSIGN:
+		return "'='"
+	case SEMI:
+		return "';'"
+	case COMMA:
+		return "','"
+	case LBRACK:
+		return "'['"
+	case RBRACK:
+		return "']'"
+	}
+	return "unknown"
+}
+
+var punct = map[byte]TokenKind{'=': ASSIGN, ';': SEMI, ',': COMMA, '[': LBRACK, ']': RBRACK}
+
+// Token 词法单元, Offset/End 为在注释内容中的字节偏移
+type Token struct {
+	Kind   TokenKind
+	Text   string
+	Offset int
+	End    int
+}
+
+// Lexer 注释内容的词法分析器
+//
+// 字符串使用双引号, 支持的转义: \" \\ \n \t, 其他反斜杠序列原样保留
+type Lexer struct {
+	src string
+	pos int
+}
+
+func NewLexer(src string) *Lexer {
+	return &Lexer{src: src}
+}
+
+// Next 返回下一个词法单元
+func (l *Lexer) Next() (Token, error) {
+	l.skipSpace()
+	if l.pos >= len(l.src) {
+		return Token{Kind: EOF, Offset: l.pos, End: l.pos}, nil
+	}
+	start := l.pos
+	c := l.src[l.pos]
+	if kind, ok := punct[c]; ok {
+		l.pos++
+		return Token{Kind: kind, Text: string(c), Offset: start, End: l.pos}, nil
+	}
+	if c == '"' {
+		return l.scanString()
+	}
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if unicode.IsSpace(r) || r == '"' {
+			break
+		}
+		if _, ok := punct[l.src[l.pos]]; ok {
+			break
+		}
+		l.pos += size
+	}
+	return Token{Kind: WORD, Text: l.src[start:l.pos], Offset: start, End: l.pos}, nil
+}
+
+func (l *Lexer) scanString() (Token, error) {
+	start := l.pos
+	l.pos++ // 跳过开头的引号
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return Token{Kind: STRING, Text: b.String(), Offset: start, End: l.pos}, nil
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				l.pos++
+				continue
+			}
+			switch next := l.src[l.pos+1]; next {
+			case '"', '\\':
+				b.WriteByte(next)
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(c)
+				b.WriteByte(next)
+			}
+			l.pos += 2
+		default:
+			b.WriteByte(c)
+			l.pos++
+		}
+	}
+	return Token{}, Errorf(start, "unterminated string")
+}
+
+func (l *Lexer) skipSpace() {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		l.pos += size
+	}
+}
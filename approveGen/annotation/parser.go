@@ -0,0 +1,293 @@
+package annotation
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 注释语法:
+//
+//	body      = clause { ";" clause } [ ";" ]
+//	clause    = key [ "[" word "]" ] [ "=" value ]
+//	key       = word                                   // 例如 func:name, args::field, global::template
+//	value     = string | list | bare
+//	list      = "[" [ value { "," value } [ "," ] ] "]"
+//	bare      = word { word | "," }                    // 到 ";" 为止的原始文本, 列表内到 "," 为止
+//
+// 例子:
+//
+//	func:name="approveFor"; nest=true; args=["name string", "codes github.com/a/b#EString"]
+//	args::note="包含 \"引号\" 的说明"
+
+// ValueKind 值的类型
+type ValueKind int
+
+const (
+	Bare   ValueKind = iota // 未加引号的值
+	String                  // 双引号字符串
+	List                    // [a, b, c]
+)
+
+// Value 子句的值
+type Value struct {
+	Kind   ValueKind
+	Text   string   // Bare/String 的内容
+	Items  []*Value // List 的元素
+	Offset int
+}
+
+// Strings 将值展开为字符串列表; List 取每个元素, 其他类型按逗号分割
+func (v *Value) Strings() []string {
+	if v == nil {
+		return nil
+	}
+	var ret []string
+	if v.Kind == List {
+		for _, item := range v.Items {
+			ret = append(ret, item.Text)
+		}
+		return ret
+	}
+	for _, item := range strings.Split(v.Text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+// Clause 一个 key=value 子句
+type Clause struct {
+	Key       string
+	Qualifier string // args::note[en] ==> en
+	Value     *Value // 没有 "=" 时为 nil
+	Offset    int
+}
+
+// Text 返回值的文本内容, 没有值或值为列表时返回空字符串
+func (c *Clause) Text() string {
+	if c == nil || c.Value == nil {
+		return ""
+	}
+	return c.Value.Text
+}
+
+// Bool 将值解析为布尔值, 没有值(例如 nest)视为 true
+func (c *Clause) Bool() (bool, error) {
+	if c.Value == nil {
+		return true, nil
+	}
+	b, err := strconv.ParseBool(c.Value.Text)
+	if err != nil || c.Value.Kind == List {
+		return false, Errorf(c.Value.Offset, "%s: %q is not a valid boolean", c.Key, c.Value.Text)
+	}
+	return b, nil
+}
+
+// Body 解析后的注释内容
+type Body struct {
+	Raw     string
+	Clauses []*Clause
+}
+
+// Head 第一个子句, 决定注释的种类
+func (b *Body) Head() *Clause {
+	if b == nil || len(b.Clauses) == 0 {
+		return nil
+	}
+	return b.Clauses[0]
+}
+
+// HeadKey 第一个子句的 key
+func (b *Body) HeadKey() string {
+	if h := b.Head(); h != nil {
+		return h.Key
+	}
+	return ""
+}
+
+// Lookup 按 key 查找子句
+func (b *Body) Lookup(key string) *Clause {
+	if b == nil {
+		return nil
+	}
+	for _, c := range b.Clauses {
+		if c.Key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// Has 是否存在 key
+func (b *Body) Has(key string) bool {
+	return b.Lookup(key) != nil
+}
+
+// CheckKeys 校验除 Head 外的子句 key 都在 allowed 中
+func (b *Body) CheckKeys(allowed ...string) error {
+	for _, c := range b.Clauses[1:] {
+		if !slices.Contains(allowed, c.Key) {
+			return Errorf(c.Offset, "unknown field %q in %s annotation", c.Key, b.HeadKey())
+		}
+	}
+	return nil
+}
+
+// Parse 解析一条注释的内容(括号内的部分)
+func Parse(src string) (*Body, error) {
+	p := &parser{lx: NewLexer(src), src: src}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	body := &Body{Raw: src}
+	seen := make(map[string]bool)
+	for p.tok.Kind != EOF {
+		if p.tok.Kind == SEMI {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		clause, err := p.parseClause()
+		if err != nil {
+			return nil, err
+		}
+		id := clause.Key + "[" + clause.Qualifier + "]"
+		if seen[id] {
+			return nil, Errorf(clause.Offset, "duplicate field %q", clause.Key)
+		}
+		seen[id] = true
+		body.Clauses = append(body.Clauses, clause)
+		if p.tok.Kind != SEMI && p.tok.Kind != EOF {
+			return nil, p.unexpected("';'")
+		}
+	}
+	return body, nil
+}
+
+type parser struct {
+	lx  *Lexer
+	src string
+	tok Token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lx.Next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	if p.tok.Kind == EOF {
+		return Errorf(p.tok.Offset, "unexpected %s, expected %s", p.tok.Kind, expected)
+	}
+	return Errorf(p.tok.Offset, "unexpected %s %q, expected %s", p.tok.Kind, p.src[p.tok.Offset:p.tok.End], expected)
+}
+
+func (p *parser) parseClause() (*Clause, error) {
+	if p.tok.Kind != WORD {
+		return nil, p.unexpected("field name")
+	}
+	clause := &Clause{Key: p.tok.Text, Offset: p.tok.Offset}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.Kind == LBRACK {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.Kind != WORD {
+			return nil, p.unexpected("qualifier")
+		}
+		clause.Qualifier = p.tok.Text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.Kind != RBRACK {
+			return nil, p.unexpected("']'")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.tok.Kind != ASSIGN {
+		return clause, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	value, err := p.parseValue(false)
+	if err != nil {
+		return nil, err
+	}
+	clause.Value = value
+	return clause, nil
+}
+
+func (p *parser) parseValue(inList bool) (*Value, error) {
+	switch p.tok.Kind {
+	case STRING:
+		v := &Value{Kind: String, Text: p.tok.Text, Offset: p.tok.Offset}
+		return v, p.advance()
+	case LBRACK:
+		return p.parseList()
+	case WORD:
+		start, end := p.tok.Offset, p.tok.End
+		for {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.tok.Kind == WORD || (p.tok.Kind == COMMA && !inList) {
+				end = p.tok.End
+				continue
+			}
+			break
+		}
+		return &Value{Kind: Bare, Text: p.src[start:end], Offset: start}, nil
+	}
+	return nil, p.unexpected("value")
+}
+
+func (p *parser) parseList() (*Value, error) {
+	list := &Value{Kind: List, Offset: p.tok.Offset}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	for p.tok.Kind != RBRACK {
+		item, err := p.parseValue(true)
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, item)
+		if p.tok.Kind == COMMA {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if p.tok.Kind != RBRACK {
+			return nil, p.unexpected("',' or ']'")
+		}
+	}
+	return list, p.advance()
+}
+
+// Error 注释错误, Offset 为相对注释内容的字节偏移
+type Error struct {
+	Offset int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func Errorf(offset int, format string, args ...any) *Error {
+	return &Error{Offset: offset, Msg: fmt.Sprintf(format, args...)}
+}
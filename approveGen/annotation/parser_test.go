@@ -0,0 +1,93 @@
+package annotation
+
+import (
+	"go/scanner"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	body, err := Parse(`func:name="approveFor"; module=ABC; nest; args=["name string", "codes a/b#C",]; args::note[en]="a \"quoted\" ; text\n"`)
+	require.NoError(t, err)
+	require.Len(t, body.Clauses, 5)
+
+	assert.Equal(t, "func:name", body.HeadKey())
+	assert.Equal(t, "approveFor", body.Head().Text())
+	assert.Equal(t, Bare, body.Lookup("module").Value.Kind)
+	assert.Equal(t, "ABC", body.Lookup("module").Text())
+
+	nest, err := body.Lookup("nest").Bool()
+	require.NoError(t, err)
+	assert.True(t, nest)
+
+	args := body.Lookup("args").Value
+	assert.Equal(t, List, args.Kind)
+	assert.Equal(t, []string{"name string", "codes a/b#C"}, args.Strings())
+
+	note := body.Lookup("args::note")
+	assert.Equal(t, "en", note.Qualifier)
+	assert.Equal(t, "a \"quoted\" ; text\n", note.Text())
+}
+
+func TestParseBareValue(t *testing.T) {
+	body, err := Parse(`args::string="$key"; include= a, b ,c; sep=" | "`)
+	require.NoError(t, err)
+	assert.Equal(t, "a, b ,c", body.Lookup("include").Text())
+	assert.Equal(t, []string{"a", "b", "c"}, body.Lookup("include").Value.Strings())
+	assert.Equal(t, " | ", body.Lookup("sep").Text())
+}
+
+func TestParseUnknownEscapeKept(t *testing.T) {
+	body, err := Parse(`global::template="Fn=a\.b"`)
+	require.NoError(t, err)
+	assert.Equal(t, `Fn=a\.b`, body.Head().Text())
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src    string
+		offset int
+	}{
+		{src: `args::note="unterminated`, offset: 11},
+		{src: `args::note="a" "b"`, offset: 15},
+		{src: `=x`, offset: 0},
+		{src: `args=["a" "b"]`, offset: 10},
+		{src: `a=1; a=2`, offset: 5},
+		{src: `a=`, offset: 2},
+		{src: `args::note[en="x"`, offset: 13},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		require.Error(t, err, tt.src)
+		var ae *Error
+		require.ErrorAs(t, err, &ae, tt.src)
+		assert.Equal(t, tt.offset, ae.Offset, tt.src)
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	body, err := Parse(`args::string="$key"; sep=","; bad=1`)
+	require.NoError(t, err)
+	err = body.CheckKeys("sep")
+	require.Error(t, err)
+	assert.Equal(t, 30, err.(*Error).Offset)
+}
+
+func TestDiagnostics(t *testing.T) {
+	src := Source{Body: `args::note="a" "b"`, Pos: token.Position{Filename: "a.go", Line: 3, Column: 13, Offset: 40}}
+	var diags Diagnostics
+	_, err := src.Parse()
+	diags.Report(src, err)
+	diags.Errorf(token.Position{Filename: "a.go", Line: 1, Column: 1}, "first")
+
+	err = diags.Err()
+	require.Error(t, err)
+	assert.Equal(t, "a.go:1:1: first (and 1 more errors)", err.Error())
+
+	list := err.(scanner.ErrorList)
+	require.Len(t, list, 2)
+	assert.Equal(t, `a.go:3:28: unexpected string "\"b\"", expected ';'`, list[1].Error())
+}
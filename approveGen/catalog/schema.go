@@ -0,0 +1,115 @@
+package catalog
+
+import (
+	"encoding/json"
+	"go/ast"
+	"strings"
+)
+
+// Schema JSON Schema 对象, encoding/json 输出时按 key 排序, 结果是稳定的
+type Schema map[string]any
+
+// Property 参数结构体的一个字段
+type Property struct {
+	Name     string   // 结构体字段名
+	Title    string   // args::field 的 alias
+	Type     ast.Expr // 参数类型
+	Optional bool     // 指针类型, 可以为 null
+}
+
+// ObjectSchema 生成参数结构体的 JSON Schema;
+// fullType 返回使用完整导入路径的类型名称, 用于识别 time.Time 等类型
+func ObjectSchema(title string, props []Property, fullType func(ast.Expr) string) Schema {
+	properties := make(map[string]Schema, len(props))
+	required := make([]string, 0, len(props))
+	for _, p := range props {
+		s := TypeSchema(p.Type, fullType)
+		if p.Title != "" {
+			s["title"] = p.Title
+		}
+		properties[p.Name] = s
+		if !p.Optional {
+			required = append(required, p.Name)
+		}
+	}
+	ret := Schema{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"type":       "object",
+		"properties": properties,
+	}
+	if title != "" {
+		ret["title"] = title
+	}
+	if len(required) > 0 {
+		ret["required"] = required
+	}
+	return ret
+}
+
+// TypeSchema Go 类型对应的 JSON Schema, 无法描述的类型返回带 x-go-type 的空 Schema
+func TypeSchema(expr ast.Expr, fullType func(ast.Expr) string) Schema {
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return TypeSchema(t.X, fullType)
+	case *ast.StarExpr:
+		s := TypeSchema(t.X, fullType)
+		if typ, ok := s["type"].(string); ok {
+			s["type"] = []string{typ, "null"}
+		}
+		return s
+	case *ast.Ellipsis:
+		return Schema{"type": "array", "items": TypeSchema(t.Elt, fullType)}
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && (ident.Name == "byte" || ident.Name == "uint8") {
+			// encoding/json 将 []byte 编码为 base64 字符串
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": TypeSchema(t.Elt, fullType)}
+	case *ast.MapType:
+		return Schema{"type": "object", "additionalProperties": TypeSchema(t.Value, fullType)}
+	case *ast.InterfaceType:
+		return Schema{}
+	case *ast.Ident:
+		if s := basicSchema(t.Name); s != nil {
+			return s
+		}
+	}
+	name := fullType(expr)
+	switch name {
+	case "time.Time":
+		return Schema{"type": "string", "format": "date-time"}
+	case "time.Duration":
+		return Schema{"type": "integer", "x-go-type": name}
+	case "encoding/json.RawMessage":
+		return Schema{}
+	}
+	return Schema{"x-go-type": name}
+}
+
+func basicSchema(name string) Schema {
+	switch name {
+	case "string":
+		return Schema{"type": "string"}
+	case "bool":
+		return Schema{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return Schema{"type": "integer"}
+	case "float32", "float64":
+		return Schema{"type": "number"}
+	case "any":
+		return Schema{}
+	}
+	return nil
+}
+
+// Marshal 紧凑的 JSON, 生成代码中作为字符串常量
+func (s Schema) Marshal() json.RawMessage {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		panic(err)
+	}
+	return json.RawMessage(strings.TrimSuffix(buf.String(), "\n"))
+}
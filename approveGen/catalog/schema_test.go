@@ -0,0 +1,58 @@
+package catalog
+
+import (
+	"go/ast"
+	"go/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeSchema(t *testing.T) {
+	fullType := func(expr ast.Expr) string {
+		if sel, ok := expr.(*ast.SelectorExpr); ok {
+			return sel.X.(*ast.Ident).Name + "." + sel.Sel.Name
+		}
+		return expr.(*ast.Ident).Name
+	}
+	tests := []struct {
+		typ  string
+		want string
+	}{
+		{typ: "string", want: `{"type":"string"}`},
+		{typ: "*int64", want: `{"type":["integer","null"]}`},
+		{typ: "[]float64", want: `{"items":{"type":"number"},"type":"array"}`},
+		{typ: "[]byte", want: `{"contentEncoding":"base64","type":"string"}`},
+		{typ: "map[string]bool", want: `{"additionalProperties":{"type":"boolean"},"type":"object"}`},
+		{typ: "time.Time", want: `{"format":"date-time","type":"string"}`},
+		{typ: "any", want: `{}`},
+		{typ: "Status", want: `{"x-go-type":"Status"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.typ)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(TypeSchema(expr, fullType).Marshal()))
+		})
+	}
+}
+
+func TestObjectSchema(t *testing.T) {
+	name, _ := parser.ParseExpr("string")
+	at, _ := parser.ParseExpr("*string")
+	s := ObjectSchema("开户", []Property{
+		{Name: "Name", Title: "户名", Type: name},
+		{Name: "At", Type: at, Optional: true},
+	}, nil)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "开户",
+		"type": "object",
+		"properties": {
+			"Name": {"type": "string", "title": "户名"},
+			"At": {"type": ["string", "null"]}
+		},
+		"required": ["Name"]
+	}`, string(s.Marshal()))
+}
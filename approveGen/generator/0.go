@@ -7,13 +7,29 @@ import (
 
 // GenMethodCallApprovalData 模板数据结构
 type GenMethodCallApprovalData struct {
+	Config
 	GenMethodName          string
 	AddUnmarshalMethodArgs bool
 	Methods                []MyMethod
 	EveryMethodSuffix      string
 	DefaultSuccess         bool
 	GetType                func(typ ast.Expr, method MyMethod) string
-	HookRejectedMap        map[string]bool // v2版本使用，标记哪些方法支持 HookRejected
+	HookRejectedMap        map[string]bool // 标记哪些方法支持 HookRejected
+	LibPkg                 string
+	Migrations             map[string][]Migration // key: GenMethod()
+	Decision               bool                   // 生成 CallDecision, Call 作为它的 shim
+	Previews               map[string]string      // key: GenMethod(), value: 预览方法名
+}
+
+// Migration 将旧版本参数升级到当前版本的函数
+type Migration struct {
+	From string // 旧版本的 hash
+	Func string // func(content []byte) (*_XxxMethodYyy, error)
+}
+
+type FormatterMethod struct {
+	MethodName string
+	Signature  string
 }
 
 type MyMethod = types.MyMethod
@@ -0,0 +1,699 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"strings"
+	"text/template"
+
+	"github.com/dave/jennifer/jen"
+	methods2 "github.com/donutnomad/gotoolkit/approveGen/methods"
+	"github.com/donutnomad/gotoolkit/approveGen/types"
+	"github.com/donutnomad/gotoolkit/approveGen/utils"
+)
+
+// Config 生成器的可选能力
+//
+// 命令行参数与能力的对应关系:
+//
+//	(默认)  自由函数 CallMethodForApproval(a *AllServices, ...), HookRejected 单独生成 CallMethodForApprovalHookRejected
+//	-v2     HookRejected
+//	-v3     CallerStruct + HookRejected
+//	-v4     CallerStruct + HookRejected + FormatterArg
+type Config struct {
+	// CallerStruct 生成 ApprovalCaller 结构体及 IApprovalFormatter 接口, 否则生成自由函数
+	CallerStruct bool
+	// HookRejected 在 Call 中根据 approved 分发到 XxxHookRejected
+	HookRejected bool
+	// FuncArgs func:name 生成的方法支持自定义 args
+	FuncArgs bool
+	// FormatterArg func:name 生成的方法追加 formatter IApprovalFormatter 参数
+	FormatterArg bool
+	// PkgPrefix MethodName() 的包名前缀
+	PkgPrefix string
+	// Proxy 为每个结构体生成 XxxApprovalProxy, 将 @Approve 方法转换为审批请求
+	Proxy bool
+	// Codec 参数结构体的序列化方式, 为空时使用 sonic
+	Codec Codec
+	// Strict 生成可以直接编译的代码: 删除没有使用的 import, func:name 返回 (T..., error) 而模板只返回 error 时其余返回值使用零值
+	Strict bool
+}
+
+// ConfigFromFlags 将 -v2/-v3/-v4/-pkgname 参数转换为 Config
+func ConfigFromFlags(v2, v3, v4 bool, pkgName string) Config {
+	if v4 {
+		v3 = true
+	}
+	return Config{
+		CallerStruct: v3,
+		HookRejected: v2 || v3,
+		FuncArgs:     v2,
+		FormatterArg: v4,
+		PkgPrefix:    pkgName,
+	}
+}
+
+// CallName 审批回调方法的名称
+func (c Config) CallName() string {
+	if c.CallerStruct {
+		return "Call"
+	}
+	return "CallMethodForApproval"
+}
+
+// Imports 审批回调代码需要的额外 import
+func (c Config) Imports() []string {
+	var ret []string
+	if path := c.Codec.Import(); path != "" {
+		ret = append(ret, path)
+	}
+	if c.HookRejected {
+		ret = append(ret, "errors")
+	}
+	return ret
+}
+
+// CodecFunc 生成代码中 Marshal/Unmarshal 函数的表达式
+func (g *Generator) CodecFunc(name string) string {
+	return g.Codec.Func(name, g.LibPkg)
+}
+
+// DisplayMarshal 生成代码中 Json() 使用的 Marshal 函数的表达式
+func (g *Generator) DisplayMarshal() string {
+	return g.Codec.DisplayFunc(g.LibPkg)
+}
+
+// Generator 根据 Config 生成审批回调代码
+type Generator struct {
+	Config
+	GetType func(typ ast.Expr, method MyMethod) string
+	// LibPkg lib/approvegen 在生成文件中的包名, 为空表示生成代码不依赖 lib/approvegen
+	LibPkg string
+	// Migrations UnmarshalMethodArgs 中旧版本参数的迁移函数, key: GenMethod()
+	Migrations map[string][]Migration
+	// Redacted 有脱敏字段的方法, Format 使用 Redacted() 的副本, key: GenMethod()
+	Redacted map[string]bool
+	// Captured 有 ctx::capture 的方法, 调用前使用 RestoreCtx() 写回 ctx, key: GenMethod()
+	Captured map[string]bool
+	// Decision 有方法声明了 lib/approvegen.Decision 参数, ApprovalCaller 生成 CallDecision, Call 作为它的 shim
+	Decision bool
+	// Previews 有 func::preview 的方法, ApprovalCaller 生成 Preview, key: GenMethod(), value: 目标上的预览方法名
+	Previews map[string]string
+}
+
+// IsDecision 是否为 lib/approvegen.Decision 类型, typ 为 GetType 的结果
+func (g *Generator) IsDecision(typ string) bool {
+	return g.LibPkg != "" && typ == g.LibPkg+".Decision"
+}
+
+// IgnoreParam 不保存到参数结构体的参数: context.Context 以及审批时才产生的 Decision
+func (g *Generator) IgnoreParam(typ string) bool {
+	return typ == "context.Context" || g.IsDecision(typ)
+}
+
+// decisionArg 调用回调时 Decision 参数的值, CallDecision 中为参数 d, 其他情况从 ctx 中获取
+func (g *Generator) decisionArg() string {
+	if g.CallerStruct && g.Decision {
+		return "d"
+	}
+	return g.LibPkg + ".DecisionFromContext(ctx)"
+}
+
+func New(config Config, getType func(typ ast.Expr, method MyMethod) string) *Generator {
+	return &Generator{Config: config, GetType: getType}
+}
+
+// MethodName 生成 MethodName() 的返回值, 函数没有结构体部分
+func (g *Generator) MethodName(method MyMethod) string {
+	if !method.IsStructMethod() {
+		if g.PkgPrefix != "" {
+			return fmt.Sprintf("%s_%s", g.PkgPrefix, method.MethodName)
+		}
+		return method.MethodName
+	}
+	if g.PkgPrefix != "" {
+		return fmt.Sprintf("%s_%s_%s", g.PkgPrefix, method.StructNameWithoutPtr(), method.MethodName)
+	}
+	return fmt.Sprintf("%s_%s", method.StructNameWithoutPtr(), method.MethodName)
+}
+
+// GenCaller 生成审批回调代码, hookRejected 为标记了 func::hookRejected 的方法
+func (g *Generator) GenCaller(methods []MyMethod, hookRejected []MyMethod) types.JenStatementSlice {
+	if !g.CallerStruct && !g.HookRejected {
+		// 旧版本: 通过 AllServices 调用, HookRejected 单独生成一个函数
+		return types.JenStatementSlice{
+			g.execute(servicesTemplate, GenMethodCallApprovalData{
+				Config:                 g.Config,
+				GenMethodName:          g.CallName(),
+				AddUnmarshalMethodArgs: true,
+				Methods:                methods,
+				GetType:                g.GetType,
+			}),
+			g.execute(servicesTemplate, GenMethodCallApprovalData{
+				Config:            g.Config,
+				GenMethodName:     g.CallName() + "HookRejected",
+				Methods:           hookRejected,
+				EveryMethodSuffix: "HookRejected",
+				DefaultSuccess:    true,
+				GetType:           g.GetType,
+			}),
+		}
+	}
+
+	hookRejectedMap := make(map[string]bool)
+	if g.HookRejected {
+		for _, method := range hookRejected {
+			hookRejectedMap[method.GenMethod()] = true
+		}
+	}
+	return types.JenStatementSlice{
+		g.execute(callerTemplate, GenMethodCallApprovalData{
+			Config:                 g.Config,
+			GenMethodName:          g.CallName(),
+			AddUnmarshalMethodArgs: true,
+			Methods:                methods,
+			GetType:                g.GetType,
+			HookRejectedMap:        hookRejectedMap,
+			LibPkg:                 g.LibPkg,
+			Migrations:             g.Migrations,
+			Decision:               g.Decision,
+			Previews:               g.Previews,
+		}),
+	}
+}
+
+func (g *Generator) execute(text string, data GenMethodCallApprovalData) *jen.Statement {
+	tmpl, err := template.New("methodCallApproval").Funcs(g.funcMap()).Parse(text + unmarshalTemplate)
+	if err != nil {
+		panic(fmt.Sprintf("解析模板失败: %v", err))
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		panic(fmt.Sprintf("执行模板失败: %v", err))
+	}
+
+	// 将生成的代码转换为 jen.Statement
+	return jen.Id(buf.String())
+}
+
+// 旧版本的模板: 通过 AllServices 直接调用方法
+const servicesTemplate = `
+func {{.GenMethodName}}(a *AllServices, ctx context.Context, method string, content string) BaseResponse[any] {
+	switch method {
+{{- range .Methods}}
+	case "{{.GenMethod}}":
+		var p {{.OutStructName}}
+		if err := {{codec "Unmarshal"}}([]byte(content), &p); err != nil {
+			return Fail[any](CodeUnmarshalFailed)
+		}
+{{- if captured .}}
+		ctx = p.RestoreCtx(ctx)
+{{- end}}
+		return {{if .IsStructMethod}}a.{{.StructNameWithoutPtr}}.{{end}}{{.MethodName}}{{$.EveryMethodSuffix}}({{formatParams . $.GetType}}).ToAny()
+{{- end}}
+	default:
+{{- if .DefaultSuccess}}
+		return Success[any](struct{}{})
+{{- else}}
+		return Fail[any](CodeUnknownMethod)
+{{- end}}
+	}
+}
+
+{{- if .AddUnmarshalMethodArgs}}
+
+{{template "unmarshal" .}}
+{{- end}}
+`
+
+// 通过 targets 查找实现了 XxxHookApproved/XxxHookRejected 的对象进行调用
+const callerTemplate = `
+{{- if .CallerStruct}}
+type ApprovalCaller struct {
+	targets []any
+	formatter IApprovalFormatter
+}
+
+func newApprovalCaller(formatter IApprovalFormatter, targets ...any) *ApprovalCaller {
+    return &ApprovalCaller{targets: targets, formatter: formatter}
+}
+
+{{- if .Decision}}
+
+// {{.GenMethodName}} 只传递是否通过, Decision 的其他字段从 ctx 中获取
+func (amc *ApprovalCaller) {{.GenMethodName}}(ctx context.Context, arg any, approved bool) (any, error) {
+	d := {{.LibPkg}}.DecisionFromContext(ctx)
+	d.Approved = approved
+	return amc.{{.GenMethodName}}Decision(ctx, arg, d)
+}
+
+func (amc *ApprovalCaller) {{.GenMethodName}}Decision(ctx context.Context, arg any, d {{.LibPkg}}.Decision) (any, error) {
+	approved := d.Approved
+{{- else}}
+
+func (amc *ApprovalCaller) {{.GenMethodName}}(ctx context.Context, arg any, approved bool) (any, error) {
+{{- end}}
+	switch p := arg.(type) {
+{{- else}}
+func {{.GenMethodName}}(targets []any, ctx context.Context, method string, content string, approved bool) (any, error) {
+	param, err := UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return nil, err
+	}
+	switch p := param.(type) {
+{{- end}}
+{{- range .Methods}}
+	case *{{.OutStructName}}:
+{{- if captured .}}
+		ctx = p.RestoreCtx(ctx)
+{{- end}}
+		type ApprovedInterface interface {
+			{{getApprovedMethodName .}}({{formatMethodSignatureWithReturn . $.GetType}})
+		}{{if index $.HookRejectedMap .GenMethod}}
+		type RejectedInterface interface {
+			{{getRejectedMethodName .}}({{formatMethodSignatureWithReturn . $.GetType}})
+		}{{end}}
+		for _, t := range {{if $.CallerStruct}}amc.targets{{else}}targets{{end}} {
+			if approved {
+				if target, ok := t.(ApprovedInterface); ok {
+					{{formatCallLogic . (getApprovedMethodName .) $.GetType}}
+				}
+			}{{if index $.HookRejectedMap .GenMethod}} else {
+				if target, ok := t.(RejectedInterface); ok {
+					{{formatCallLogic . (getRejectedMethodName .) $.GetType}}
+				}
+			}{{end}}
+		}
+{{- if $.CallerStruct}}
+		if !approved {
+			return nil, nil
+		}
+{{- end}}
+{{- end}}
+	}
+{{- if .CallerStruct}}
+	return nil, errors.Join({{.LibPkg}}.ErrUnknownMethod, fmt.Errorf("unknown arg type %T", arg))
+{{- else}}
+	return nil, errors.New("CodeUnknownMethod")
+{{- end}}
+}
+
+{{- if .AddUnmarshalMethodArgs}}
+
+{{template "unmarshal" .}}
+{{- end}}
+{{- if .CallerStruct}}
+
+// Methods 支持的 MethodName(), approvegen.Registry 注册时据此建立索引
+func (amc *ApprovalCaller) Methods() []string {
+	return []string{
+{{- range .Methods}}
+		"{{methodName .}}",
+{{- end}}
+	}
+}
+
+func (amc *ApprovalCaller) Policy(arg any) ({{.LibPkg}}.Policy, bool) {
+	if p, ok := arg.(interface{ Policy() {{.LibPkg}}.Policy }); ok {
+		return p.Policy(), true
+	}
+	return {{.LibPkg}}.Policy{}, false
+}
+
+func (amc *ApprovalCaller) Format(ctx context.Context, arg any) (any, error) {
+{{- $hasAnyFormatter := false -}}
+{{- range .Methods -}}
+{{- if hasFormatterMethod . -}}
+{{- $hasAnyFormatter = true -}}
+{{- end -}}
+{{- end -}}
+{{- if not $hasAnyFormatter}}
+	return "", nil
+{{- else}}
+	switch v := arg.(type) {
+{{- range .Methods}}
+{{- $method := . -}}
+{{- if hasFormatterMethod $method}}
+	case *{{.OutStructName}}:
+{{- if redacted .}}
+		v = v.Redacted()
+{{- end}}
+		return amc.formatter.{{getFormatterMethodName .}}({{formatFormatterCallParams . $.GetType}})
+{{- end}}
+{{- end}}
+	}
+	return nil, errors.Join({{.LibPkg}}.ErrUnsupportedArgType, fmt.Errorf("unsupported arg type %T", arg))
+{{- end}}
+}
+
+{{- if and .LibPkg .Previews}}
+
+// Preview 审批前调用目标上的 XxxPreview, 返回回调将产生的变更
+func (amc *ApprovalCaller) Preview(ctx context.Context, arg any) (*{{.LibPkg}}.Preview, error) {
+	switch p := arg.(type) {
+{{- range .Methods}}
+{{- $preview := index $.Previews .GenMethod}}
+{{- if $preview}}
+	case *{{.OutStructName}}:
+{{- if captured .}}
+		ctx = p.RestoreCtx(ctx)
+{{- end}}
+		type PreviewInterface interface {
+			{{$preview}}({{formatMethodParams . $.GetType}}) (*{{$.LibPkg}}.Preview, error)
+		}
+		for _, t := range amc.targets {
+			if target, ok := t.(PreviewInterface); ok {
+				return target.{{$preview}}({{previewParams . $.GetType}})
+			}
+		}
+		return nil, {{$.LibPkg}}.ErrNoPreview
+{{- end}}
+{{- end}}
+	}
+	return nil, errors.Join({{.LibPkg}}.ErrNoPreview, fmt.Errorf("unknown arg type %T", arg))
+}
+{{- end}}
+
+type IApprovalFormatter interface {
+{{- $structFormatterMethods := groupFormatterMethodsByStruct .Methods $.GetType -}}
+{{range $structName, $methods := $structFormatterMethods}}
+{{- range $methods}}
+	{{.MethodName}}({{.Signature}})
+{{- end}}
+{{- end}}
+}
+{{end}}
+`
+
+// UnmarshalMethodArgs, CallerStruct 时为 ApprovalCaller 的方法
+const unmarshalTemplate = `{{define "unmarshal" -}}
+func {{if .CallerStruct}}(amc *ApprovalCaller) {{end}}UnmarshalMethodArgs(method string, content string) (any, error) {
+	switch method {
+{{- range .Methods}}
+	case "{{if $.CallerStruct}}{{methodName .}}{{else}}{{.GenMethod}}{{end}}":
+{{- with index $.Migrations .GenMethod}}
+		switch {{$.LibPkg}}.SchemaOf(content) {
+{{- range .}}
+		case {{printf "%q" .From}}:
+			return {{.Func}}([]byte(content))
+{{- end}}
+		}
+{{- end}}
+		var p {{.OutStructName}}
+		if err := {{codec "Unmarshal"}}([]byte(content), &p); err != nil {
+			return nil, err
+		}
+		return &p, nil
+{{- end}}
+	default:
+		return nil, nil
+	}
+}
+{{- end}}`
+
+func (g *Generator) funcMap() template.FuncMap {
+	return template.FuncMap{
+		"nameWithoutPoint": utils.NameWithoutPoint,
+		"methodName":       g.MethodName,
+		"codec":            g.CodecFunc,
+		"redacted": func(method MyMethod) bool {
+			return g.Redacted[method.GenMethod()]
+		},
+		"captured": func(method MyMethod) bool {
+			return g.Captured[method.GenMethod()]
+		},
+		"formatParams": func(method MyMethod, getType func(typ ast.Expr, method MyMethod) string) string {
+			params := method.AsParams(func(typ ast.Expr) string {
+				return getType(typ, method)
+			})
+			var result []string
+			for _, param := range params {
+				if param.Type == "context.Context" {
+					result = append(result, "ctx")
+				} else if g.IsDecision(string(param.Type)) {
+					result = append(result, g.decisionArg())
+				} else {
+					result = append(result, callArg(param))
+				}
+			}
+			return strings.Join(result, ", ")
+		},
+		"getApprovedMethodName": func(method MyMethod) string {
+			return method.MethodName
+		},
+		"getRejectedMethodName": func(method MyMethod) string {
+			return rejectedMethodName(method.MethodName)
+		},
+		"formatMethodSignatureWithReturn": formatMethodSignatureWithReturn,
+		"formatMethodParams":              formatMethodParams,
+		"formatCallLogic":                 g.formatCallLogic,
+		"groupFormatterMethodsByStruct":   g.groupFormatterMethodsByStruct,
+		"hasFormatterMethod": func(method MyMethod) bool {
+			// 检查方法的注释中是否有formatter相关的配置, 语法错误的注释已由 approveGen 报告
+			bodies, _ := method.FindAnnoBody("Approve")
+			formatterName, _ := methods2.ParseFormatterMethod(bodies)
+			return formatterName != ""
+		},
+		"getFormatterMethodName": func(method MyMethod) string {
+			// 解析注释中指定的formatter方法名
+			bodies, _ := method.FindAnnoBody("Approve")
+			formatterName, _ := methods2.ParseFormatterMethod(bodies)
+			if formatterName == "" || formatterName == "DEFAULT" {
+				return "Format" + method.MethodName
+			}
+			return formatterName
+		},
+		"formatFormatterCallParams": func(method MyMethod, getType func(typ ast.Expr, method MyMethod) string) string {
+			params := method.AsParams(func(typ ast.Expr) string {
+				return getType(typ, method)
+			})
+			var paramNames []string
+			paramNames = append(paramNames, "ctx")
+
+			// 添加除context.Context和Decision之外的所有参数
+			for _, param := range params {
+				if !g.IgnoreParam(string(param.Type)) {
+					paramNames = append(paramNames, fmt.Sprintf("v.%s", param.Name.UpperCamelCase()))
+				}
+			}
+
+			// 添加raw参数（传入v本身）
+			paramNames = append(paramNames, "v")
+
+			return strings.Join(paramNames, ", ")
+		},
+		"previewParams": func(method MyMethod, getType func(typ ast.Expr, method MyMethod) string) string {
+			// 预览时还没有审批结果, Decision 从 ctx 中获取
+			params := method.AsParams(func(typ ast.Expr) string {
+				return getType(typ, method)
+			})
+			var result []string
+			for _, param := range params {
+				if param.Type == "context.Context" {
+					result = append(result, "ctx")
+				} else if g.IsDecision(string(param.Type)) {
+					result = append(result, g.LibPkg+".DecisionFromContext(ctx)")
+				} else {
+					result = append(result, callArg(param))
+				}
+			}
+			return strings.Join(result, ", ")
+		},
+	}
+}
+
+func (g *Generator) groupFormatterMethodsByStruct(methods []MyMethod, getType func(typ ast.Expr, method MyMethod) string) map[string][]FormatterMethod {
+	structMethods := make(map[string][]FormatterMethod)
+
+	for _, method := range methods {
+		bodies, _ := method.FindAnnoBody("Approve")
+
+		// 获取formatter方法名
+		formatterName, _ := methods2.ParseFormatterMethod(bodies)
+		if formatterName == "" {
+			continue
+		}
+		if formatterName == "DEFAULT" {
+			formatterName = "Format" + method.MethodName
+		}
+
+		// 生成方法签名
+		params := method.AsParams(func(typ ast.Expr) string {
+			return getType(typ, method)
+		})
+		var paramResult []string
+		paramResult = append(paramResult, "ctx context.Context")
+
+		// 添加除context.Context和Decision之外的所有参数
+		for _, param := range params {
+			if !g.IgnoreParam(string(param.Type)) {
+				paramResult = append(paramResult, fmt.Sprintf("%s %s", param.Name.LowerCamelCase(), param.Type.Storage()))
+			}
+		}
+
+		// 添加raw any参数
+		paramResult = append(paramResult, "raw any")
+		signature := strings.Join(paramResult, ", ") + ") (any, error"
+
+		structName := method.StructNameWithoutPtr()
+		formatterMethod := FormatterMethod{
+			MethodName: formatterName,
+			Signature:  signature,
+		}
+
+		// 检查是否已存在相同的方法名（不检查签名，因为Go不允许同名方法）
+		found := false
+		for _, existing := range structMethods[structName] {
+			if existing.MethodName == formatterMethod.MethodName {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			structMethods[structName] = append(structMethods[structName], formatterMethod)
+		}
+	}
+
+	return structMethods
+}
+
+func formatMethodSignatureWithReturn(method MyMethod, getType func(typ ast.Expr, method MyMethod) string) string {
+	// 处理返回值
+	var returnTypes []string
+	for _, result := range method.MethodResults {
+		returnTypes = append(returnTypes, getType(result.Type, method))
+	}
+
+	if len(returnTypes) == 0 {
+		return formatMethodParams(method, getType)
+	} else {
+		return formatMethodParams(method, getType) + ") (" + strings.Join(returnTypes, ", ")
+	}
+}
+
+// formatMethodParams 方法的参数列表, 不含返回值
+func formatMethodParams(method MyMethod, getType func(typ ast.Expr, method MyMethod) string) string {
+	params := method.AsParams(func(typ ast.Expr) string {
+		return getType(typ, method)
+	})
+	var paramResult []string
+	for _, param := range params {
+		if param.Type == "context.Context" {
+			paramResult = append(paramResult, "ctx context.Context")
+		} else {
+			paramResult = append(paramResult, fmt.Sprintf("%s %s", param.Name.LowerCamelCase(), param.Type))
+		}
+	}
+	return strings.Join(paramResult, ", ")
+}
+
+func (g *Generator) formatCallLogic(method MyMethod, methodName string, getType func(typ ast.Expr, method MyMethod) string) string {
+	params := method.AsParams(func(typ ast.Expr) string {
+		return getType(typ, method)
+	})
+	var paramNames []string
+	for _, param := range params {
+		if param.Type == "context.Context" {
+			paramNames = append(paramNames, "ctx")
+		} else if g.IsDecision(string(param.Type)) {
+			paramNames = append(paramNames, g.decisionArg())
+		} else {
+			paramNames = append(paramNames, callArg(param))
+		}
+	}
+
+	// 处理返回值
+	var returnTypes []string
+	for _, result := range method.MethodResults {
+		returnTypes = append(returnTypes, getType(result.Type, method))
+	}
+
+	callParams := strings.Join(paramNames, ", ")
+
+	if len(returnTypes) == 0 {
+		// 没有返回值
+		return fmt.Sprintf(`target.%s(%s)
+					return nil, nil`, methodName, callParams)
+	} else if len(returnTypes) == 1 {
+		if returnTypes[0] == "error" {
+			// 只有error返回值
+			return fmt.Sprintf(`err := target.%s(%s)
+					if err != nil {
+						return nil, err
+					}
+					return nil, nil`, methodName, callParams)
+		} else {
+			// 只有一个非error返回值
+			return fmt.Sprintf(`result := target.%s(%s)
+					return result, nil`, methodName, callParams)
+		}
+	} else {
+		// 多个返回值
+		lastType := returnTypes[len(returnTypes)-1]
+		if lastType == "error" {
+			// 最后一个是error
+			varNames := make([]string, len(returnTypes))
+			for i := 0; i < len(returnTypes)-1; i++ {
+				varNames[i] = fmt.Sprintf("v%d", i)
+			}
+			varNames[len(returnTypes)-1] = "err"
+
+			nonErrorVars := varNames[:len(varNames)-1]
+			if len(nonErrorVars) == 1 {
+				// 只有一个非error返回值，直接返回
+				return fmt.Sprintf(`%s := target.%s(%s)
+					if err != nil {
+						return nil, err
+					}
+					return %s, nil`, strings.Join(varNames, ", "), methodName, callParams, nonErrorVars[0])
+			} else {
+				// 多个非error返回值，使用[]any
+				return fmt.Sprintf(`%s := target.%s(%s)
+					if err != nil {
+						return nil, err
+					}
+					return []any{%s}, nil`, strings.Join(varNames, ", "), methodName, callParams, strings.Join(nonErrorVars, ", "))
+			}
+		} else {
+			// 没有error
+			varNames := make([]string, len(returnTypes))
+			for i := 0; i < len(returnTypes); i++ {
+				varNames[i] = fmt.Sprintf("v%d", i)
+			}
+			if len(varNames) == 1 {
+				// 只有一个返回值，直接返回
+				return fmt.Sprintf(`%s := target.%s(%s)
+					return %s, nil`, strings.Join(varNames, ", "), methodName, callParams, varNames[0])
+			} else {
+				// 多个返回值，使用[]any
+				return fmt.Sprintf(`%s := target.%s(%s)
+					return []any{%s}, nil`, strings.Join(varNames, ", "), methodName, callParams, strings.Join(varNames, ", "))
+			}
+		}
+	}
+}
+
+// callArg 调用回调时参数结构体字段的表达式, 可变参数展开切片
+func callArg(param types.Param) string {
+	if param.Type.IsVariadic() {
+		return fmt.Sprintf("p.%s...", param.Name.UpperCamelCase())
+	}
+	return fmt.Sprintf("p.%s", param.Name.UpperCamelCase())
+}
+
+// PreviewMethodName func::preview 默认的预览方法名称, 例如 RefundHookApproved ==> RefundPreview
+func PreviewMethodName(methodName string) string {
+	return strings.TrimSuffix(methodName, "HookApproved") + "Preview"
+}
+
+// rejectedMethodName 审批拒绝时调用的方法名称
+func rejectedMethodName(methodName string) string {
+	// 如果方法名以 "HookApproved" 结尾，将后缀替换为 "HookRejected"
+	if strings.HasSuffix(methodName, "HookApproved") {
+		return strings.TrimSuffix(methodName, "HookApproved") + "HookRejected"
+	}
+	return methodName + "HookRejected"
+}
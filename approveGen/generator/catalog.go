@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	utils2 "github.com/donutnomad/gotoolkit/internal/utils"
+	"github.com/donutnomad/gotoolkit/lib/approvegen"
+)
+
+// GenCatalog 生成 Catalog(), 返回所有审批方法的描述
+func (g *Generator) GenCatalog(entries approvegen.Catalog) jen.Code {
+	data := catalogData{Lib: g.LibPkg}
+	for _, entry := range entries {
+		data.Entries = append(data.Entries, catalogEntry{CatalogEntry: entry, SchemaLit: stringLit(string(entry.Schema))})
+	}
+	return jen.Id(utils2.MustExecuteTemplate(data, catalogTemplate))
+}
+
+type catalogData struct {
+	Lib     string
+	Entries []catalogEntry
+}
+
+type catalogEntry struct {
+	approvegen.CatalogEntry
+	SchemaLit string
+}
+
+// stringLit 尽量使用原始字符串, 便于阅读生成的 JSON
+func stringLit(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+const catalogTemplate = `
+// Catalog 所有审批方法的参数描述及 JSON Schema, 供管理后台渲染表单
+func Catalog() {{.Lib}}.Catalog {
+	return {{.Lib}}.Catalog{
+{{- range .Entries}}
+		{
+			Method: {{printf "%q" .Method}},
+{{- if .Note}}
+			Note:   {{printf "%q" .Note}},
+{{- end}}
+{{- with .Notes}}
+			Notes: map[string]string{
+{{- range $locale, $note := .}}
+				{{printf "%q" $locale}}: {{printf "%q" $note}},
+{{- end}}
+			},
+{{- end}}
+			Struct: {{printf "%q" .Struct}},
+			Fields: []{{$.Lib}}.CatalogField{
+{{- range .Fields}}
+				{Name: {{printf "%q" .Name}},{{if .Alias}} Alias: {{printf "%q" .Alias}},{{end}} Type: {{printf "%q" .Type}}{{if .Redact}}, Redact: {{printf "%q" .Redact}}{{end}}},
+{{- end}}
+			},
+			Schema: json.RawMessage({{.SchemaLit}}),
+		},
+{{- end}}
+	}
+}
+`
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Codec 参数结构体的序列化方式, 用于 UnmarshalMethodArgs; 空字符串等同于 sonic
+type Codec string
+
+const (
+	CodecSonic   Codec = "sonic"
+	CodecJSON    Codec = "json"    // encoding/json, 用于不能使用 sonic 的平台
+	CodecMsgpack Codec = "msgpack" // 二进制格式, 通过 lib/approvegen.Msgpack 编解码
+)
+
+var codecs = []Codec{CodecSonic, CodecJSON, CodecMsgpack}
+
+// ParseCodec 解析 -codec 参数, 空字符串为 sonic
+func ParseCodec(name string) (Codec, error) {
+	if name == "" {
+		return CodecSonic, nil
+	}
+	for _, c := range codecs {
+		if string(c) == name {
+			return c, nil
+		}
+	}
+	names := make([]string, len(codecs))
+	for i, c := range codecs {
+		names[i] = string(c)
+	}
+	return "", fmt.Errorf("unknown codec %q, supported: %s", name, strings.Join(names, ", "))
+}
+
+// Import 生成代码需要导入的包, 为空时使用 lib/approvegen
+func (c Codec) Import() string {
+	switch c {
+	case CodecJSON:
+		return "encoding/json"
+	case CodecMsgpack:
+		return ""
+	default:
+		return "github.com/bytedance/sonic"
+	}
+}
+
+// IsJSON 编码结果是否为 JSON, -schema 依赖 JSON 中的 __schema 字段
+func (c Codec) IsJSON() bool {
+	return c != CodecMsgpack
+}
+
+// Func 生成代码中 Marshal/Unmarshal 函数的表达式, libPkg 为 lib/approvegen 的包名
+func (c Codec) Func(name, libPkg string) string {
+	switch c {
+	case CodecJSON:
+		return "json." + name
+	case CodecMsgpack:
+		return libPkg + ".Msgpack." + name
+	default:
+		return "sonic." + name
+	}
+}
+
+// DisplayFunc 生成代码中 Json() 使用的 Marshal 表达式: 输出始终为 JSON 以便展示, 二进制格式改用 lib/approvegen.JSON
+func (c Codec) DisplayFunc(libPkg string) string {
+	if !c.IsJSON() {
+		return libPkg + ".JSON.Marshal"
+	}
+	return c.Func("Marshal", libPkg)
+}
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/dave/jennifer/jen"
+	utils2 "github.com/donutnomad/gotoolkit/internal/utils"
+)
+
+// FuncAdapterName 函数适配类型的名称, 例如 Transfer ==> TransferApprovalFunc
+func FuncAdapterName(funcName string) string {
+	return funcName + "ApprovalFunc"
+}
+
+// GenFuncAdapters 为 @Approve 函数生成适配类型: 函数值转换为适配类型后实现与函数同名的方法,
+// 可以和结构体一样作为 ApprovalCaller 的 target; hookRejected 中的函数同时为 XxxHookRejected 生成适配类型,
+// 有 func::preview 的函数同时为 XxxPreview 生成适配类型
+func (g *Generator) GenFuncAdapters(funcs []MyMethod, hookRejected map[string]bool) (jen.Code, error) {
+	var adapters []funcAdapter
+	for _, method := range funcs {
+		names := []string{method.MethodName}
+		if hookRejected[method.GenMethod()] {
+			names = append(names, rejectedMethodName(method.MethodName))
+		}
+		for _, name := range names {
+			target := method.Copy()
+			target.MethodName = name
+			m, err := g.proxyMethod(target, false)
+			if err != nil {
+				return nil, &MethodError{Method: method, Err: err}
+			}
+			adapters = append(adapters, funcAdapter{Type: FuncAdapterName(name), Method: m})
+		}
+		if name, ok := g.Previews[method.GenMethod()]; ok && g.LibPkg != "" {
+			target := method.Copy()
+			target.MethodName = name
+			m, err := g.proxyMethod(target, false)
+			if err != nil {
+				return nil, &MethodError{Method: method, Err: err}
+			}
+			// 预览函数与回调的参数相同, 返回值固定
+			m.Results = fmt.Sprintf(" (*%s.Preview, error)", g.LibPkg)
+			m.HasResult = true
+			adapters = append(adapters, funcAdapter{Type: FuncAdapterName(name), Method: m})
+		}
+	}
+	return jen.Id(utils2.MustExecuteTemplate(adapters, funcAdaptersTemplate)), nil
+}
+
+type funcAdapter struct {
+	Type   string
+	Method proxyMethod
+}
+
+const funcAdaptersTemplate = `
+{{- range .}}
+// {{.Type}} 将函数 {{.Method.Name}} 作为审批回调的 target
+type {{.Type}} func({{.Method.Params}}){{.Method.Results}}
+
+func ({{.Method.Receiver}} {{.Type}}) {{.Method.Name}}({{.Method.Params}}){{.Method.Results}} {
+	{{if .Method.HasResult}}return {{end}}{{.Method.Receiver}}({{.Method.Args}})
+}
+{{end}}
+// approvalFuncs 所有 @Approve 函数的 target, 例如 newApprovalCaller(formatter, approvalFuncs()...)
+func approvalFuncs() []any {
+	return []any{
+{{- range .}}
+		{{.Type}}({{.Method.Name}}),
+{{- end}}
+	}
+}
+`
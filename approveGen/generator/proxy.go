@@ -0,0 +1,229 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	utils2 "github.com/donutnomad/gotoolkit/internal/utils"
+	"github.com/samber/lo"
+)
+
+// ProxyName 代理类型的名称, 例如 UserService ==> UserServiceApprovalProxy
+func ProxyName(structName string) string {
+	return structName + "ApprovalProxy"
+}
+
+// GenProxy 为 structName 生成代理类型: annotated 中的方法提交审批请求, plain 中的方法直接调用 inner
+func (g *Generator) GenProxy(structName string, annotated, plain []MyMethod) (jen.Code, error) {
+	if lo.SomeBy(annotated, func(item MyMethod) bool { return item.IsGeneric() }) {
+		return nil, fmt.Errorf("cannot generate approval proxy for generic type %s", structName)
+	}
+	data := proxyData{
+		Name:   ProxyName(structName),
+		Struct: structName,
+		Lib:    g.LibPkg,
+	}
+	if ast.IsExported(structName) {
+		data.Ctor = "New" + data.Name
+	} else {
+		data.Ctor = "new" + utils2.UpperCamelCase(data.Name)
+	}
+
+	all := make([]proxyMethod, 0, len(annotated)+len(plain))
+	for _, method := range annotated {
+		m, err := g.proxyMethod(method, true)
+		if err != nil {
+			return nil, &MethodError{Method: method, Err: err}
+		}
+		all = append(all, m)
+	}
+	for _, method := range plain {
+		m, err := g.proxyMethod(method, false)
+		if err != nil {
+			return nil, &MethodError{Method: method, Err: err}
+		}
+		all = append(all, m)
+	}
+	data.Methods = lo.UniqBy(all, func(item proxyMethod) string {
+		return item.Name
+	})
+	return jen.Id(utils2.MustExecuteTemplate(data, proxyTemplate)), nil
+}
+
+// MethodError 生成某个方法时的错误
+type MethodError struct {
+	Method MyMethod
+	Err    error
+}
+
+func (e *MethodError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MethodError) Unwrap() error {
+	return e.Err
+}
+
+type proxyData struct {
+	Name    string
+	Ctor    string
+	Struct  string
+	Lib     string
+	Methods []proxyMethod
+}
+
+type proxyMethod struct {
+	Approve   bool
+	Name      string
+	Receiver  string
+	Params    string
+	Results   string
+	Ctx       string   // 提交审批使用的 context
+	ArgStruct string   // _XxxMethodYyy
+	Fields    []string // Name: name
+	Args      string   // 直接调用时的参数
+	ID, Err   string   // 局部变量名, 避免与参数重名
+	Zero      []string // 除 error 外返回值的零值
+	HasResult bool
+}
+
+func (g *Generator) proxyMethod(method MyMethod, approve bool) (proxyMethod, error) {
+	getType := func(typ ast.Expr) string {
+		if e, ok := typ.(*ast.Ellipsis); ok {
+			return "..." + g.GetType(e.Elt, method)
+		}
+		return g.GetType(typ, method)
+	}
+
+	used := make(map[string]bool)
+	for _, field := range method.MethodParams {
+		for _, name := range field.Names {
+			used[name.Name] = true
+		}
+	}
+	m := proxyMethod{
+		Approve:   approve,
+		Name:      method.MethodName,
+		ArgStruct: method.OutStructName(),
+		Ctx:       "context.Background()",
+	}
+	m.Receiver = uniqueName("x", used)
+
+	var params, args []string
+	idx := 0
+	for _, field := range method.MethodParams {
+		typ := getType(field.Type)
+		names := lo.Map(field.Names, func(item *ast.Ident, _ int) string { return item.Name })
+		if len(names) == 0 {
+			names = []string{"_"}
+		}
+		for _, name := range names {
+			if name == "_" {
+				// 没有名称的参数需要命名后才能转发
+				name = uniqueName(fmt.Sprintf("arg%d", idx), used)
+			} else if approve && !g.IgnoreParam(typ) {
+				m.Fields = append(m.Fields, fmt.Sprintf("%s: %s", utils2.UpperCamelCase(name), name))
+			}
+			if typ == "context.Context" && m.Ctx == "context.Background()" {
+				m.Ctx = name
+			}
+			params = append(params, name+" "+typ)
+			if strings.HasPrefix(typ, "...") {
+				args = append(args, name+"...")
+			} else {
+				args = append(args, name)
+			}
+			idx++
+		}
+	}
+	m.Params = strings.Join(params, ", ")
+	m.Args = strings.Join(args, ", ")
+
+	var results []string
+	for _, field := range method.MethodResults {
+		for range max(len(field.Names), 1) {
+			results = append(results, getType(field.Type))
+		}
+	}
+	m.HasResult = len(results) > 0
+	switch len(results) {
+	case 0:
+	case 1:
+		m.Results = " " + results[0]
+	default:
+		m.Results = " (" + strings.Join(results, ", ") + ")"
+	}
+
+	if approve {
+		if len(results) == 0 || results[len(results)-1] != "error" {
+			return m, fmt.Errorf("cannot generate approval proxy for %s.%s: the last result must be error", method.StructNameWithoutPtr(), method.MethodName)
+		}
+		m.Zero = lo.Map(results[:len(results)-1], func(item string, _ int) string {
+			return zeroValue(item)
+		})
+		m.ID = uniqueName("id", used)
+		m.Err = uniqueName("err", used)
+	}
+	return m, nil
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	ret := name
+	for i := 1; used[ret]; i++ {
+		ret = fmt.Sprintf("%s%d", name, i)
+	}
+	used[ret] = true
+	return ret
+}
+
+func zeroValue(typ string) string {
+	switch typ {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "byte", "rune":
+		return "0"
+	case "error", "any":
+		return "nil"
+	}
+	for _, prefix := range []string{"*", "[]", "map[", "chan ", "<-chan ", "func("} {
+		if strings.HasPrefix(typ, prefix) {
+			return "nil"
+		}
+	}
+	return "*new(" + typ + ")"
+}
+
+const proxyTemplate = `
+// {{.Name}} 将 @Approve 方法转换为审批请求, 其他方法直接调用 inner.
+// 提交成功时方法返回 {{.Lib}}.PendingError, 使用 {{.Lib}}.PendingID 获取待审批请求的 ID
+type {{.Name}} struct {
+	inner     *{{.Struct}}
+	submitter {{.Lib}}.Submitter
+}
+
+func {{.Ctor}}(inner *{{.Struct}}, submitter {{.Lib}}.Submitter) *{{.Name}} {
+	return &{{.Name}}{inner: inner, submitter: submitter}
+}
+{{range .Methods}}
+func ({{.Receiver}} *{{$.Name}}) {{.Name}}({{.Params}}){{.Results}} {
+{{- if .Approve}}
+	{{.ID}}, {{.Err}} := {{.Receiver}}.submitter.Submit({{.Ctx}}, &{{.ArgStruct}}{
+{{- range .Fields}}
+		{{.}},
+{{- end}}
+	})
+	if {{.Err}} != nil {
+		return {{range .Zero}}{{.}}, {{end}}{{.Err}}
+	}
+	return {{range .Zero}}{{.}}, {{end}}{{$.Lib}}.NewPendingError({{.ID}})
+{{- else}}
+	{{if .HasResult}}return {{end}}{{.Receiver}}.inner.{{.Name}}({{.Args}})
+{{- end}}
+}
+{{end}}`
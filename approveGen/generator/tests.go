@@ -0,0 +1,367 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	utils2 "github.com/donutnomad/gotoolkit/internal/utils"
+	"github.com/samber/lo"
+)
+
+// TestsSupported 是否可以生成参数结构体的测试: 需要 UnmarshalMethodArgs 和通过 targets 分发的回调
+func (c Config) TestsSupported() bool {
+	return c.CallerStruct || c.HookRejected
+}
+
+// RecorderName 测试中记录调用的 target 类型, 函数共用一个 recorder
+func RecorderName(method MyMethod) string {
+	if !method.IsStructMethod() {
+		return "_approvalFuncsRecorder"
+	}
+	return "_" + method.StructNameWithoutPtr() + "ApprovalRecorder"
+}
+
+// GenTests 为每个参数结构体生成测试: 填充示例值后序列化, 经 UnmarshalMethodArgs 反序列化后应与原值相同;
+// 再通过回调调用 recorder, 检查调用了正确的 XxxHookApproved/XxxHookRejected 且参数相同
+func (g *Generator) GenTests(methods []MyMethod, hookRejected map[string]bool) jen.Code {
+	data := testsData{
+		Config:  g.Config,
+		Marshal: g.CodecFunc("Marshal"),
+	}
+	recorders := make(map[string]*testRecorder)
+	for _, method := range methods {
+		name := RecorderName(method)
+		rec, ok := recorders[name]
+		if !ok {
+			rec = &testRecorder{Name: name}
+			recorders[name] = rec
+		}
+		c := testCase{
+			Struct:   method.OutStructName(),
+			Recorder: name,
+			Method:   "want.MethodName()",
+			Calls:    []testCall{{Approved: true, Name: method.MethodName}},
+		}
+		if !g.CallerStruct {
+			c.Method = strconv.Quote(method.GenMethod())
+		}
+		if hookRejected[method.GenMethod()] {
+			c.Calls = append(c.Calls, testCall{Approved: false, Name: rejectedMethodName(method.MethodName)})
+		}
+
+		getType := func(typ ast.Expr) string { return g.GetType(typ, method) }
+		seq := 0
+		var record []string
+		for _, field := range method.MethodParams {
+			typ := getType(field.Type)
+			for _, name := range field.Names {
+				if name.Name == "_" {
+					continue
+				}
+				if g.IgnoreParam(typ) {
+					continue
+				}
+				fieldName := utils2.UpperCamelCase(name.Name)
+				if value := g.sampleValue(field.Type, fieldName, getType, &seq); value != "" {
+					c.Fields = append(c.Fields, fieldName+": "+value)
+				}
+				c.Args = append(c.Args, "want."+fieldName)
+			}
+		}
+		used := map[string]bool{"ctx": true}
+		for _, param := range method.AsParams(getType) {
+			if param.Type == "context.Context" {
+				// 签名中统一命名为 ctx
+				continue
+			}
+			name := param.Name.LowerCamelCase().String()
+			used[name] = true
+			if !g.IgnoreParam(string(param.Type)) {
+				record = append(record, name)
+			}
+		}
+		receiver := uniqueName("r", used)
+		zero := lo.Map(method.MethodResults, func(item *ast.Field, _ int) string {
+			return zeroValue(getType(item.Type))
+		})
+		signature := formatMethodSignatureWithReturn(method, g.GetType)
+		for _, call := range c.Calls {
+			rec.Methods = append(rec.Methods, testRecorderMethod{
+				Name:      call.Name,
+				Receiver:  receiver,
+				Signature: signature,
+				Record:    record,
+				Zero:      strings.Join(zero, ", "),
+			})
+		}
+		data.Cases = append(data.Cases, c)
+	}
+	for _, rec := range recorders {
+		data.Recorders = append(data.Recorders, *rec)
+	}
+	sort.Slice(data.Recorders, func(i, j int) bool {
+		return data.Recorders[i].Name < data.Recorders[j].Name
+	})
+	return jen.Id(utils2.MustExecuteTemplate(data, testsTemplate))
+}
+
+type testsData struct {
+	Config
+	Marshal   string
+	Recorders []testRecorder
+	Cases     []testCase
+}
+
+type testRecorder struct {
+	Name    string
+	Methods []testRecorderMethod
+}
+
+type testRecorderMethod struct {
+	Name      string
+	Receiver  string
+	Signature string   // 与 Call 中 ApprovedInterface 的方法签名相同
+	Record    []string // 记录的参数, 不包含 context.Context 和 Decision
+	Zero      string   // 返回值的零值
+}
+
+type testCase struct {
+	Struct   string
+	Method   string   // UnmarshalMethodArgs 的 method 参数
+	Recorder string   // recorder 类型
+	Fields   []string // Name: value, 没有示例值的字段使用零值
+	Args     []string // 期望 recorder 记录的参数
+	Calls    []testCall
+}
+
+// testCall 回调的 approved 参数及期望调用的方法, 有 func::hookRejected 时同时检查拒绝
+type testCall struct {
+	Approved bool
+	Name     string
+}
+
+// sampleValue 类型的示例值; 生成时无法确定的类型(结构体, 接口等)在测试运行时通过 approvalTestSample 填充,
+// 返回空字符串表示使用零值
+func (g *Generator) sampleValue(expr ast.Expr, path string, getType func(ast.Expr) string, seq *int) string {
+	*seq++
+	switch t := expr.(type) {
+	case *ast.ParenExpr:
+		return g.sampleValue(t.X, path, getType, seq)
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return strconv.Quote(fmt.Sprintf("sample%d", *seq))
+		case "bool":
+			return "true"
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+			return strconv.Itoa(*seq)
+		case "float32", "float64":
+			return fmt.Sprintf("%d.5", *seq)
+		case "any", "error":
+			return ""
+		}
+	case *ast.StarExpr:
+		if value := g.sampleValue(t.X, path, getType, seq); value != "" {
+			return fmt.Sprintf("approvalTestPtr[%s](%s)", getType(t.X), value)
+		}
+		return ""
+	case *ast.Ellipsis:
+		if value := g.sampleValue(t.Elt, path+"[0]", getType, seq); value != "" {
+			return fmt.Sprintf("[]%s{%s}", getType(t.Elt), value)
+		}
+		return ""
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && (ident.Name == "byte" || ident.Name == "uint8") {
+			return fmt.Sprintf("[]byte(%q)", fmt.Sprintf("sample%d", *seq))
+		}
+		if value := g.sampleValue(t.Elt, path+"[0]", getType, seq); value != "" && t.Len == nil {
+			return fmt.Sprintf("%s{%s}", getType(t), value)
+		}
+	case *ast.MapType:
+		key := g.sampleValue(t.Key, path+"{key}", getType, seq)
+		value := g.sampleValue(t.Value, path+"{value}", getType, seq)
+		if key != "" && value != "" {
+			return fmt.Sprintf("%s{%s: %s}", getType(t), key, value)
+		}
+		return ""
+	case *ast.SelectorExpr:
+		switch getType(t) {
+		case "time.Time":
+			return fmt.Sprintf("time.Date(2024, 1, %d, 3, 4, 5, 0, time.UTC)", *seq%28+1)
+		case "time.Duration":
+			return fmt.Sprintf("%d * time.Second", *seq)
+		}
+	case *ast.InterfaceType:
+		return ""
+	}
+	return fmt.Sprintf("approvalTestSample[%s](t, %q)", getType(expr), path)
+}
+
+const testsTemplate = `
+type approvalTestCall struct {
+	Method string
+	Args   []any
+}
+
+type approvalTestRecorder struct {
+	calls []approvalTestCall
+}
+
+func (r *approvalTestRecorder) record(method string, args ...any) {
+	r.calls = append(r.calls, approvalTestCall{Method: method, Args: args})
+}
+
+// expect 最近一次调用的方法应为 method, 参数与 args 相同
+func (r *approvalTestRecorder) expect(t *testing.T, method string, args ...any) {
+	t.Helper()
+	if len(r.calls) == 0 {
+		t.Fatalf("%s was not called", method)
+	}
+	last := r.calls[len(r.calls)-1]
+	r.calls = nil
+	if last.Method != method {
+		t.Fatalf("called %s, want %s", last.Method, method)
+	}
+	approvalTestEqual(t, args, last.Args)
+}
+
+// approvalTestEqual 比较反序列化前后的值; 自定义序列化的类型(例如 decimal.Decimal)比较再次序列化的结果
+func approvalTestEqual(t *testing.T, want, got any) {
+	t.Helper()
+	if reflect.DeepEqual(want, got) {
+		return
+	}
+	a, errA := {{.Marshal}}(want)
+	b, errB := {{.Marshal}}(got)
+	if errA != nil || errB != nil || !bytes.Equal(a, b) {
+		t.Fatalf("value changed after round trip:\nwant: %#v\ngot:  %#v", want, got)
+	}
+}
+
+func approvalTestPtr[T any](v T) *T {
+	return &v
+}
+
+// approvalTestSample 生成时无法确定结构的类型, 运行时通过反射填充示例值
+func approvalTestSample[T any](t *testing.T, path string) T {
+	t.Helper()
+	var v T
+	approvalTestFill(t, reflect.ValueOf(&v).Elem(), path, 0)
+	return v
+}
+
+// approvalTestFill 填充导出字段; 接口和未导出字段无法经过序列化还原, 报告为错误.
+// 自定义序列化的类型(例如 time.Time, decimal.Decimal)保持零值
+func approvalTestFill(t *testing.T, v reflect.Value, path string, depth int) {
+	t.Helper()
+	if v.CanAddr() {
+		switch v.Addr().Interface().(type) {
+		case json.Marshaler, encoding.TextMarshaler:
+			return
+		}
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			t.Errorf("%s is an interface (%s), UnmarshalMethodArgs can not restore its concrete type", path, v.Type())
+		}
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		t.Errorf("%s (%s) can not be serialized", path, v.Type())
+	case reflect.Pointer:
+		if depth < 3 {
+			v.Set(reflect.New(v.Type().Elem()))
+			approvalTestFill(t, v.Elem(), path, depth+1)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				t.Errorf("%s.%s is unexported and is lost after the round trip", path, field.Name)
+				continue
+			}
+			approvalTestFill(t, v.Field(i), path+"."+field.Name, depth)
+		}
+	case reflect.Slice:
+		if depth < 3 {
+			v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+			approvalTestFill(t, v.Index(0), path+"[0]", depth+1)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			approvalTestFill(t, v.Index(i), fmt.Sprintf("%s[%d]", path, i), depth)
+		}
+	case reflect.Map:
+		if depth < 3 {
+			key := reflect.New(v.Type().Key()).Elem()
+			elem := reflect.New(v.Type().Elem()).Elem()
+			approvalTestFill(t, key, path+"{key}", depth+1)
+			approvalTestFill(t, elem, path+"{value}", depth+1)
+			v.Set(reflect.MakeMap(v.Type()))
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.String:
+		v.SetString("sample")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	}
+}
+{{range .Recorders}}
+{{- $rec := .}}
+type {{.Name}} struct {
+	approvalTestRecorder
+}
+{{range .Methods}}
+func ({{.Receiver}} *{{$rec.Name}}) {{.Name}}({{.Signature}}) {
+	{{.Receiver}}.record({{printf "%q" .Name}}{{range .Record}}, {{.}}{{end}})
+{{- if .Zero}}
+	return {{.Zero}}
+{{- end}}
+}
+{{end}}
+{{- end}}
+{{- range .Cases}}
+{{- $case := .}}
+func Test{{.Struct}}(t *testing.T) {
+	want := &{{.Struct}}{
+{{- range .Fields}}
+		{{.}},
+{{- end}}
+	}
+	content, err := {{$.Marshal}}(want)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	rec := new({{.Recorder}})
+{{- if $.CallerStruct}}
+	caller := newApprovalCaller(nil, rec)
+	got, err := caller.UnmarshalMethodArgs({{.Method}}, string(content))
+{{- else}}
+	got, err := UnmarshalMethodArgs({{.Method}}, string(content))
+{{- end}}
+	if err != nil {
+		t.Fatalf("UnmarshalMethodArgs: %v", err)
+	}
+	approvalTestEqual(t, want, got)
+{{- range .Calls}}
+{{- if $.CallerStruct}}
+	if _, err := caller.{{$.CallName}}(context.Background(), got, {{.Approved}}); err != nil {
+{{- else}}
+	if _, err := {{$.CallName}}([]any{rec}, context.Background(), {{$case.Method}}, string(content), {{.Approved}}); err != nil {
+{{- end}}
+		t.Fatalf("{{$.CallName}}(approved={{.Approved}}): %v", err)
+	}
+	rec.expect(t, {{printf "%q" .Name}}{{range $case.Args}}, {{.}}{{end}})
+{{- end}}
+}
+{{end}}`
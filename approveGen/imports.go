@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// pruneImports 删除生成代码中没有使用的 import
+// 模板按需求预先添加 context/fmt/strings 等包, 实际是否用到取决于方法的注释, 测试文件则复制了全部 import;
+// names 为导入路径到包名的映射, 不在其中的第三方包无法确定包名, 保持不变
+func pruneImports(src []byte, names map[string]string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	type importSpec struct{ alias, path string }
+	var unused []importSpec
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		var alias, name string
+		switch {
+		case spec.Name != nil:
+			alias, name = spec.Name.Name, spec.Name.Name
+		case names[importPath] != "":
+			name = names[importPath]
+		case isStdImport(importPath):
+			name = path.Base(importPath)
+		}
+		if name == "" || name == "_" || name == "." || used[name] {
+			continue
+		}
+		unused = append(unused, importSpec{alias, importPath})
+	}
+	if len(unused) == 0 {
+		return src, nil
+	}
+	for _, spec := range unused {
+		astutil.DeleteNamedImport(fset, file, spec.alias, spec.path)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isStdImport 标准库的路径第一段不包含 "."
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	xast2 "github.com/donutnomad/gotoolkit/internal/xast"
+	"golang.org/x/tools/go/packages"
+)
+
+// loadMode 依赖也从源码做类型检查(NeedDeps), 不依赖编译器导出数据的格式, 与 go 工具链的版本无关
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedImports |
+	packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo
+
+// loadPackages 使用 go/packages 加载 patterns 对应的包, 例如 ./... 或 ./service;
+// 生成的代码通常还不存在, 包中的类型错误不影响生成, 只有无法解析的包和语法错误才返回错误
+func loadPackages(dir string, patterns ...string) ([]*packages.Package, error) {
+	cfg := &packages.Config{Mode: loadMode, Dir: dir}
+	pkgs, err := packages.Load(cfg, normalizePatterns(dir, patterns)...)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	var ret []*packages.Package
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			if e.Kind != packages.TypeError {
+				errs = append(errs, e)
+			}
+		}
+		if len(pkg.Syntax) > 0 {
+			ret = append(ret, pkg)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no Go files matched %s", strings.Join(patterns, ", "))
+	}
+	return ret, nil
+}
+
+// normalizePatterns 兼容旧的 -path 参数: 不以 . 或 / 开头的目录(例如 service, service/...)视为相对路径而不是导入路径
+func normalizePatterns(dir string, patterns []string) []string {
+	ret := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if !filepath.IsAbs(p) && !strings.HasPrefix(p, ".") {
+			if info, err := os.Stat(filepath.Join(dir, strings.TrimSuffix(p, "/..."))); err == nil && info.IsDir() {
+				p = "./" + p
+			}
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+// packageNames 包直接导入的包的包名, key: 导入路径
+func packageNames(pkg *packages.Package) map[string]string {
+	ret := make(map[string]string, len(pkg.Imports))
+	for path, imported := range pkg.Imports {
+		ret[path] = imported.Name
+	}
+	return ret
+}
+
+// typeString 使用 go/types 渲染类型表达式, 包名由 qualifier 决定; 可变参数 ...T 保留 "..." 前缀,
+// 没有类型信息时返回空字符串
+func typeString(info *types.Info, expr ast.Expr, qualifier types.Qualifier) string {
+	if info == nil {
+		return ""
+	}
+	if e, ok := expr.(*ast.Ellipsis); ok {
+		if elt := typeString(info, e.Elt, qualifier); elt != "" {
+			return "..." + elt
+		}
+		return ""
+	}
+	t := info.TypeOf(expr)
+	if t == nil || t == types.Typ[types.Invalid] {
+		return ""
+	}
+	return types.TypeString(t, qualifier)
+}
+
+// importsOf 文件的 import, 没有别名时使用 go/types 解析出的包名(例如 gopkg.in/yaml.v3 ==> yaml),
+// 没有类型信息时退回到路径的最后一段
+func importsOf(file *ast.File, info *types.Info) xast2.ImportInfoSlice {
+	imports := new(xast2.ImportInfoSlice).From(file.Imports)
+	if info == nil {
+		return imports
+	}
+	for i, spec := range file.Imports {
+		if spec.Name != nil {
+			continue
+		}
+		if pkgName := info.PkgNameOf(spec); pkgName != nil {
+			imports[i].Alias = pkgName.Imported().Name()
+		}
+	}
+	return imports
+}
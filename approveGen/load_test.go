@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePatterns(t *testing.T) {
+	got := normalizePatterns(".", []string{" testdata/policy", "./testdata/svc", "testdata/imports/...", "", "fmt"})
+	assert.Equal(t, []string{"./testdata/policy", "./testdata/svc", "./testdata/imports/...", "fmt"}, got)
+}
+
+func TestLoadPackages(t *testing.T) {
+	pkgs, err := loadPackages("", "testdata/imports", "./testdata/policy")
+	require.NoError(t, err)
+	require.Len(t, pkgs, 2)
+	assert.Equal(t, "github.com/donutnomad/gotoolkit/approveGen/testdata/imports", pkgs[0].PkgPath)
+	assert.Equal(t, "github.com/donutnomad/gotoolkit/approveGen/testdata/policy", pkgs[1].PkgPath)
+
+	// 没有别名的 import 使用 go/types 解析出的包名, 有别名时保留别名
+	imports := importsOf(pkgs[0].Syntax[0], pkgs[0].TypesInfo)
+	money := imports.Find("money")
+	require.NotNil(t, money)
+	assert.Equal(t, "github.com/donutnomad/gotoolkit/approveGen/testdata/imports/moneyv2", money.GetPath())
+	assert.Nil(t, imports.Find("moneyv2"))
+	assert.NotNil(t, imports.Find("approval"))
+
+	_, err = loadPackages("", "./testdata/missing")
+	assert.Error(t, err)
+}
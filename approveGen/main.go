@@ -2,11 +2,14 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"go/ast"
-	"go/parser"
+	"go/scanner"
 	"go/token"
+	gotypes "go/types"
 	"iter"
 	"os"
 	"path/filepath"
@@ -17,17 +20,24 @@ import (
 
 	"github.com/Xuanwo/gg"
 	"github.com/dave/jennifer/jen"
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/donutnomad/gotoolkit/approveGen/catalog"
 	"github.com/donutnomad/gotoolkit/approveGen/generator"
 	"github.com/donutnomad/gotoolkit/approveGen/methods"
+	"github.com/donutnomad/gotoolkit/approveGen/schema"
 	"github.com/donutnomad/gotoolkit/approveGen/types"
-	"github.com/donutnomad/gotoolkit/approveGen/utils"
 	utils2 "github.com/donutnomad/gotoolkit/internal/utils"
 	xast2 "github.com/donutnomad/gotoolkit/internal/xast"
+	"github.com/donutnomad/gotoolkit/lib/approvegen"
 	"github.com/samber/lo"
+	"golang.org/x/tools/go/packages"
 )
 
 const AnnotationName = "Approve"
 
+// LibPkgPath 生成代码依赖的运行时库
+const LibPkgPath = "github.com/donutnomad/gotoolkit/lib/approvegen"
+
 // GlobalTemplateInfo 全局模板信息
 type GlobalTemplateInfo struct {
 	FuncName string
@@ -39,41 +49,60 @@ type GlobalTemplateInfo struct {
 // 支持格式:
 // 1. global::template="ApproveFor=模板内容"
 // 2. global::template="ApproveFor::args=[参数列表]"
-func parseGlobalTemplate(comment string) GlobalTemplateInfo {
+func parseGlobalTemplate(comment string) (GlobalTemplateInfo, error) {
 	info := GlobalTemplateInfo{}
 
-	// 移除 global::template= 前缀
-	content := strings.TrimPrefix(comment, "global::template=")
-	content = strings.Trim(content, "\"")
-
-	// 检查是否是args定义
-	if strings.Contains(content, "::args=") {
-		// args定义格式: "ApproveFor::args=[...]"
-		parts := strings.Split(content, "::args=")
-		if len(parts) >= 2 {
-			info.FuncName = parts[0]
+	body, err := annotation.Parse(comment)
+	if err != nil {
+		return info, err
+	}
+	head := body.Head()
+	if err := body.CheckKeys(); err != nil {
+		return info, err
+	}
+	if head.Value == nil || head.Value.Kind == annotation.List {
+		return info, annotation.Errorf(head.Offset, "global::template requires a string value")
+	}
+	content := head.Text()
 
-			// 解析args数组
-			argsStr := "args=" + parts[1]
-			// 移除转义字符
-			argsStr = strings.ReplaceAll(argsStr, "\\\"", "\"")
-			info.Args = methods.ParseArgsArray(argsStr)
+	// 模板定义格式: "ApproveFor=模板内容"
+	// args定义格式: "ApproveFor::args=[...]"
+	name, rest, ok := strings.Cut(content, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return info, annotation.Errorf(head.Value.Offset, "global::template expects \"<Func>=<template>\" or \"<Func>::args=[...]\"")
+	}
+	if funcName, isArgs := strings.CutSuffix(strings.TrimSpace(name), "::args"); isArgs {
+		info.FuncName = funcName
+		info.Args, err = methods.ParseArgsArray(content)
+		if err != nil {
+			var ae *annotation.Error
+			if errors.As(err, &ae) {
+				// 偏移量转换为相对整个注释内容(跳过开头的引号)
+				return info, annotation.Errorf(head.Value.Offset+1+ae.Offset, "%s", ae.Msg)
+			}
+			return info, err
 		}
 	} else {
-		// 模板定义格式: "ApproveFor=模板内容"
-		parts := strings.SplitN(content, "=", 2)
-		if len(parts) >= 2 {
-			info.FuncName = parts[0]
-			info.Template = parts[1]
-		}
+		info.FuncName = name
+		info.Template = rest
 	}
 
-	return info
+	return info, nil
 }
 
-func genGlobalFunc(comment string, method *MyMethod, formatFunctionName func(name string) string, getNameFunc func(typ ast.Expr, imports xast2.ImportInfoSlice) string) jen.Code {
+func genGlobalFunc(comment string, method *MyMethod, formatFunctionName func(name string) string, getNameFunc func(typ ast.Expr, imports xast2.ImportInfoSlice) string) (jen.Code, error) {
 	// Extract function name from comment
-	funcName := strings.Trim(strings.Split(comment, "=")[1], "\"")
+	body, err := annotation.Parse(comment)
+	if err != nil {
+		return nil, err
+	}
+	if err := body.CheckKeys(); err != nil {
+		return nil, err
+	}
+	funcName := body.Head().Text()
+	if funcName == "" {
+		return nil, annotation.Errorf(body.Head().Offset, "global::func requires a non-empty value")
+	}
 
 	g := gg.NewGroup()
 	func1 := g.NewFunction(formatFunctionName(funcName))
@@ -97,72 +126,206 @@ func genGlobalFunc(comment string, method *MyMethod, formatFunctionName func(nam
 	func1.AddBody(
 		gg.Return(
 			gg.Call(method.MethodName).AddParameter(lo.Map(method.MethodParams, func(param *ast.Field, _ int) any {
-				return gg.String(param.Names[0].Name)
+				return gg.String("%s", param.Names[0].Name)
 			})...),
 		),
 	)
 
-	return jen.Id(g.String())
+	return jen.Id(g.String()), nil
 }
 
 var (
-	paths           = flag.String("path", "", "dir paths, separated by comma")
-	outputFileName_ = flag.String("out", "", "output filename")
+	paths           = flag.String("path", "", "package patterns or dirs, separated by comma, e.g. ./... or ./service,./repo")
+	outputFileName_ = flag.String("out", "", "output filename; with several packages it is the file name written into each package directory")
 	version2        = flag.Bool("v2", false, "version2")
 	version3        = flag.Bool("v3", false, "version3")
 	version4        = flag.Bool("v4", false, "version4")
 	pkgName         = flag.String("pkgname", "", "package name prefix for MethodName()")
 	genMethods      = flag.Bool("methods", true, "generate CallMethodForApproval and CallMethodForApprovalHookRejected methods")
+	catalogFile     = flag.String("catalog", "", "write the catalog of approval methods (fields and JSON Schema) to this file and generate Catalog()")
+	codecName       = flag.String("codec", "sonic", "serialization of argument structs: sonic, json (encoding/json) or msgpack")
+	genProxy        = flag.Bool("proxy", false, "generate XxxApprovalProxy types that submit @Approve methods as approval requests")
+	schemaFile      = flag.String("schema", "", "schema lock file; records argument struct versions and rejects incompatible changes without args::migrate")
+	genTests        = flag.Bool("tests", false, "also write <out>_test.go, checking that every argument struct survives UnmarshalMethodArgs and dispatches to the right callback")
+	strict          = flag.Bool("strict", false, "generate code that compiles as is: drop unused imports and return zero values when a func:name template only returns an error")
 )
 
+// diags 收集生成过程中的所有注释错误, 在写文件前统一报告
+var diags = &annotation.Diagnostics{}
+
+var annotationCache = make(map[string][]annotation.Source)
+
+// annotationsOf 返回方法上语法正确的 @Approve 注释, 有错误的注释会被记录并跳过
+func annotationsOf(method MyMethod) []annotation.Source {
+	key := method.PkgPath + "." + method.GenMethod()
+	if sources, ok := annotationCache[key]; ok {
+		return sources
+	}
+	// 语法错误的注释记录到 diags, 其余注释照常检查
+	sources, err := method.FindAnnotations(AnnotationName)
+	if err != nil {
+		diags.ReportList(err)
+	}
+	var ret []annotation.Source
+	for _, src := range sources {
+		if err := methods.CheckAnnotation(src.Body); err != nil {
+			diags.Report(src, err)
+			continue
+		}
+		ret = append(ret, src)
+	}
+	annotationCache[key] = ret
+	return ret
+}
+
+// isGlobalFunc 函数的注释是否都是 global::func/global::template, 不能与其他注释混用
+func isGlobalFunc(method MyMethod) bool {
+	sources := annotationsOf(method)
+	global := lo.CountBy(sources, func(item annotation.Source) bool {
+		return strings.HasPrefix(item.Body, "global::")
+	})
+	if global > 0 && global < len(sources) {
+		diags.Report(sources[0], fmt.Errorf("global::func and global::template can not be combined with other annotations on %s", method.MethodName))
+	}
+	return global > 0
+}
+
+func bodiesOf(method MyMethod) []string {
+	return lo.Map(annotationsOf(method), func(item annotation.Source, _ int) string {
+		return item.Body
+	})
+}
+
 func main() {
 	flag.Parse()
-	if *paths == "" || *outputFileName_ == "" {
+	if (*paths == "" && flag.NArg() == 0) || *outputFileName_ == "" {
 		fmt.Println("type parameter is required")
 		return
 	}
 
-	if *version4 {
-		*version3 = true
-	}
+	var outputFileName = *outputFileName_
 
-	var pathList = strings.Split(*paths, ",")
-	// Trim spaces from each path
-	for i, path := range pathList {
-		pathList[i] = strings.TrimSpace(path)
+	pkgs, err := loadPackages("", append(strings.Split(*paths, ","), flag.Args()...)...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	var outputFileName = *outputFileName_
 
-	var files []string
-	for _, pwd := range pathList {
-		_files := getFiles(pwd)
-		files = append(files, _files...)
+	cfg := generator.ConfigFromFlags(*version2, *version3, *version4, *pkgName)
+	cfg.Proxy = *genProxy
+	cfg.Strict = *strict
+	codec, err := generator.ParseCodec(*codecName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	cfg.Codec = codec
+	var lock *schema.Lock
+	if *schemaFile != "" {
+		if lock, err = schema.Load(*schemaFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	var catalog *approvegen.Catalog
+	if *catalogFile != "" {
+		catalog = new(approvegen.Catalog)
+	}
+	var written int
+	for _, pkg := range pkgs {
+		// 一个包时保存到 -out 指定的路径, 多个包时保存到每个包的目录
+		out := outputFileName
+		if len(pkgs) > 1 {
+			out = filepath.Join(pkg.Dir, filepath.Base(outputFileName))
+		}
+		// 每个包的 Catalog() 只包含自己的方法, -catalog 文件包含所有包的方法
+		var pkgCatalog *approvegen.Catalog
+		if catalog != nil {
+			pkgCatalog = new(approvegen.Catalog)
+		}
+		var tests *bytes.Buffer
+		if *genTests {
+			tests = new(bytes.Buffer)
+		}
+		src, err := generate(pkg, cfg, *genMethods, lock, pkgCatalog, tests)
+		if err != nil {
+			scanner.PrintError(os.Stderr, err)
+			os.Exit(1)
+		}
+		if src == nil {
+			fmt.Println("[approveGen] 未找到方法, skip", pkg.PkgPath)
+			continue
+		}
+		if err := utils2.WriteFormat(out, src); err != nil {
+			panic(err)
+		}
+		if tests != nil {
+			testFileName := strings.TrimSuffix(out, ".go") + "_test.go"
+			if err := utils2.WriteFormat(testFileName, tests.Bytes()); err != nil {
+				panic(err)
+			}
+		}
+		if catalog != nil {
+			*catalog = append(*catalog, *pkgCatalog...)
+		}
+		abs, _ := filepath.Abs(out)
+		fmt.Println("[approveGen] Success:", abs)
+		written++
 	}
-	if len(files) == 0 {
+	if written == 0 {
 		return
 	}
-	pkgPath := lo.Must1(utils.GetFullPathWithPackage(files[0]))
+	if lock != nil {
+		if err := lock.Save(*schemaFile); err != nil {
+			panic(err)
+		}
+	}
+	if catalog != nil {
+		bs, err := json.MarshalIndent(catalog, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(*catalogFile, append(bs, '\n'), 0o644); err != nil {
+			panic(err)
+		}
+	}
+}
 
-	var fSet = token.NewFileSet()
-	var importMgr = xast2.NewImportManager(pkgPath)
+// generate 生成 pkg 中所有 @Approve 方法的代码, 没有方法时返回 nil;
+// lock 不为 nil 时记录参数结构体的版本, catalog 不为 nil 时收集方法描述并生成 Catalog(),
+// tests 不为 nil 时写入参数结构体的测试文件
+func generate(pkg *packages.Package, cfg generator.Config, genMethods bool, lock *schema.Lock, catalog *approvegen.Catalog, tests *bytes.Buffer) ([]byte, error) {
+	diags = &annotation.Diagnostics{}
+	annotationCache = make(map[string][]annotation.Source)
+	if lock != nil && !cfg.Codec.IsJSON() {
+		// 版本记录在 JSON 的 __schema 字段中
+		return nil, fmt.Errorf("-schema can not be used with -codec=%s", cfg.Codec)
+	}
+	if tests != nil && (!genMethods || !cfg.TestsSupported()) {
+		// 测试通过 UnmarshalMethodArgs 和 targets 调用回调, 旧版本通过 AllServices 调用
+		return nil, fmt.Errorf("-tests requires -methods and one of -v2, -v3, -v4")
+	}
+
+	var importMgr = xast2.NewImportManager(pkg.PkgPath)
 	var allMethods = types.MyMethodSlice{}
+	var plainMethods = types.MyMethodSlice{}
+	var pkgFuncs = types.MyMethodSlice{}
 
 	extractor := NewAnnotationExtractor("@" + AnnotationName)
-	var ch = make(chan types.MyMethodSlice)
-	for _, file := range files {
-		go func() {
-			ch <- extractor.ExtractMethods(fSet, file)
-		}()
-	}
-	for i := 0; i < len(files); i++ {
-		_methods := <-ch
-		allMethods = append(allMethods, _methods...)
-	}
-	notStructMethods := lo.Filter(allMethods, func(item MyMethod, index int) bool {
-		return item.Recv == nil
-	})
+	for _, file := range pkg.Syntax {
+		annotated, plain, funcs := extractor.ExtractMethods(pkg.Fset, file, pkg.PkgPath, pkg.TypesInfo)
+		allMethods = append(allMethods, annotated...)
+		plainMethods = append(plainMethods, plain...)
+		pkgFuncs = append(pkgFuncs, funcs...)
+	}
+	// 只有 global::func/global::template 注释的函数用于定义模板, 其他 @Approve 函数作为审批目标
+	var notStructMethods types.MyMethodSlice
 	allMethods = lo.Filter(allMethods, func(item MyMethod, index int) bool {
-		return item.Recv != nil
+		if item.Recv == nil && isGlobalFunc(item) {
+			notStructMethods = append(notStructMethods, item)
+			return false
+		}
+		return true
 	})
 	// sort methods
 	sort.Slice(allMethods, func(i, j int) bool {
@@ -170,22 +333,103 @@ func main() {
 	})
 
 	methodsMap := allMethods.ToMap()
+	// 生成文件中的 import 没有别名时使用包名, 源文件中的别名只在源文件中有效
+	pkgNames := packageNames(pkg)
+	// 类型名由 go/types 渲染, 别名、泛型实例和嵌套的类型都与源码的写法无关;
+	// 没有类型信息时(例如类型检查失败)退回到 AST
 	var getNameFunc = func(typ ast.Expr, imports xast2.ImportInfoSlice) string {
+		tn := typeString(pkg.TypesInfo, typ, func(p *gotypes.Package) string {
+			if p.Path() == pkg.PkgPath {
+				return ""
+			}
+			alias, _ := importMgr.GetAliasAndPath(p.Path())
+			return lo.CoalesceOrEmpty(alias, p.Name())
+		})
+		if tn != "" {
+			return tn
+		}
 		return xast2.GetFieldType(typ, func(expr *ast.SelectorExpr) string {
-			x := expr.X.(*ast.Ident).Name // mo
-			alias, _ := importMgr.GetAliasAndPath(imports.Find(x).GetPath())
+			info := findImport(expr, imports) // mo
+			if info == nil {
+				return ""
+			}
+			alias, path := importMgr.GetAliasAndPath(info.GetPath())
+			if alias == "" {
+				alias = pkgNames[path]
+			}
 			return alias
 		})
 	}
+	gen := generator.New(cfg, func(typ ast.Expr, method MyMethod) string {
+		return getNameFunc(typ, method.Imports)
+	})
+	gen.Migrations = make(map[string][]generator.Migration)
+	gen.Redacted = make(map[string]bool)
+	// 使用完整导入路径的类型, 计算参数结构体版本时不受 import 别名影响
+	var fullTypeFunc = func(typ ast.Expr, imports xast2.ImportInfoSlice) string {
+		tn := typeString(pkg.TypesInfo, typ, func(p *gotypes.Package) string {
+			if p.Path() == pkg.PkgPath {
+				return ""
+			}
+			return p.Path()
+		})
+		if tn != "" {
+			return tn
+		}
+		return xast2.GetFieldType(typ, func(expr *ast.SelectorExpr) string {
+			if info := findImport(expr, imports); info != nil {
+				return info.GetPath()
+			}
+			return ""
+		})
+	}
 	// 检查是否有formatter方法，如果有就提前添加import
 	var formatterMethods types.MyMethodSlice
 	for _, method := range utils2.IterSortMap(methodsMap) {
-		bodies := lo.Must1(method.FindAnnoBody(AnnotationName))
-		formatterName := methods.ParseFormatterMethod(bodies)
+		formatterName, _ := methods.ParseFormatterMethod(bodiesOf(method))
 		if formatterName != "" {
 			formatterMethods = append(formatterMethods, method)
 		}
 	}
+	// 检查是否有审批策略或有效期, 有则需要导入 lib/approvegen
+	hasPolicy := lo.SomeBy(allMethods, func(method MyMethod) bool {
+		return lo.SomeBy(bodiesOf(method), func(body string) bool {
+			info, _ := methods.ParsePolicyMethod(body)
+			expiry, _ := methods.ParseExpiryMethod(body)
+			return info != nil || expiry != nil
+		})
+	})
+	// 检查是否需要生成 Json(), 有则需要导入序列化库
+	hasJson := lo.SomeBy(allMethods, func(method MyMethod) bool {
+		return lo.SomeBy(bodiesOf(method), func(body string) bool {
+			info, _ := methods.ParseJsonMethod(body)
+			return info != nil && info.Generate
+		})
+	})
+	needCodec := genMethods || hasJson
+	// 检查是否有脱敏字段, 有则需要导入 lib/approvegen
+	hasRedact := lo.SomeBy(allMethods, func(method MyMethod) bool {
+		return lo.SomeBy(bodiesOf(method), func(body string) bool {
+			info, _ := methods.ParseFieldAnnotation(body)
+			return info != nil && info.Redact != ""
+		})
+	})
+	// 检查是否有方法声明了 Decision 参数, 有则 ApprovalCaller 生成 CallDecision
+	hasDecision := lo.SomeBy(allMethods, func(method MyMethod) bool {
+		return lo.SomeBy(method.MethodParams, func(item *ast.Field) bool {
+			return fullTypeFunc(item.Type, method.Imports) == LibPkgPath+".Decision"
+		})
+	})
+	gen.Decision = hasDecision
+	// func::preview 的方法, ApprovalCaller 生成 Preview, 需要导入 lib/approvegen
+	gen.Previews = make(map[string]string)
+	for _, method := range allMethods {
+		for _, body := range bodiesOf(method) {
+			if info, _ := methods.ParsePreviewMethod(body); info != nil {
+				gen.Previews[method.GenMethod()] = lo.CoalesceOrEmpty(info.Method, generator.PreviewMethodName(method.MethodName))
+			}
+		}
+	}
 
 	// 导入import
 	importMgr.AddImport("fmt")
@@ -200,15 +444,43 @@ func main() {
 			importMgr.AddImport(item)
 		}
 	}
-	if *genMethods {
-		importMgr.AddImport("github.com/bytedance/sonic")
-		if *version2 {
-			importMgr.AddImport("errors")
+	// ctx::capture 提取函数的返回值类型决定 Ctx 中字段的类型, key: GenMethod()
+	captures := resolveCaptures(methodsMap, pkgFuncs, importMgr, getNameFunc)
+	gen.Captured = lo.MapValues(captures, func(_ *methods.CtxCaptureMethod, _ string) bool { return true })
+	if genMethods {
+		for _, item := range cfg.Imports() {
+			importMgr.AddImport(item)
 		}
+	} else if path := cfg.Codec.Import(); hasJson && path != "" {
+		importMgr.AddImport(path)
 	}
-	// v3版本的额外imports
-	if *version3 && *genMethods {
-		importMgr.AddImport("errors")
+	// 代理类型需要转发没有注释的方法
+	var proxyPlainMethods types.MyMethodSlice
+	if cfg.Proxy {
+		importMgr.AddImport("context")
+		proxyPlainMethods = lo.Filter(plainMethods, func(item MyMethod, _ int) bool {
+			return allMethods.HasStruct(item.PkgPath, item.StructNameWithoutPtr())
+		})
+		sort.SliceStable(proxyPlainMethods, func(i, j int) bool {
+			return proxyPlainMethods[i].GenMethod() < proxyPlainMethods[j].GenMethod()
+		})
+		for _, method := range proxyPlainMethods {
+			for _, item := range method.ExtractImportPath() {
+				importMgr.AddImport(item)
+			}
+		}
+	}
+	if catalog != nil {
+		importMgr.AddImport("encoding/json")
+	}
+	// -v3/-v4 的 ApprovalCaller 返回 lib/approvegen 的错误, 调用方可以使用 errors.Is 判断
+	callerStruct := genMethods && cfg.CallerStruct
+	if callerStruct || hasPolicy || hasRedact || hasDecision || len(gen.Previews) > 0 || cfg.Proxy || lock != nil || catalog != nil || (needCodec && cfg.Codec == generator.CodecMsgpack) {
+		importMgr.AddImport(LibPkgPath)
+		gen.LibPkg, _ = importMgr.GetAliasAndPath(LibPkgPath)
+		if gen.LibPkg == "" {
+			gen.LibPkg = filepath.Base(LibPkgPath)
+		}
 	}
 	// 字段格式化方法
 	var formatFunctionBy = func(name string) string {
@@ -218,8 +490,7 @@ func main() {
 		return "_ApprovedFunc_" + name
 	}
 	if len(allMethods) == 0 {
-		fmt.Println("[approveGen] 未找到方法, skip")
-		return
+		return nil, diags.Err()
 	}
 	codes := jen.NewFile(allMethods[0].FilePkgName)
 
@@ -245,15 +516,25 @@ func main() {
 	var funcTemplateMapping = make(map[string]string)
 	var funcGlobalArgsMapping = make(map[string][]methods.FuncMethodArg) // 新增：全局args映射
 	for _, method := range utils2.IterSortMap(notStructMethods.ToMap()) {
-		comments := lo.Must1(method.FindAnnoBody(AnnotationName))
-		for _, comment := range comments {
+		sources := annotationsOf(method)
+		for _, src := range sources {
+			comment := src.Body
 			switch {
 			case strings.HasPrefix(comment, "global::func"):
-				fmt.Println("Global Function:", comments, method.PkgPath, method.MethodName)
-				codes.Add(genGlobalFunc(comment, &method, formatFunctionBy, getNameFunc))
+				fmt.Println("Global Function:", bodiesOf(method), method.PkgPath, method.MethodName)
+				code, err := genGlobalFunc(comment, &method, formatFunctionBy, getNameFunc)
+				if err != nil {
+					diags.Report(src, err)
+					continue
+				}
+				codes.Add(code)
 			case strings.HasPrefix(comment, "global::template"):
 				// 解析全局模板，支持分离的template和args定义
-				templateInfo := parseGlobalTemplate(comment)
+				templateInfo, err := parseGlobalTemplate(comment)
+				if err != nil {
+					diags.Report(src, err)
+					continue
+				}
 
 				// 如果是模板定义，存储到模板映射
 				if templateInfo.Template != "" {
@@ -270,16 +551,10 @@ func main() {
 
 	var hookRejectedMethods types.MyMethodSlice
 
-	var isIgnoreType = func(ty string) bool {
-		return ty == "context.Context"
-	}
+	var isIgnoreType = gen.IgnoreParam
 
 	// 处理每个加了@Approve注释的方法
 	for _, method1 := range utils2.IterSortMap(methodsMap) {
-		if !method1.IsStructMethod() {
-			panic("暂时不能给不是结构体的方法设置")
-		}
-
 		var getNameFunc2 = func(typ ast.Expr) string {
 			return getNameFunc(typ, method1.Imports)
 		}
@@ -296,8 +571,13 @@ func main() {
 		method := method1.Copy()
 		method.MethodParams = methodParams
 
+		if err := checkParamTypes(method1, pkg.TypesInfo, isIgnoreType, getNameFunc2); err != nil && len(method1.CommentPos) > 0 {
+			diags.Errorf(method1.CommentPos[0], "%s", err)
+		}
+
 		///////// 生成方法结构体
 		codes.Comment(fmt.Sprintf("========================== %s ==========================", method.OutStructName())).Line()
+		capture := captures[method.GenMethod()]
 		codes.Add(jen.Type().Id(method.OutStructName()).StructFunc(func(s *jen.Group) {
 			for _, param := range methodParams {
 				tn := getNameFunc2(param.Type)
@@ -306,31 +586,82 @@ func main() {
 					continue
 				}
 				for _, name := range param.Names {
-					s.Id(utils2.UpperCamelCase(name.Name)).Qual("", tn)
+					// 可变参数保存为切片
+					s.Id(utils2.UpperCamelCase(name.Name)).Qual("", string(types.Type(tn).Storage()))
 				}
 			}
+			if capture != nil {
+				s.Add(capture.StructField())
+			}
 		}))
 
 		// 获取所有该方法的注释
-		var bodies = lo.Must1(method.FindAnnoBody(AnnotationName))
+		var sources = annotationsOf(method1)
+		// 解析出所有的args::field的控制语句
+		var fields methods.FieldInfoSlice
+		var fieldSources []annotation.Source
+		for _, src := range sources {
+			field, err := methods.ParseFieldAnnotation(src.Body)
+			if err != nil {
+				diags.Report(src, err)
+			} else if field != nil {
+				fields = append(fields, *field)
+				fieldSources = append(fieldSources, src)
+			}
+		}
 
 		// 生成方法
 		var receiver, structName = "p", method.OutStructName()
 
+		// 脱敏字段, String()/Json()/Format 使用 Redacted() 的副本
+		var redactFields []methods.RedactField
+		for _, param := range methodParams {
+			tn := getNameFunc2(param.Type)
+			if isIgnoreType(tn) {
+				continue
+			}
+			for _, name := range param.Names {
+				mode := fields.GetRedact(utils2.EString(name.Name))
+				if mode == "" {
+					continue
+				}
+				if mode != "omit" && tn != "string" && tn != "*string" {
+					idx := slices.IndexFunc(fields, func(f methods.FieldInfo) bool { return f.Field == name.Name && f.Redact != "" })
+					diags.Report(fieldSources[idx], fmt.Errorf("redact=%q requires a string field, %s is %s", mode, name.Name, tn))
+					continue
+				}
+				redactFields = append(redactFields, methods.RedactField{Field: utils2.UpperCamelCase(name.Name), Mode: mode, Ptr: tn == "*string"})
+			}
+		}
+		redacted := len(redactFields) > 0
+		omitFields := lo.FilterMap(redactFields, func(f methods.RedactField, _ int) (string, bool) { return f.Field, f.Mode == "omit" })
+		if redacted {
+			gen.Redacted[method.GenMethod()] = true
+		}
+
 		var args = method.AsParams(func(typ ast.Expr) string {
 			return getNameFunc(typ, method.Imports)
 		})
 
 		var methodCodes []jen.Code
-		for _, body := range bodies {
+		// 多语言的 String()/Note(), key: 语言, 默认语言为空字符串
+		var stringLocales, noteLocales = make(map[string]annotation.Source), make(map[string]annotation.Source)
+		var stringVariants, noteVariants []methods.LocaleVariant
+		for _, src := range sources {
+			body := src.Body
 			// 生成方法 String()
-			if info := methods.ParseStringMethod(body); info != nil {
-				// 解析出所有的args::field的控制语句
-				fields := methods.ParseFieldMethod(bodies)
+			if info, err := methods.ParseStringMethod(body); err != nil {
+				diags.Report(src, err)
+			} else if info != nil && checkLocaleDup(stringLocales, "args::string", info.Locale, src) {
+				methodName := "String"
+				if info.Locale != "" {
+					methodName = "string" + methods.LocaleSuffix(info.Locale)
+					stringVariants = append(stringVariants, methods.LocaleVariant{Locale: info.Locale, Expr: receiver + "." + methodName + "()"})
+				}
 				argsFilter := lo.Filter(args, func(param types.Param, index int) bool {
 					return !isIgnoreType(string(param.Type))
 				})
-				out := info.Generator().WithMethod("String").Generate(receiver, structName, lo.Map(argsFilter, func(p types.Param, idx int) methods.ArgInfo {
+				out := info.Generator().WithMethod(methodName).WithRedacted(redacted).Generate(receiver, structName, lo.Map(argsFilter, func(p types.Param, idx int) methods.ArgInfo {
 					key := fields.GetName(p.Name).UpperCamelCase()
 					placeholder := p.Type.Placeholder()
 					fieldFormatFunc := fields.GetFunction(p.Name)
@@ -347,23 +678,63 @@ func main() {
 						Field:      p.Name.UpperCamelCase().String(),
 						FormatFunc: formatFunctionBy(fieldFormatFunc.String()),
 						IsPtr:      p.Type.IsPtr(),
+						Omit:       fields.GetRedact(p.Name) == "omit",
 					}
 				}))
 				methodCodes = append(methodCodes, out)
 			}
 			// 生成方法 Note()
-			if info := methods.ParseNoteMethod(body); info != nil {
+			if info, err := methods.ParseNoteMethod(body); err != nil {
+				diags.Report(src, err)
+			} else if info != nil && checkLocaleDup(noteLocales, "args::note", info.Locale, src) {
+				if info.Locale == "" {
+					methodCodes = append(methodCodes, info.Generator().Generate(receiver, structName))
+				} else {
+					noteVariants = append(noteVariants, methods.LocaleVariant{Locale: info.Locale, Expr: strconv.Quote(info.Note)})
+				}
+			}
+			// 生成方法 Policy()
+			if info, err := methods.ParsePolicyMethod(body); err != nil {
+				diags.Report(src, err)
+			} else if info != nil {
+				methodCodes = append(methodCodes, info.Generator().Generate(receiver, structName, gen.LibPkg))
+			}
+			// 生成方法 Expiry()
+			if info, err := methods.ParseExpiryMethod(body); err != nil {
+				diags.Report(src, err)
+			} else if info != nil {
+				methodCodes = append(methodCodes, info.Generator().Generate(receiver, structName, gen.LibPkg))
+			}
+			// 生成方法 Retryable()
+			if info, err := methods.ParseExecMethod(body); err != nil {
+				diags.Report(src, err)
+			} else if info != nil {
 				methodCodes = append(methodCodes, info.Generator().Generate(receiver, structName))
 			}
+			// func::preview 由 ApprovalCaller.Preview 分发到目标上的 XxxPreview
+			if info, err := methods.ParsePreviewMethod(body); err != nil {
+				diags.Report(src, err)
+			} else if info != nil && genMethods && !cfg.CallerStruct {
+				diags.Report(src, errors.New("func::preview requires -v3 or -v4"))
+			}
 			// 生成方法 Json()
-			if info := methods.ParseJsonMethod(body); info != nil {
-				methodCodes = append(methodCodes, info.Generator().Generate(receiver, structName))
+			if info, err := methods.ParseJsonMethod(body); err != nil {
+				diags.Report(src, err)
+			} else if info != nil {
+				methodCodes = append(methodCodes, info.Generator().WithMarshal(gen.DisplayMarshal()).WithRedacted(redacted).WithOmit(gen.LibPkg, omitFields).Generate(receiver, structName))
 			}
 			// 为对象结构体生成自定义方法
-			if info := methods.ParseFuncMethod(body); info != nil {
+			if info, err := methods.ParseFuncMethod(body); err != nil {
+				diags.Report(src, err)
+			} else if info != nil {
+				if !method.IsStructMethod() || method.Interface {
+					diags.Report(src, fmt.Errorf("func:%s can only be used on struct methods", info.Name))
+					continue
+				}
 				tmplStr, ok := funcTemplateMapping[info.Name]
 				if !ok {
-					panic(fmt.Sprintf("func: %s 's template is not define", info.Name))
+					diags.Report(src, fmt.Errorf("func: %s 's template is not define", info.Name))
+					continue
 				}
 				// 如果当前func没有定义args，使用全局args
 				if len(info.Args) == 0 {
@@ -395,8 +766,8 @@ func main() {
 						methodArgNames = append(methodArgNames, name.Name)
 					}
 				}
-				// 添加一个formatter方法, v4添加
-				if *version4 {
+				// 添加一个formatter参数
+				if cfg.FormatterArg {
 					if strings.HasSuffix(methodArgs, ")") {
 						methodArgs = methodArgs[:len(methodArgs)-1] + ", formatter IApprovalFormatter" + methodArgs[len(methodArgs)-1:]
 					}
@@ -404,7 +775,7 @@ func main() {
 					methodArgNames = append(methodArgNames, "formatter")
 				}
 
-				methodCodes = append(methodCodes, info.Generator().Generate(tmplStr, method.ObjName, method.StructName, method.MethodName, methodArgNames, methodArgs, methodStructArgCode.GoString(), returnString, *version2))
+				methodCodes = append(methodCodes, info.Generator().WithZeroValues(cfg.Strict).Generate(tmplStr, method.ObjName, method.StructName, method.MethodName, methodArgNames, methodArgs, methodStructArgCode.GoString(), returnString, cfg.FuncArgs))
 			}
 			// 生成hookRejected内容
 			if strings.HasPrefix(body, "func::hookRejected") {
@@ -412,89 +783,133 @@ func main() {
 			}
 		}
 
-		// 生成方法 MethodName()
-		var methodNameValue string
-		if *pkgName != "" {
-			methodNameValue = fmt.Sprintf("%s_%s_%s", *pkgName, method.StructNameWithoutPtr(), method.MethodName)
-		} else {
-			methodNameValue = fmt.Sprintf("%s_%s", method.StructNameWithoutPtr(), method.MethodName)
+		// 生成方法 StringFor(locale) 和 NoteFor(locale), 回退到默认语言
+		for _, item := range []struct {
+			base     string
+			locales  map[string]annotation.Source
+			variants []methods.LocaleVariant
+		}{
+			{"String", stringLocales, stringVariants},
+			{"Note", noteLocales, noteVariants},
+		} {
+			if len(item.variants) == 0 {
+				continue
+			}
+			if _, ok := item.locales[""]; !ok {
+				src := item.locales[item.variants[0].Locale]
+				diags.Report(src, fmt.Errorf("args::%s[%s] requires a default args::%s without locale", strings.ToLower(item.base), item.variants[0].Locale, strings.ToLower(item.base)))
+				continue
+			}
+			_l := methods.LocaleMethod{Base: item.base, Variants: item.variants}
+			methodCodes = append(methodCodes, _l.Generate(receiver, structName))
 		}
+
+		// 生成方法 MethodName()
 		_m := methods.NoteMethod{
-			Info: &methods.NoteMethodInfo{Note: methodNameValue},
+			Info: &methods.NoteMethodInfo{Note: gen.MethodName(method)},
 		}
 		methodCodes = append(methodCodes, _m.WithMethod("MethodName").Generate(receiver, structName))
 
+		// 生成方法 SchemaHash() 和 MarshalJSON()
+		if lock != nil {
+			hash := recordSchema(lock, pkg.PkgPath, gen, method, sources, func(typ ast.Expr) string {
+				return fullTypeFunc(typ, method.Imports)
+			})
+			_s := methods.SchemaMethod{Hash: hash}
+			methodCodes = append(methodCodes, _s.Generate(receiver, structName, gen.LibPkg))
+		}
+		if capture != nil {
+			methodCodes = append(methodCodes, capture.Generate(receiver, structName))
+		}
+		if redacted {
+			_r := methods.RedactMethod{Fields: redactFields}
+			methodCodes = append(methodCodes, _r.Generate(receiver, structName, gen.LibPkg))
+		}
+		if catalog != nil {
+			*catalog = append(*catalog, catalogEntry(gen, method, sources, fields, getNameFunc2, func(typ ast.Expr) string {
+				return fullTypeFunc(typ, method.Imports)
+			}))
+		}
+
 		codes.Add(methodCodes...)
 	}
 
 	codes.Line()
 
+	if len(gen.Migrations) > 0 && genMethods && !cfg.CallerStruct && !cfg.HookRejected {
+		diags.Errorf(token.Position{Filename: pkg.GoFiles[0]}, "args::migrate requires -v2 or -v3, CallMethodForApproval does not use UnmarshalMethodArgs")
+	}
+
 	// 根据命令行参数决定是否生成方法调用审批相关方法
-	if *genMethods {
-		if *version3 {
-			// 使用v3版本生成方法，生成ApprovalMethodCaller结构体
-			// 创建一个 map 来标记哪些方法支持 HookRejected
-			hookRejectedMap := make(map[string]bool)
-			for _, method := range hookRejectedMethods {
-				hookRejectedMap[method.GenMethod()] = true
-			}
-
-			// 将formatterMethods和allMethods合并，确保formatter方法也被包含
-			var combinedMethods types.MyMethodSlice
-			combinedMethods = append(combinedMethods, allMethods...)
-			for _, fMethod := range formatterMethods {
-				// 检查是否已经在allMethods中
-				found := false
-				for _, aMethod := range allMethods {
-					if fMethod.GenMethod() == aMethod.GenMethod() {
-						found = true
-						break
-					}
-				}
-				if !found {
-					combinedMethods = append(combinedMethods, fMethod)
-				}
-			}
+	if genMethods {
+		codes.Add(gen.GenCaller(allMethods, hookRejectedMethods).As()...)
+	}
 
-			m1 := generator.GenMethodCallApprovalV3("Call", true, *pkgName, "", combinedMethods, func(typ ast.Expr, method MyMethod) string {
-				return getNameFunc(typ, method.Imports)
-			}, false, hookRejectedMap)
-			codes.Add(m1.As()...)
-		} else if *version2 {
-			// 使用v2版本生成方法，需要传递 hookRejectedMethods 的信息
-			// 创建一个 map 来标记哪些方法支持 HookRejected
-			hookRejectedMap := make(map[string]bool)
-			for _, method := range hookRejectedMethods {
-				hookRejectedMap[method.GenMethod()] = true
+	// 函数通过适配类型注册为 target, 旧版本直接调用函数
+	funcMethods := lo.Filter(allMethods, func(item MyMethod, _ int) bool {
+		return !item.IsStructMethod()
+	})
+	if genMethods && cfg.HookRejected && len(funcMethods) > 0 {
+		hookRejectedMap := lo.SliceToMap(hookRejectedMethods, func(item MyMethod) (string, bool) {
+			return item.GenMethod(), true
+		})
+		code, err := gen.GenFuncAdapters(funcMethods, hookRejectedMap)
+		if err != nil {
+			var me *generator.MethodError
+			if errors.As(err, &me) && len(me.Method.CommentPos) > 0 {
+				diags.Errorf(me.Method.CommentPos[0], "%s", err)
+			} else {
+				return nil, err
 			}
-
-			m1 := generator.GenMethodCallApprovalV2("CallMethodForApproval", true, "", allMethods, func(typ ast.Expr, method MyMethod) string {
-				return getNameFunc(typ, method.Imports)
-			}, false, hookRejectedMap)
-			codes.Add(m1.As()...)
 		} else {
-			// 使用v1版本生成方法
-			m1 := generator.GenMethodCallApproval("CallMethodForApproval", true, "", allMethods, func(typ ast.Expr, method MyMethod) string {
-				return getNameFunc(typ, method.Imports)
-			}, false)
-			codes.Add(m1.As()...)
-			fmt.Println("-===============")
-			m2 := generator.GenMethodCallApproval("CallMethodForApprovalHookRejected", false, "HookRejected", hookRejectedMethods, func(typ ast.Expr, method MyMethod) string {
-				return getNameFunc(typ, method.Imports)
-			}, true)
-			codes.Add(m2.As()...)
+			codes.Line()
+			codes.Comment("========================== Approval Functions ==========================").Line()
+			codes.Add(code)
 		}
 	}
 
-	// 生成Formatter方法 (v3版本跳过，因为已经在ApprovalMethodCaller中生成)
-	if len(formatterMethods) > 0 && !*version3 {
+	if catalog != nil {
+		codes.Line()
+		codes.Add(gen.GenCatalog(*catalog))
+	}
+
+	// 生成代理类型
+	if cfg.Proxy {
+		// 函数和接口方法没有可以包装的结构体
+		structMethods := lo.Filter(allMethods, func(item MyMethod, _ int) bool {
+			return item.IsStructMethod() && !item.Interface
+		})
+		groups := lo.GroupBy(structMethods, func(item MyMethod) string {
+			return item.StructNameWithoutPtr()
+		})
+		for structName, annotated := range utils2.IterSortMap(groups) {
+			plain := lo.Filter(proxyPlainMethods, func(item MyMethod, _ int) bool {
+				return item.StructNameWithoutPtr() == structName
+			})
+			code, err := gen.GenProxy(structName, annotated, plain)
+			if err != nil {
+				pos := annotated[0].CommentPos[0]
+				var me *generator.MethodError
+				if errors.As(err, &me) && len(me.Method.CommentPos) > 0 {
+					pos = me.Method.CommentPos[0]
+				}
+				diags.Errorf(pos, "%s", err)
+				continue
+			}
+			codes.Line()
+			codes.Comment(fmt.Sprintf("========================== %s ==========================", generator.ProxyName(structName))).Line()
+			codes.Add(code)
+		}
+	}
+
+	// 生成Formatter方法 (CallerStruct 跳过，因为已经在 ApprovalCaller 中生成)
+	if len(formatterMethods) > 0 && !cfg.CallerStruct {
 		codes.Line()
 		codes.Comment("========================== Formatter Method ==========================").Line()
 
 		formatterGen := methods.NewFormatterMethod()
 		for _, method := range formatterMethods {
-			bodies := lo.Must1(method.FindAnnoBody(AnnotationName))
-			formatterName := methods.ParseFormatterMethod(bodies)
+			formatterName, _ := methods.ParseFormatterMethod(bodiesOf(method))
 
 			// 如果没有指定formatter名称或者是DEFAULT，使用方法名
 			if formatterName == "" || formatterName == "DEFAULT" {
@@ -522,28 +937,257 @@ func main() {
 				for _, name := range param.Names {
 					fields = append(fields, methods.FormatterFieldInfo{
 						Name: utils2.UpperCamelCase(name.Name),
-						Type: tn,
+						Type: string(types.Type(tn).Storage()),
 					})
 				}
 			}
 
-			formatterGen.AddMethod(method.OutStructName(), method.MethodName, formatterName, fields)
+			formatterGen.AddMethod(method.OutStructName(), method.MethodName, formatterName, fields, gen.Redacted[method.GenMethod()])
 		}
 
 		codes.Add(formatterGen.Generate())
 	}
 
-	// 保存到当前工作目录
+	// 所有错误在写文件前统一报告
+	if err := diags.Err(); err != nil {
+		return nil, err
+	}
+
 	buf := &bytes.Buffer{}
 	if err := codes.Render(buf); err != nil {
-		panic(err)
+		return nil, err
 	}
-	err := utils2.WriteFormat(outputFileName, buf.Bytes())
-	if err != nil {
-		panic(err)
+	// 导入路径到包名, -strict 时用于删除没有使用的 import
+	importNames := map[string]string{LibPkgPath: filepath.Base(LibPkgPath)}
+	if path := cfg.Codec.Import(); path != "" {
+		importNames[path] = filepath.Base(path)
+	}
+	for path, dep := range pkg.Imports {
+		importNames[path] = dep.Name
+	}
+	if tests != nil {
+		testCodes := jen.NewFile(allMethods[0].FilePkgName)
+		testCodes.PackageComment("Code generated by approveGen. DO NOT EDIT.")
+		testCodes.Line()
+		testCodes.Id("import").DefsFunc(func(group *jen.Group) {
+			imported := make(map[string]bool)
+			for _, info := range importMgr.Iter() {
+				imported[info.GetPath()] = true
+			}
+			for _, path := range []string{"bytes", "context", "encoding", "encoding/json", "fmt", "reflect", "testing", "time"} {
+				if !imported[path] {
+					group.Lit(path)
+				}
+			}
+			for _, info := range importMgr.Iter() {
+				if info.HasAlias() {
+					group.Id(info.GetAlias()).Lit(info.GetPath())
+				} else {
+					group.Lit(info.GetPath())
+				}
+			}
+		})
+		hookRejectedMap := lo.SliceToMap(hookRejectedMethods, func(item MyMethod) (string, bool) {
+			return item.GenMethod(), true
+		})
+		testCodes.Add(gen.GenTests(allMethods, hookRejectedMap))
+		if !cfg.Strict {
+			if err := testCodes.Render(tests); err != nil {
+				return nil, err
+			}
+		} else {
+			testBuf := &bytes.Buffer{}
+			if err := testCodes.Render(testBuf); err != nil {
+				return nil, err
+			}
+			src, err := pruneImports(testBuf.Bytes(), importNames)
+			if err != nil {
+				return nil, err
+			}
+			tests.Write(src)
+		}
+	}
+	if !cfg.Strict {
+		return buf.Bytes(), nil
+	}
+	return pruneImports(buf.Bytes(), importNames)
+}
+
+// catalogEntry 方法的描述: 字段, 别名, Go 类型及 JSON Schema
+func catalogEntry(gen *generator.Generator, method MyMethod, sources []annotation.Source, fields methods.FieldInfoSlice, typeOf, fullType func(ast.Expr) string) approvegen.CatalogEntry {
+	entry := approvegen.CatalogEntry{
+		Method: gen.MethodName(method),
+		Struct: method.OutStructName(),
+		Fields: []approvegen.CatalogField{},
+	}
+	for _, src := range sources {
+		if info, _ := methods.ParseNoteMethod(src.Body); info != nil {
+			if info.Locale == "" {
+				entry.Note = info.Note
+			} else {
+				if entry.Notes == nil {
+					entry.Notes = make(map[string]string)
+				}
+				entry.Notes[info.Locale] = info.Note
+			}
+		}
+	}
+	var props []catalog.Property
+	for _, param := range method.MethodParams {
+		tn := typeOf(param.Type)
+		if gen.IgnoreParam(tn) {
+			continue
+		}
+		for _, name := range param.Names {
+			field := approvegen.CatalogField{Name: utils2.UpperCamelCase(name.Name), Type: string(types.Type(tn).Storage())}
+			if alias := fields.GetName(utils2.EString(name.Name)); alias.String() != name.Name {
+				field.Alias = alias.String()
+			}
+			field.Redact = fields.GetRedact(utils2.EString(name.Name))
+			entry.Fields = append(entry.Fields, field)
+			_, optional := param.Type.(*ast.StarExpr)
+			props = append(props, catalog.Property{Name: field.Name, Title: field.Alias, Type: param.Type, Optional: optional})
+		}
+	}
+	entry.Schema = catalog.ObjectSchema(entry.Note, props, fullType).Marshal()
+	return entry
+}
+
+// recordSchema 记录参数结构体的当前版本并返回 hash; 与旧版本不兼容时需要 args::migrate 迁移函数
+func recordSchema(lock *schema.Lock, pkgPath string, gen *generator.Generator, method MyMethod, sources []annotation.Source, typeOf func(ast.Expr) string) string {
+	var fields []schema.Field
+	for _, param := range method.MethodParams {
+		tn := typeOf(param.Type)
+		if gen.IgnoreParam(tn) {
+			continue
+		}
+		for _, name := range param.Names {
+			fields = append(fields, schema.Field{Name: utils2.UpperCamelCase(name.Name), Type: string(types.Type(tn).Storage())})
+		}
 	}
-	pwd, _ := os.Getwd()
-	fmt.Println("[approveGen] Success:", filepath.Join(pwd, outputFileName))
+	version := schema.NewVersion(fields)
+	methodName := gen.MethodName(method)
+	key := schema.Key(pkgPath, methodName)
+	diffs := lock.Record(key, version)
+
+	migrated := make(map[string]bool)
+	for _, src := range sources {
+		info, err := methods.ParseMigrateMethod(src.Body)
+		if err != nil {
+			diags.Report(src, err)
+			continue
+		}
+		if info == nil {
+			continue
+		}
+		if !slices.ContainsFunc(lock.History(key), func(v schema.Version) bool { return v.Hash == info.From }) {
+			diags.Report(src, fmt.Errorf("args::migrate: unknown schema %q for %s", info.From, methodName))
+			continue
+		}
+		migrated[info.From] = true
+		gen.Migrations[method.GenMethod()] = append(gen.Migrations[method.GenMethod()], generator.Migration{From: info.From, Func: info.Func})
+	}
+	for _, diff := range diffs {
+		if migrated[diff.From.Hash] {
+			continue
+		}
+		diags.Errorf(method.CommentPos[0], "incompatible change to %s (%s): pending requests stored with schema %s can not be decoded, add @Approve(args::migrate=\"<func>\"; from=\"%s\") to upgrade them",
+			methodName, strings.Join(diff.Problems, ", "), diff.From.Hash, diff.From.Hash)
+	}
+	return version.Hash
+}
+
+// checkLocaleDup 同一种语言只能声明一次, 重复时报告错误并返回 false
+func checkLocaleDup(seen map[string]annotation.Source, kind, locale string, src annotation.Source) bool {
+	if _, ok := seen[locale]; ok {
+		if locale == "" {
+			diags.Report(src, fmt.Errorf("duplicate %s", kind))
+		} else {
+			diags.Report(src, fmt.Errorf("duplicate %s[%s]", kind, locale))
+		}
+		return false
+	}
+	seen[locale] = src
+	return true
+}
+
+// resolveCaptures 解析 ctx::capture, 根据同一个包中提取函数的返回值确定 Ctx 中字段的类型, key: GenMethod()
+func resolveCaptures(methodsMap map[string]MyMethod, pkgFuncs []MyMethod, importMgr *xast2.ImportManager, getNameFunc func(typ ast.Expr, imports xast2.ImportInfoSlice) string) map[string]*methods.CtxCaptureMethod {
+	funcs := lo.KeyBy(pkgFuncs, func(item MyMethod) string { return item.MethodName })
+	ret := make(map[string]*methods.CtxCaptureMethod)
+	for _, method := range utils2.IterSortMap(methodsMap) {
+		for _, src := range annotationsOf(method) {
+			info, err := methods.ParseCtxCaptureMethod(src.Body)
+			if err != nil {
+				diags.Report(src, err)
+				continue
+			}
+			if info == nil {
+				continue
+			}
+			if _, ok := ret[method.GenMethod()]; ok {
+				diags.Report(src, errors.New("duplicate ctx::capture annotation"))
+				continue
+			}
+			hasCtx := lo.SomeBy(method.MethodParams, func(item *ast.Field) bool {
+				return getNameFunc(item.Type, method.Imports) == "context.Context"
+			})
+			if !hasCtx {
+				diags.Report(src, fmt.Errorf("ctx::capture requires a context.Context parameter in %s", method.MethodName))
+				continue
+			}
+			if lo.SomeBy(method.MethodParams, func(item *ast.Field) bool {
+				return getNameFunc(item.Type, method.Imports) != "context.Context" && lo.SomeBy(item.Names, func(name *ast.Ident) bool { return utils2.UpperCamelCase(name.Name) == methods.CtxFieldName })
+			}) {
+				diags.Report(src, fmt.Errorf("ctx::capture: parameter name conflicts with the %s field", methods.CtxFieldName))
+				continue
+			}
+			m := &methods.CtxCaptureMethod{}
+			for _, c := range info.Captures {
+				field, err := captureField(c, funcs, importMgr, getNameFunc)
+				if err != nil {
+					diags.Report(src, annotation.Errorf(info.Offset, "ctx::capture: %s", err))
+					m = nil
+					break
+				}
+				m.Fields = append(m.Fields, field)
+			}
+			if m != nil {
+				ret[method.GenMethod()] = m
+			}
+		}
+	}
+	return ret
+}
+
+// captureField 提取函数必须是 func(context.Context) T 或 func(context.Context) (T, bool)
+func captureField(c methods.CtxCapture, funcs map[string]MyMethod, importMgr *xast2.ImportManager, getNameFunc func(typ ast.Expr, imports xast2.ImportInfoSlice) string) (methods.CtxField, error) {
+	fn, ok := funcs[c.Extract]
+	if !ok {
+		return methods.CtxField{}, fmt.Errorf("function %s is not found in this package", c.Extract)
+	}
+	var results []ast.Expr
+	for _, field := range fn.MethodResults {
+		for range max(len(field.Names), 1) {
+			results = append(results, field.Type)
+		}
+	}
+	for _, item := range fn.ExtractImportPath() {
+		importMgr.AddImport(item)
+	}
+	typeOf := func(typ ast.Expr) string { return getNameFunc(typ, fn.Imports) }
+	invalid := fn.Func.Type.TypeParams != nil || len(fn.MethodParams) != 1 || len(fn.MethodParams[0].Names) > 1 ||
+		typeOf(fn.MethodParams[0].Type) != "context.Context" ||
+		len(results) == 0 || len(results) > 2 || (len(results) == 2 && typeOf(results[1]) != "bool")
+	if invalid {
+		return methods.CtxField{}, fmt.Errorf("%s must be func(context.Context) T or func(context.Context) (T, bool)", c.Extract)
+	}
+	return methods.CtxField{
+		CtxCapture: c,
+		Field:      utils2.UpperCamelCase(c.Key),
+		Type:       typeOf(results[0]),
+		Ok:         len(results) == 2,
+	}, nil
 }
 
 func genMethodParamsString(fields []*ast.Field, isResult bool, nameFor func(ast.Expr) string) string {
@@ -590,23 +1234,88 @@ func NewAnnotationExtractor(annotationName string) *AnnotationExtractor {
 
 type MyMethod = types.MyMethod
 
+// MethodsIter 带有注释的函数和方法
 func (e *AnnotationExtractor) MethodsIter(file *ast.File) iter.Seq[MyMethod] {
+	return e.methodsIter(file, func(fn *ast.FuncDecl) bool {
+		return hasComment(fn.Doc, e.AnnotationName)
+	})
+}
+
+// PlainMethodsIter 没有注释的导出方法, 生成代理类型时直接转发
+func (e *AnnotationExtractor) PlainMethodsIter(file *ast.File) iter.Seq[MyMethod] {
+	return e.methodsIter(file, func(fn *ast.FuncDecl) bool {
+		return fn.Recv != nil && fn.Name.IsExported() && !hasComment(fn.Doc, e.AnnotationName)
+	})
+}
+
+// InterfaceMethodsIter 接口中带有注释的方法, 实现了该接口的对象可以作为 target
+func (e *AnnotationExtractor) InterfaceMethodsIter(file *ast.File) iter.Seq[MyMethod] {
+	return func(yield func(MyMethod) bool) {
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			iface, ok := spec.Type.(*ast.InterfaceType)
+			if !ok {
+				return true
+			}
+			for _, field := range iface.Methods.List {
+				ft, ok := field.Type.(*ast.FuncType)
+				if !ok || len(field.Names) == 0 || !hasComment(field.Doc, e.AnnotationName) {
+					continue
+				}
+				// 构造一个接收者为接口的方法声明, 与结构体方法统一处理
+				recv := &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent(spec.Name.Name)}}}
+				fn := &ast.FuncDecl{Doc: field.Doc, Recv: recv, Name: field.Names[0], Type: ft}
+				var sig = MyMethod{
+					StructName: spec.Name.Name,
+					MethodName: fn.Name.Name,
+					TypeParams: typeParamNames(spec.TypeParams),
+					Func:       fn,
+					Recv:       recv,
+					Interface:  true,
+					StartPos:   int(field.Pos()),
+					EndPos:     int(field.End()),
+				}
+				sig.Comment = lo.Map(field.Doc.List, func(item *ast.Comment, index int) string {
+					return item.Text
+				})
+				if ft.Params != nil {
+					sig.MethodParams = ft.Params.List
+				}
+				if ft.Results != nil {
+					sig.MethodResults = ft.Results.List
+				}
+				if !yield(sig) {
+					return false
+				}
+			}
+			return false
+		})
+	}
+}
+
+func (e *AnnotationExtractor) methodsIter(file *ast.File, match func(fn *ast.FuncDecl) bool) iter.Seq[MyMethod] {
 	return func(yield func(MyMethod) bool) {
 		ast.Inspect(file, func(n ast.Node) bool {
 			if fn, ok := n.(*ast.FuncDecl); ok {
-				if hasComment(fn.Doc, e.AnnotationName) {
+				if match(fn) {
 					var objName, structName string = getObjectName(fn.Recv)
 					var sig = MyMethod{
 						ObjName:    objName,
 						StructName: structName,
+						TypeParams: receiverTypeParams(fn.Recv),
 						MethodName: fn.Name.Name,
 						Func:       fn,
 						Recv:       fn.Recv,
-						Comment: lo.Map(fn.Doc.List, func(item *ast.Comment, index int) string {
+						StartPos:   int(fn.Pos()),
+						EndPos:     int(fn.End()),
+					}
+					if fn.Doc != nil {
+						sig.Comment = lo.Map(fn.Doc.List, func(item *ast.Comment, index int) string {
 							return item.Text
-						}),
-						StartPos: int(fn.Pos()),
-						EndPos:   int(fn.End()),
+						})
 					}
 					if fn.Type.Params != nil {
 						sig.MethodParams = fn.Type.Params.List
@@ -624,21 +1333,168 @@ func (e *AnnotationExtractor) MethodsIter(file *ast.File) iter.Seq[MyMethod] {
 	}
 }
 
-func (e *AnnotationExtractor) ExtractMethods(fSet *token.FileSet, filename string) []MyMethod {
-	file := lo.Must1(parser.ParseFile(fSet, filename, nil, parser.AllErrors|parser.ParseComments))
-	pkgPath, err := utils.GetFullPathWithPackage(filename)
-	if err != nil {
-		panic(err)
+// FuncsIter 包级函数, 用于查找 ctx::capture 提取函数的返回值类型
+func (e *AnnotationExtractor) FuncsIter(file *ast.File) iter.Seq[MyMethod] {
+	return e.methodsIter(file, func(fn *ast.FuncDecl) bool {
+		return fn.Recv == nil
+	})
+}
+
+// ExtractMethods 返回文件中带有注释的函数和方法, 没有注释的导出方法, 以及所有包级函数;
+// info 为 go/packages 的类型信息, 用于解析 import 的包名
+func (e *AnnotationExtractor) ExtractMethods(fSet *token.FileSet, file *ast.File, pkgPath string, info *gotypes.Info) (annotated, plain, funcs []MyMethod) {
+	importInfos := importsOf(file, info)
+	fill := func(methods_ []MyMethod) []MyMethod {
+		for i, method := range methods_ {
+			if method.Func.Doc != nil {
+				method.CommentPos = lo.Map(method.Func.Doc.List, func(item *ast.Comment, _ int) token.Position {
+					return fSet.Position(item.Slash)
+				})
+			}
+			method.PkgPath = pkgPath
+			method.Imports = importInfos
+			method.FilePkgName = file.Name.Name
+			methods_[i] = method
+		}
+		return methods_
+	}
+	annotated = slices.Collect(e.MethodsIter(file))
+	annotated = append(annotated, slices.Collect(e.InterfaceMethodsIter(file))...)
+	return fill(annotated), fill(slices.Collect(e.PlainMethodsIter(file))), fill(slices.Collect(e.FuncsIter(file)))
+}
+
+// checkParamTypes 参数和返回值不能使用接收者的类型参数(ApprovalCaller 不知道类型实参);
+// 保存到参数结构体的参数不能包含函数、channel 和非空接口, 它们无法序列化(或无法反序列化)
+func checkParamTypes(method MyMethod, info *gotypes.Info, isIgnoreType func(string) bool, typeOf func(ast.Expr) string) error {
+	typeParams := lo.SliceToMap(method.TypeParams, func(item string) (string, bool) { return item, true })
+	check := func(kind string, fields []*ast.Field) error {
+		for i, field := range fields {
+			name := fmt.Sprintf("#%d", i)
+			if len(field.Names) > 0 {
+				name = strings.Join(lo.Map(field.Names, func(item *ast.Ident, _ int) string { return item.Name }), ", ")
+			}
+			var typeParam string
+			var unsupported bool
+			ast.Inspect(field.Type, func(n ast.Node) bool {
+				switch t := n.(type) {
+				case *ast.SelectorExpr:
+					// pkg.T 中的 T 不是类型参数
+					return false
+				case *ast.Ident:
+					if typeParams[t.Name] {
+						typeParam = t.Name
+					}
+				case *ast.FuncType, *ast.ChanType:
+					unsupported = true
+				}
+				return typeParam == "" && !unsupported
+			})
+			if typeParam != "" {
+				return fmt.Errorf("%s %s of %s.%s uses type parameter %s of the receiver, approval callbacks are dispatched without type arguments; use a concrete type",
+					kind, name, method.StructNameWithoutPtr(), method.MethodName, typeParam)
+			}
+			// 有类型信息时按底层类型判断, type Callback func() 这样的命名类型在 AST 中只是一个标识符
+			if typ := paramType(info, field.Type); typ != nil {
+				unsupported = unserializable(typ)
+			}
+			if kind == "parameter" && unsupported && !isIgnoreType(typeOf(field.Type)) {
+				return fmt.Errorf("parameter %s of %s has type %s which can not be serialized into the approval request",
+					name, method.MethodName, typeOf(field.Type))
+			}
+		}
+		return nil
 	}
-	importInfos := new(xast2.ImportInfoSlice).From(file.Imports)
-	methods_ := slices.Collect(e.MethodsIter(file))
-	for i, method := range methods_ {
-		method.PkgPath = pkgPath
-		method.Imports = importInfos
-		method.FilePkgName = file.Name.Name
-		methods_[i] = method
+	if err := check("parameter", method.MethodParams); err != nil {
+		return err
 	}
-	return methods_
+	return check("result", method.MethodResults)
+}
+
+// paramType 参数的类型, 可变参数 ...T 返回 T; 没有类型信息时返回 nil
+func paramType(info *gotypes.Info, expr ast.Expr) gotypes.Type {
+	if info == nil {
+		return nil
+	}
+	if e, ok := expr.(*ast.Ellipsis); ok {
+		expr = e.Elt
+	}
+	typ := info.TypeOf(expr)
+	if typ == nil || typ == gotypes.Typ[gotypes.Invalid] {
+		return nil
+	}
+	return typ
+}
+
+// unserializable 类型(及指针、slice、array、map 的元素)的底层类型是否为函数、channel 或非空接口;
+// 空接口(any)可以保存任意可序列化的值
+func unserializable(typ gotypes.Type) bool {
+	if _, ok := typ.(*gotypes.TypeParam); ok {
+		// 类型参数由 checkParamTypes 单独检查, 它的底层类型是约束接口
+		return false
+	}
+	switch t := typ.Underlying().(type) {
+	case *gotypes.Signature, *gotypes.Chan:
+		return true
+	case *gotypes.Interface:
+		return !t.Empty()
+	case *gotypes.Pointer:
+		return unserializable(t.Elem())
+	case *gotypes.Slice:
+		return unserializable(t.Elem())
+	case *gotypes.Array:
+		return unserializable(t.Elem())
+	case *gotypes.Map:
+		return unserializable(t.Key()) || unserializable(t.Elem())
+	}
+	return false
+}
+
+// findImport 选择器 pkg.Type 中 pkg 对应的 import, 不是导入的包时返回 nil
+func findImport(expr *ast.SelectorExpr, imports xast2.ImportInfoSlice) *xast2.ImportInfo {
+	x, ok := expr.X.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	return imports.Find(x.Name)
+}
+
+// typeParamNames 类型声明的类型参数, type Store[K comparable, V any] ==> [K V]
+func typeParamNames(list *ast.FieldList) []string {
+	if list == nil {
+		return nil
+	}
+	var ret []string
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			ret = append(ret, name.Name)
+		}
+	}
+	return ret
+}
+
+// receiverTypeParams 泛型接收者的类型参数, (r *Repo[K, V]) ==> [K V]
+func receiverTypeParams(list *ast.FieldList) []string {
+	if list == nil || len(list.List) == 0 {
+		return nil
+	}
+	typ := list.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	var indices []ast.Expr
+	switch t := typ.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{t.Index}
+	case *ast.IndexListExpr:
+		indices = t.Indices
+	}
+	var ret []string
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok && ident.Name != "_" {
+			ret = append(ret, ident.Name)
+		}
+	}
+	return ret
 }
 
 func getObjectName(list *ast.FieldList) (objName, structName string) {
@@ -658,26 +1514,6 @@ func getObjectName(list *ast.FieldList) (objName, structName string) {
 	return
 }
 
-func getFiles(pwd string) []string {
-	var files []string
-	if err := filepath.Walk(pwd, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && filepath.Ext(path) == ".go" {
-			if strings.HasSuffix(path, "_test.go") {
-				return nil
-			}
-			files = append(files, path)
-		}
-		return nil
-	}); err != nil {
-		fmt.Printf("visit dir files failed: %s", err.Error())
-		os.Exit(1)
-	}
-	return files
-}
-
 func hasComment(comment *ast.CommentGroup, target string) bool {
 	if comment == nil {
 		return false
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"go/scanner"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/donutnomad/gotoolkit/approveGen/generator"
+	"github.com/donutnomad/gotoolkit/approveGen/schema"
+	"github.com/donutnomad/gotoolkit/approveGen/types"
+	"github.com/donutnomad/gotoolkit/lib/approvegen"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenTest 一种命令行参数组合及其输出的 golden 文件
+type goldenTest struct {
+	name       string
+	dir        string
+	cfg        generator.Config
+	genMethods bool
+	schema     bool // 使用 testdata/<dir>/schema.lock.json
+	catalog    bool // 同时对比 testdata/golden/<name>.catalog.json
+	tests      bool // 同时对比 testdata/golden/<name>_test.golden
+}
+
+func goldenTests() []goldenTest {
+	proxyCfg := generator.ConfigFromFlags(false, true, false, "")
+	proxyCfg.Proxy = true
+	jsonCfg := generator.ConfigFromFlags(false, true, false, "")
+	jsonCfg.Codec = generator.CodecJSON
+	msgpackCfg := generator.ConfigFromFlags(false, true, false, "")
+	msgpackCfg.Codec = generator.CodecMsgpack
+
+	return []goldenTest{
+		{name: "v1", cfg: generator.ConfigFromFlags(false, false, false, ""), genMethods: true},
+		{name: "v2", cfg: generator.ConfigFromFlags(true, false, false, ""), genMethods: true, tests: true},
+		{name: "v3", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true, tests: true},
+		{name: "v4", cfg: generator.ConfigFromFlags(false, false, true, ""), genMethods: true},
+		{name: "v3_pkgname", cfg: generator.ConfigFromFlags(false, true, false, "pp"), genMethods: true},
+		{name: "v1_no_methods", cfg: generator.ConfigFromFlags(false, false, false, ""), genMethods: false},
+		{name: "policy_v2", dir: "policy", cfg: generator.ConfigFromFlags(true, false, false, ""), genMethods: true},
+		{name: "policy_v3", dir: "policy", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true},
+		{name: "proxy_v3", dir: "proxy", cfg: proxyCfg, genMethods: true},
+		{name: "codec_sonic_v3", dir: "codec", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true},
+		{name: "codec_json_v3", dir: "codec", cfg: jsonCfg, genMethods: true},
+		{name: "codec_msgpack_v3", dir: "codec", cfg: msgpackCfg, genMethods: true, tests: true},
+		{name: "codec_msgpack_no_methods", dir: "codec", cfg: msgpackCfg, genMethods: false},
+		{name: "funcs_v1", dir: "funcs", cfg: generator.ConfigFromFlags(false, false, false, ""), genMethods: true},
+		{name: "funcs_v3", dir: "funcs", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true, tests: true},
+		{name: "catalog_v3", dir: "catalog", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true, catalog: true, tests: true},
+		{name: "redact_v1", dir: "redact", cfg: generator.ConfigFromFlags(false, false, false, ""), genMethods: true},
+		{name: "redact_v3", dir: "redact", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true},
+		{name: "capture_v1", dir: "capture", cfg: generator.ConfigFromFlags(false, false, false, ""), genMethods: true},
+		{name: "capture_v3", dir: "capture", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true},
+		{name: "decision_v1", dir: "decision", cfg: generator.ConfigFromFlags(false, false, false, ""), genMethods: true},
+		{name: "decision_v3", dir: "decision", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true},
+		{name: "locale_v3", dir: "locale", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true, catalog: true},
+		{name: "params_v1", dir: "params", cfg: generator.ConfigFromFlags(false, false, false, ""), genMethods: true},
+		{name: "params_v3", dir: "params", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true, tests: true},
+		{name: "preview_v3", dir: "preview", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true, tests: true},
+		{name: "imports_v3", dir: "imports", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true},
+		{name: "exec_v3", dir: "exec", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true},
+		{name: "schema_v3", dir: "schema", cfg: generator.ConfigFromFlags(false, true, false, ""), genMethods: true, schema: true},
+	}
+}
+
+// TestGenerateGolden 锁定每种命令行参数组合的输出
+func TestGenerateGolden(t *testing.T) {
+	for _, tt := range goldenTests() {
+		t.Run(tt.name, func(t *testing.T) {
+			got, tests, catalog := runGolden(t, tt)
+
+			assertGolden(t, filepath.Join("testdata", "golden", tt.name+".golden"), got)
+			if tt.tests {
+				assertGolden(t, filepath.Join("testdata", "golden", tt.name+"_test.golden"), tests.Bytes())
+			}
+			if tt.catalog {
+				bs, err := json.MarshalIndent(catalog, "", "  ")
+				require.NoError(t, err)
+				assertGolden(t, filepath.Join("testdata", "golden", tt.name+".catalog.json"), append(bs, '\n'))
+			}
+		})
+	}
+}
+
+// runGolden 按 tt 的参数生成代码, tt.tests 为 false 时 tests 为 nil
+func runGolden(t *testing.T, tt goldenTest) (got []byte, tests *bytes.Buffer, catalog *approvegen.Catalog) {
+	t.Helper()
+	if tt.dir == "" {
+		tt.dir = "svc"
+	}
+	pkg := loadTestPackage(t, tt.dir)
+
+	var lock *schema.Lock
+	if tt.schema {
+		var err error
+		lock, err = schema.Load(filepath.Join("testdata", tt.dir, "schema.lock.json"))
+		require.NoError(t, err)
+	}
+	if tt.catalog {
+		catalog = new(approvegen.Catalog)
+	}
+	if tt.tests {
+		tests = new(bytes.Buffer)
+	}
+	got, err := generate(pkg, tt.cfg, tt.genMethods, lock, catalog, tests)
+	require.NoError(t, err)
+	return got, tests, catalog
+}
+
+// TestGenerateStrictVet 以 -strict 生成每种参数组合的代码, 与 testdata 包放在一起执行 go vet, 防止生成无法编译的代码
+func TestGenerateStrictVet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("go vet is slow")
+	}
+	// 以 _ 开头的目录不会被 ./... 匹配, 需要放在模块内才能解析 import
+	root, err := os.MkdirTemp("testdata", "_vet")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(root) })
+
+	var patterns []string
+	for _, tt := range goldenTests() {
+		// 默认模式的 CallMethodForApproval 依赖项目中定义的 AllServices/BaseResponse, 无法单独编译
+		if tt.genMethods && !tt.cfg.HookRejected {
+			continue
+		}
+		tt.cfg.Strict = true
+		got, tests, _ := runGolden(t, tt)
+		if tt.dir == "" {
+			tt.dir = "svc"
+		}
+		dir := filepath.Join(root, tt.name)
+		require.NoError(t, os.CopyFS(dir, os.DirFS(filepath.Join("testdata", tt.dir))))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "approval_gen.go"), got, 0o644))
+		if tests != nil {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "approval_gen_test.go"), tests.Bytes(), 0o644))
+		}
+		patterns = append(patterns, "./"+filepath.ToSlash(dir))
+	}
+	out, err := exec.Command("go", append([]string{"vet"}, patterns...)...).CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
+
+func assertGolden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	if *update {
+		require.NoError(t, os.WriteFile(golden, got, 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+var testPackages = sync.OnceValues(func() ([]*packages.Package, error) {
+	// 一次加载所有 testdata 包, 共享依赖的类型检查; ./... 不匹配 testdata 目录, 需要逐个列出
+	entries, err := os.ReadDir("testdata")
+	if err != nil {
+		return nil, err
+	}
+	var patterns []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != "golden" {
+			patterns = append(patterns, "./testdata/"+entry.Name())
+		}
+	}
+	return loadPackages("", patterns...)
+})
+
+// loadTestPackage 返回 testdata/<dir> 的包
+func loadTestPackage(t *testing.T, dir string) *packages.Package {
+	t.Helper()
+	pkgs, err := testPackages()
+	require.NoError(t, err)
+	abs, err := filepath.Abs(filepath.Join("testdata", dir))
+	require.NoError(t, err)
+	pkg, ok := lo.Find(pkgs, func(item *packages.Package) bool { return item.Dir == abs })
+	require.True(t, ok, "package %s not found", dir)
+	return pkg
+}
+
+func TestGenerateSchemaIncompatible(t *testing.T) {
+	pkg := loadTestPackage(t, "policy")
+	key := schema.Key(pkg.PkgPath, "Payment_Refund")
+	lock := &schema.Lock{Methods: map[string][]schema.Version{
+		key: {schema.NewVersion([]schema.Field{{Name: "OrderID", Type: "string"}})},
+	}}
+
+	_, err := generate(pkg, generator.ConfigFromFlags(false, true, false, ""), true, lock, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field OrderID changed from string to int64")
+	assert.Contains(t, err.Error(), "args::migrate")
+
+	// 兼容的变更(新增字段)只记录新版本
+	lock = &schema.Lock{Methods: map[string][]schema.Version{
+		key: {schema.NewVersion(nil)},
+	}}
+	_, err = generate(pkg, generator.ConfigFromFlags(false, true, false, ""), true, lock, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, lock.History(key), 2)
+
+	// 其他包中同名的 Payment_Refund 互不影响
+	other := schema.Key("example.com/other", "Payment_Refund")
+	lock = &schema.Lock{Methods: map[string][]schema.Version{
+		other: {schema.NewVersion([]schema.Field{{Name: "OrderID", Type: "string"}})},
+	}}
+	_, err = generate(pkg, generator.ConfigFromFlags(false, true, false, ""), true, lock, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, lock.History(other), 1)
+	assert.Len(t, lock.History(key), 1)
+}
+
+func TestFindAnnotationsInvalid(t *testing.T) {
+	comments := []string{
+		"// Pay 支付",
+		"// @Approve args::note",
+		`// @Approve(args::note="支付")`,
+		"// @Approve{args::json}",
+		"// @Approve(args::json)",
+	}
+	method := types.MyMethod{
+		MethodName: "Pay",
+		Comment:    comments,
+		CommentPos: lo.Map(comments, func(_ string, i int) token.Position {
+			return token.Position{Filename: "pay.go", Line: i + 1, Column: 1}
+		}),
+	}
+
+	// 语法错误的注释不影响后面的注释
+	sources, err := method.FindAnnotations("Approve")
+	assert.Equal(t, []string{`args::note="支付"`, "args::json"}, lo.Map(sources, func(item annotation.Source, _ int) string { return item.Body }))
+	var list scanner.ErrorList
+	require.ErrorAs(t, err, &list)
+	require.Len(t, list, 2)
+	assert.Equal(t, "pay.go:2:12: invalid syntax, expected @Approve(...)", list[0].Error())
+	assert.Equal(t, "pay.go:4:12: invalid syntax, expected @Approve(...)", list[1].Error())
+
+	diags = &annotation.Diagnostics{}
+	annotationCache = make(map[string][]annotation.Source)
+	assert.Len(t, annotationsOf(method), 2)
+	require.ErrorAs(t, diags.Err(), &list)
+	assert.Len(t, list, 2)
+}
+
+func TestGenerateFuncNameOnFunction(t *testing.T) {
+	pkg := loadTestPackage(t, "funcs_invalid")
+	_, err := generate(pkg, generator.ConfigFromFlags(false, true, false, ""), true, nil, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "funcs.go:7:")
+	assert.Contains(t, err.Error(), "can only be used on struct methods")
+}
+
+func TestGenerateRedactNonString(t *testing.T) {
+	pkg := loadTestPackage(t, "redact_invalid")
+	_, err := generate(pkg, generator.ConfigFromFlags(false, true, false, ""), true, nil, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "redact.go:6:")
+	assert.Contains(t, err.Error(), `redact="hash" requires a string field, amount is int64`)
+}
+
+func TestGenerateCaptureInvalid(t *testing.T) {
+	pkg := loadTestPackage(t, "capture_invalid")
+	_, err := generate(pkg, generator.ConfigFromFlags(false, true, false, ""), true, nil, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "order.go:7:")
+	assert.Contains(t, err.Error(), "TenantFromCtx must be func(context.Context) T or func(context.Context) (T, bool)")
+}
+
+func TestGenerateLocaleWithoutDefault(t *testing.T) {
+	pkg := loadTestPackage(t, "locale_invalid")
+	_, err := generate(pkg, generator.ConfigFromFlags(false, true, false, ""), true, nil, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refund.go:9:")
+	assert.Contains(t, err.Error(), "args::note[en] requires a default args::note without locale")
+}
+
+func TestGenerateTestsRequiresTargets(t *testing.T) {
+	pkg := loadTestPackage(t, "svc")
+	_, err := generate(pkg, generator.ConfigFromFlags(false, false, false, ""), true, nil, nil, new(bytes.Buffer))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "-tests requires -methods")
+
+	_, err = generate(pkg, generator.ConfigFromFlags(false, true, false, ""), false, nil, nil, new(bytes.Buffer))
+	require.Error(t, err)
+}
+
+func TestGeneratePreviewRequiresCallerStruct(t *testing.T) {
+	pkg := loadTestPackage(t, "preview")
+	_, err := generate(pkg, generator.ConfigFromFlags(true, false, false, ""), true, nil, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "payment.go:16:")
+	assert.Contains(t, err.Error(), "func::preview requires -v3 or -v4")
+
+	// 不生成审批回调时 func::preview 没有作用
+	_, err = generate(pkg, generator.ConfigFromFlags(true, false, false, ""), false, nil, nil, nil)
+	require.NoError(t, err)
+}
+
+func TestGenerateParamsInvalid(t *testing.T) {
+	pkg := loadTestPackage(t, "params_invalid")
+	_, err := generate(pkg, generator.ConfigFromFlags(false, true, false, ""), true, nil, nil, nil)
+	var list scanner.ErrorList
+	require.ErrorAs(t, err, &list)
+	require.Len(t, list, 4)
+	list.Sort()
+	assert.Contains(t, list[0].Error(), "params.go:9:")
+	assert.Contains(t, list[0].Error(), "parameter item of Repo.Save uses type parameter T of the receiver")
+	assert.Contains(t, list[1].Error(), "params.go:15:")
+	assert.Contains(t, list[1].Error(), "parameter onChange of Watch has type func(string) which can not be serialized")
+	// 命名的函数类型和非空接口按底层类型检查
+	assert.Contains(t, list[2].Error(), "params.go:23:")
+	assert.Contains(t, list[2].Error(), "parameter cb of Subscribe has type Callback which can not be serialized")
+	assert.Contains(t, list[3].Error(), "params.go:27:")
+	assert.Contains(t, list[3].Error(), "parameter targets of Notify has type []Notifier which can not be serialized")
+}
+
+func TestGenerateProxyGeneric(t *testing.T) {
+	pkg := loadTestPackage(t, "params")
+	cfg := generator.ConfigFromFlags(false, true, false, "")
+	cfg.Proxy = true
+	_, err := generate(pkg, cfg, true, nil, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot generate approval proxy for generic type Repo")
+}
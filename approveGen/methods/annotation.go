@@ -0,0 +1,58 @@
+package methods
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+)
+
+// annotationKinds 所有支持的 @Approve 注释种类(由第一个子句的 key 决定)
+var annotationKinds = []string{
+	"args::string",
+	"args::note",
+	"args::json",
+	"args::migrate",
+	"args::field",
+	"args::formatter",
+	"ctx::capture",
+	"exec::noRetry",
+	"func::hookRejected",
+	"func::preview",
+	"policy::quorum",
+	"policy::ttl",
+	"global::func",
+	"global::template",
+}
+
+// localizedKinds 支持 [locale] 的注释, 例如 args::note[en]="Refund"
+var localizedKinds = []string{
+	"args::note",
+	"args::string",
+}
+
+// CheckAnnotation 校验注释语法以及注释种类
+func CheckAnnotation(content string) error {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return err
+	}
+	head := body.Head()
+	if head == nil {
+		return nil
+	}
+	// func:name="xxx" 自定义函数
+	if strings.HasPrefix(head.Key, "func:") && !strings.HasPrefix(head.Key, "func::") {
+		return nil
+	}
+	for _, kind := range annotationKinds {
+		if head.Key != kind {
+			continue
+		}
+		if head.Qualifier != "" && !slices.Contains(localizedKinds, kind) {
+			return annotation.Errorf(head.Offset, "%s does not support locale [%s]", head.Key, head.Qualifier)
+		}
+		return nil
+	}
+	return annotation.Errorf(head.Offset, "unknown annotation %q", head.Key)
+}
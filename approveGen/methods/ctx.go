@@ -0,0 +1,186 @@
+package methods
+
+import (
+	"go/token"
+	"strings"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/donutnomad/gotoolkit/internal/utils"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/samber/lo"
+)
+
+// CtxFieldName 参数结构体中保存 context 值的字段名, 序列化为 "__ctx"
+const CtxFieldName = "Ctx"
+
+// CtxCapture 需要保存的一个 context 值
+type CtxCapture struct {
+	Key     string // tenant
+	Extract string // 提交时从 ctx 取值的函数, func(ctx context.Context) T 或 func(ctx context.Context) (T, bool)
+	Restore string // 执行时写回 ctx 的函数, func(ctx context.Context, v T) context.Context
+}
+
+// CtxCaptureInfo ctx::capture 注释
+type CtxCaptureInfo struct {
+	Captures []CtxCapture
+	Offset   int // 注释值的位置, 用于报告提取函数的错误
+}
+
+// ParseCtxCaptureMethod 解析 ctx::capture 注释, 没有指定 restore 时
+// XxxFromCtx/XxxFromContext 对应的写回函数为 WithXxx
+// Example: ctx::capture="tenant=TenantFromCtx, user=UserFromCtx"; restore="user=ContextWithUser"
+func ParseCtxCaptureMethod(content string) (*CtxCaptureInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if body.HeadKey() != "ctx::capture" {
+		return nil, nil
+	}
+	if err := body.CheckKeys("restore"); err != nil {
+		return nil, err
+	}
+
+	head := body.Head()
+	if head.Value == nil || head.Value.Kind == annotation.List {
+		return nil, annotation.Errorf(head.Offset, `ctx::capture requires "key=Func, ..."`)
+	}
+	extracts, err := parseCtxPairs(head)
+	if err != nil {
+		return nil, err
+	}
+	var restores map[string]string
+	if restore := body.Lookup("restore"); restore != nil {
+		if restore.Value == nil || restore.Value.Kind == annotation.List {
+			return nil, annotation.Errorf(restore.Offset, `restore requires "key=Func, ..."`)
+		}
+		pairs, err := parseCtxPairs(restore)
+		if err != nil {
+			return nil, err
+		}
+		restores = make(map[string]string, len(pairs))
+		for _, pair := range pairs {
+			if !lo.ContainsBy(extracts, func(item [2]string) bool { return item[0] == pair[0] }) {
+				return nil, annotation.Errorf(restore.Value.Offset, "restore: %q is not captured", pair[0])
+			}
+			restores[pair[0]] = pair[1]
+		}
+	}
+
+	info := &CtxCaptureInfo{Offset: head.Value.Offset}
+	for _, pair := range extracts {
+		c := CtxCapture{Key: pair[0], Extract: pair[1], Restore: restores[pair[0]]}
+		if c.Restore == "" {
+			if c.Restore = defaultRestoreFunc(c.Extract); c.Restore == "" {
+				return nil, annotation.Errorf(head.Value.Offset, "ctx::capture: can not derive the restore function of %s, use restore=\"%s=Func\"", c.Extract, c.Key)
+			}
+		}
+		info.Captures = append(info.Captures, c)
+	}
+	return info, nil
+}
+
+// parseCtxPairs 解析 "key=Func, key2=Func2"
+func parseCtxPairs(clause *annotation.Clause) ([][2]string, error) {
+	var ret [][2]string
+	seen := make(map[string]bool)
+	for _, item := range clause.Value.Strings() {
+		key, fn, ok := strings.Cut(item, "=")
+		key, fn = strings.TrimSpace(key), strings.TrimSpace(fn)
+		if !ok || !token.IsIdentifier(key) || !token.IsIdentifier(fn) {
+			return nil, annotation.Errorf(clause.Value.Offset, "%s: %q is not key=Func, Func must be a function of this package", clause.Key, item)
+		}
+		if seen[key] {
+			return nil, annotation.Errorf(clause.Value.Offset, "%s: duplicate key %q", clause.Key, key)
+		}
+		seen[key] = true
+		ret = append(ret, [2]string{key, fn})
+	}
+	if len(ret) == 0 {
+		return nil, annotation.Errorf(clause.Value.Offset, "%s must not be empty", clause.Key)
+	}
+	return ret, nil
+}
+
+// defaultRestoreFunc TenantFromCtx ==> WithTenant, tenantFromContext ==> withTenant
+func defaultRestoreFunc(extract string) string {
+	for _, suffix := range []string{"FromCtx", "FromContext"} {
+		name, ok := strings.CutSuffix(extract, suffix)
+		if !ok || name == "" {
+			continue
+		}
+		if token.IsExported(extract) {
+			return "With" + name
+		}
+		return "with" + utils.UpperCamelCase(name)
+	}
+	return ""
+}
+
+// CtxField 参数结构体中保存的 context 值
+type CtxField struct {
+	CtxCapture
+	Field string // Ctx 中的字段名, 例如 Tenant
+	Type  string // 提取函数的返回值类型 T
+	Ok    bool   // 提取函数返回 (T, bool), 字段类型为 *T, 没有值时不写回
+}
+
+// CtxCaptureMethod 生成 CaptureCtx() 和 RestoreCtx()
+type CtxCaptureMethod struct {
+	Fields     []CtxField
+	Receiver   string
+	StructName string
+}
+
+// StructField 参数结构体中的 Ctx 字段
+func (m *CtxCaptureMethod) StructField() jen.Code {
+	return jen.Id(CtxFieldName).StructFunc(func(s *jen.Group) {
+		for _, f := range m.Fields {
+			if f.Ok {
+				s.Id(f.Field).Op("*").Qual("", f.Type).Tag(map[string]string{"json": f.Key + ",omitempty"})
+			} else {
+				s.Id(f.Field).Qual("", f.Type).Tag(map[string]string{"json": f.Key})
+			}
+		}
+	}).Tag(map[string]string{"json": "__ctx"})
+}
+
+func (m *CtxCaptureMethod) Generate(receiver, structName string) jen.Code {
+	m.Receiver = receiver
+	m.StructName = structName
+	return jen.Id(lo.Must1(m.generate())).Line()
+}
+
+func (m *CtxCaptureMethod) generate() (string, error) {
+	return utils.ExecuteTemplate(m, `
+// CaptureCtx 提交审批时保存 ctx 中的值
+func ({{.Receiver}} *{{.StructName}}) CaptureCtx(ctx context.Context) {
+{{- range .Fields}}
+{{- if .Ok}}
+    if v, ok := {{.Extract}}(ctx); ok {
+        {{$.Receiver}}.Ctx.{{.Field}} = &v
+    } else {
+        {{$.Receiver}}.Ctx.{{.Field}} = nil
+    }
+{{- else}}
+    {{$.Receiver}}.Ctx.{{.Field}} = {{.Extract}}(ctx)
+{{- end}}
+{{- end}}
+}
+
+// RestoreCtx 执行审批通过的方法前将保存的值写回 ctx
+func ({{.Receiver}} *{{.StructName}}) RestoreCtx(ctx context.Context) context.Context {
+{{- range .Fields}}
+{{- if .Ok}}
+    if {{$.Receiver}}.Ctx.{{.Field}} != nil {
+        ctx = {{.Restore}}(ctx, *{{$.Receiver}}.Ctx.{{.Field}})
+    }
+{{- else}}
+    ctx = {{.Restore}}(ctx, {{$.Receiver}}.Ctx.{{.Field}})
+{{- end}}
+{{- end}}
+    return ctx
+}
+`)
+}
@@ -0,0 +1,60 @@
+package methods
+
+import (
+	"testing"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCtxCaptureMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []CtxCapture
+		wantErr  bool
+		errAt    int
+	}{
+		{
+			name:    "default restore",
+			content: `ctx::capture="tenant=TenantFromCtx, user=userFromContext"`,
+			expected: []CtxCapture{
+				{Key: "tenant", Extract: "TenantFromCtx", Restore: "WithTenant"},
+				{Key: "user", Extract: "userFromContext", Restore: "withUser"},
+			},
+		},
+		{
+			name:    "explicit restore",
+			content: `ctx::capture="trace=TraceID"; restore="trace=SetTraceID"`,
+			expected: []CtxCapture{
+				{Key: "trace", Extract: "TraceID", Restore: "SetTraceID"},
+			},
+		},
+		{name: "not capture", content: `args::note="x"`},
+		{name: "no restore", wantErr: true, content: `ctx::capture="trace=TraceID"`, errAt: 13},
+		{name: "restore unknown key", wantErr: true, content: `ctx::capture="tenant=TenantFromCtx"; restore="user=WithUser"`, errAt: 45},
+		{name: "qualified func", wantErr: true, content: `ctx::capture="tenant=auth.TenantFromCtx"`, errAt: 13},
+		{name: "duplicate key", wantErr: true, content: `ctx::capture="a=AFromCtx, a=BFromCtx"`, errAt: 13},
+		{name: "empty", wantErr: true, content: `ctx::capture=""`, errAt: 13},
+		{name: "missing value", wantErr: true, content: `ctx::capture`, errAt: 0},
+		{name: "unknown key", wantErr: true, content: `ctx::capture="a=AFromCtx"; b=1`, errAt: 27},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseCtxCaptureMethod(tt.content)
+			if tt.wantErr {
+				var ae *annotation.Error
+				require.ErrorAs(t, err, &ae)
+				assert.Equal(t, tt.errAt, ae.Offset)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expected == nil {
+				assert.Nil(t, info)
+				return
+			}
+			assert.Equal(t, tt.expected, info.Captures)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package methods
+
+import (
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/donutnomad/gotoolkit/internal/utils"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/samber/lo"
+)
+
+// ExecMethodInfo 审批后回调的执行方式
+type ExecMethodInfo struct {
+	NoRetry bool
+}
+
+// ParseExecMethod 解析 exec::noRetry, 回调有不能重复执行的副作用时使用
+// Example: exec::noRetry
+func ParseExecMethod(content string) (*ExecMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if body.HeadKey() != "exec::noRetry" {
+		return nil, nil
+	}
+	if err := body.CheckKeys(); err != nil {
+		return nil, err
+	}
+	noRetry, err := body.Head().Bool()
+	if err != nil {
+		return nil, err
+	}
+	if !noRetry {
+		return nil, nil
+	}
+	return &ExecMethodInfo{NoRetry: true}, nil
+}
+
+func (info *ExecMethodInfo) Generator() *ExecMethod {
+	return &ExecMethod{Info: info}
+}
+
+type ExecMethod struct {
+	Info       *ExecMethodInfo
+	Receiver   string
+	StructName string
+}
+
+func (m *ExecMethod) Generate(receiver, structName string) jen.Code {
+	m.Receiver = receiver
+	m.StructName = structName
+	return jen.Id(lo.Must1(m.generate())).Line()
+}
+
+func (m *ExecMethod) generate() (string, error) {
+	return utils.ExecuteTemplate(m, `
+// Retryable 回调失败后 approvegen.Runner 是否重试
+func ({{.Receiver}} *{{.StructName}}) Retryable() bool {
+    return {{not .Info.NoRetry}}
+}
+`)
+}
@@ -0,0 +1,39 @@
+package methods
+
+import (
+	"testing"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExecMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *ExecMethodInfo
+		wantErr  bool
+		errAt    int
+	}{
+		{name: "bare", content: `exec::noRetry`, expected: &ExecMethodInfo{NoRetry: true}},
+		{name: "true", content: `exec::noRetry=true`, expected: &ExecMethodInfo{NoRetry: true}},
+		{name: "false", content: `exec::noRetry=false`, expected: nil},
+		{name: "not exec", content: `args::note="x"`, expected: nil},
+		{name: "bad bool", wantErr: true, content: `exec::noRetry=yes`, errAt: 14},
+		{name: "unknown key", wantErr: true, content: `exec::noRetry; max=3`, errAt: 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseExecMethod(tt.content)
+			if tt.wantErr {
+				var ae *annotation.Error
+				require.ErrorAs(t, err, &ae)
+				assert.Equal(t, tt.errAt, ae.Offset)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
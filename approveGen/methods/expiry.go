@@ -0,0 +1,91 @@
+package methods
+
+import (
+	"time"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/donutnomad/gotoolkit/internal/utils"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/samber/lo"
+)
+
+// expireActions onExpire 的取值与 lib/approvegen 中常量的对应关系
+var expireActions = map[string]string{
+	"expire":  "ExpireOnly",
+	"reject":  "ExpireReject",
+	"approve": "ExpireApprove",
+}
+
+// ExpiryMethodInfo 审批请求的有效期
+type ExpiryMethodInfo struct {
+	TTL      time.Duration
+	OnExpire string // expire/reject/approve, 为空表示只标记为过期
+}
+
+// ParseExpiryMethod 解析审批请求的有效期注释
+// Example: policy::ttl="24h"; onExpire="reject"
+func ParseExpiryMethod(content string) (*ExpiryMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if body.HeadKey() != "policy::ttl" {
+		return nil, nil
+	}
+	if err := body.CheckKeys("onExpire"); err != nil {
+		return nil, err
+	}
+
+	head := body.Head()
+	if head.Value == nil || head.Value.Kind == annotation.List {
+		return nil, annotation.Errorf(head.Offset, "policy::ttl requires a duration")
+	}
+	ttl, err := time.ParseDuration(head.Text())
+	if err != nil || ttl <= 0 {
+		return nil, annotation.Errorf(head.Value.Offset, "policy::ttl: %q is not a positive duration", head.Text())
+	}
+
+	info := &ExpiryMethodInfo{TTL: ttl}
+	if onExpire := body.Lookup("onExpire"); onExpire != nil {
+		if _, ok := expireActions[onExpire.Text()]; !ok || onExpire.Value.Kind == annotation.List {
+			return nil, annotation.Errorf(onExpire.Offset, "onExpire must be one of expire, reject, approve")
+		}
+		info.OnExpire = onExpire.Text()
+	}
+	return info, nil
+}
+
+func (info *ExpiryMethodInfo) Generator() *ExpiryMethod {
+	return &ExpiryMethod{Info: info}
+}
+
+type ExpiryMethod struct {
+	Info       *ExpiryMethodInfo
+	Receiver   string
+	StructName string
+	Pkg        string // lib/approvegen 的包名
+	Action     string
+}
+
+func (m *ExpiryMethod) Generate(receiver, structName, pkg string) jen.Code {
+	m.Receiver = receiver
+	m.StructName = structName
+	m.Pkg = pkg
+	m.Action = expireActions[m.Info.OnExpire]
+	return jen.Id(lo.Must1(m.generate())).Line()
+}
+
+func (m *ExpiryMethod) generate() (string, error) {
+	return utils.ExecuteTemplate(m,
+		`
+func ({{.Receiver}} *{{.StructName}}) Expiry() {{.Pkg}}.Expiry {
+    return {{.Pkg}}.Expiry{
+        TTL: {{.Info.TTL.Nanoseconds}}, // {{.Info.TTL}}
+{{- if .Action}}
+        OnExpire: {{.Pkg}}.{{.Action}},
+{{- end}}
+    }
+}
+`)
+}
@@ -0,0 +1,54 @@
+package methods
+
+import (
+	"testing"
+	"time"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiryMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *ExpiryMethodInfo
+		wantErr  bool
+		errAt    int
+	}{
+		{
+			name:     "reject",
+			content:  `policy::ttl="24h"; onExpire="reject"`,
+			expected: &ExpiryMethodInfo{TTL: 24 * time.Hour, OnExpire: "reject"},
+		},
+		{
+			name:     "bare",
+			content:  `policy::ttl=90m`,
+			expected: &ExpiryMethodInfo{TTL: 90 * time.Minute},
+		},
+		{
+			name:     "not expiry",
+			content:  `policy::quorum=2`,
+			expected: nil,
+		},
+		{name: "missing ttl", wantErr: true, content: `policy::ttl`, errAt: 0},
+		{name: "bad ttl", wantErr: true, content: `policy::ttl="1 day"`, errAt: 12},
+		{name: "negative ttl", wantErr: true, content: `policy::ttl="-1h"`, errAt: 12},
+		{name: "bad action", wantErr: true, content: `policy::ttl="1h"; onExpire="cancel"`, errAt: 18},
+		{name: "unknown key", wantErr: true, content: `policy::ttl="1h"; quorum=2`, errAt: 18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseExpiryMethod(tt.content)
+			if tt.wantErr {
+				var ae *annotation.Error
+				require.ErrorAs(t, err, &ae)
+				assert.Equal(t, tt.errAt, ae.Offset)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
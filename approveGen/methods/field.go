@@ -1,9 +1,8 @@
 package methods
 
 import (
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
 	"github.com/donutnomad/gotoolkit/internal/utils"
-	"regexp"
-	"strings"
 )
 
 // FieldInfo stores field parsing information
@@ -12,6 +11,7 @@ type FieldInfo struct {
 	Function  string // 格式化方法
 	Alias     string // 字段别名
 	Formatter string // formatter方法名
+	Redact    string // 脱敏方式: mask, hash, omit
 }
 
 type FieldInfoSlice []FieldInfo
@@ -34,6 +34,16 @@ func (s FieldInfoSlice) GetFormatter(name utils.EString) utils.EString {
 	return ""
 }
 
+// GetRedact 字段的脱敏方式, 没有设置时返回空字符串
+func (s FieldInfoSlice) GetRedact(name utils.EString) string {
+	for _, field := range s {
+		if field.Field == name.String() && field.Redact != "" {
+			return field.Redact
+		}
+	}
+	return ""
+}
+
 func (s FieldInfoSlice) GetFunction(name utils.EString) utils.EString {
 	for _, field := range s {
 		if field.Field == name.String() && field.Function != "" {
@@ -44,51 +54,58 @@ func (s FieldInfoSlice) GetFunction(name utils.EString) utils.EString {
 }
 
 // ParseFieldMethod parses field method annotation
-// Example: args::field="rawID"; func="格式化的方法"; alias="别名"; args::formatter="CreateHookRejected"
-func ParseFieldMethod(contents []string) FieldInfoSlice {
+// Example: args::field="rawID"; func="格式化的方法"; alias="别名"; args::formatter="CreateHookRejected"; redact="mask"
+func ParseFieldMethod(contents []string) (FieldInfoSlice, error) {
 	var infos []FieldInfo
-
 	for _, content := range contents {
-		info := FieldInfo{}
-
-		// 分割分号分隔的部分
-		for _, part := range strings.Split(content, ";") {
-			part = strings.TrimSpace(part)
-
-			switch {
-			// 解析字段名
-			case strings.HasPrefix(part, "args::field="):
-				if m := regexp.MustCompile(`"(.*?)"`).FindStringSubmatch(part); len(m) > 1 {
-					info.Field = m[1]
-				}
-
-			// 解析格式化方法
-			case strings.HasPrefix(part, "func="):
-				if m := regexp.MustCompile(`"(.*?)"`).FindStringSubmatch(part); len(m) > 1 {
-					info.Function = m[1]
-				}
-
-			// 解析别名
-			case strings.HasPrefix(part, "alias="):
-				if m := regexp.MustCompile(`"(.*?)"`).FindStringSubmatch(part); len(m) > 1 {
-					info.Alias = m[1]
-				}
-
-			// 解析formatter
-			case strings.HasPrefix(part, "args::formatter="):
-				if m := regexp.MustCompile(`"(.*?)"`).FindStringSubmatch(part); len(m) > 1 {
-					info.Formatter = m[1]
-				}
-			}
+		info, err := ParseFieldAnnotation(content)
+		if err != nil {
+			return nil, err
 		}
-
 		// 如果有有效的字段名，添加到结果中
-		if info.Field != "" {
-			infos = append(infos, info)
+		if info != nil {
+			infos = append(infos, *info)
 		}
 	}
+	return infos, nil
+}
 
-	return infos
+// ParseFieldAnnotation parses a single args::field annotation, returns nil if content is not one
+func ParseFieldAnnotation(content string) (*FieldInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if !body.Has("args::field") {
+		return nil, nil
+	}
+
+	info := &FieldInfo{}
+	for _, clause := range body.Clauses {
+		switch clause.Key {
+		case "args::field":
+			info.Field = clause.Text()
+		case "func":
+			info.Function = clause.Text()
+		case "alias":
+			info.Alias = clause.Text()
+		case "args::formatter":
+			info.Formatter = clause.Text()
+		case "redact":
+			switch clause.Text() {
+			case "mask", "hash", "omit":
+				info.Redact = clause.Text()
+			default:
+				return nil, annotation.Errorf(clause.Offset, "redact must be one of mask, hash, omit, got %q", clause.Text())
+			}
+		default:
+			return nil, annotation.Errorf(clause.Offset, "unknown field %q in args::field annotation", clause.Key)
+		}
+	}
+	if info.Field == "" {
+		return nil, annotation.Errorf(body.Lookup("args::field").Offset, "args::field requires a non-empty value")
+	}
+	return info, nil
 }
 
 // ParseFormatterMethod parses formatter method annotation
@@ -97,22 +114,19 @@ func ParseFieldMethod(contents []string) FieldInfoSlice {
 //	args::formatter="CreateHookRejected"
 //	args::formatter= CreateHookRejected
 //	args::formatter=CreateHookRejected
-func ParseFormatterMethod(contents []string) string {
+//	args::formatter (使用默认值, 返回 DEFAULT)
+func ParseFormatterMethod(contents []string) (string, error) {
 	for _, content := range contents {
-		if strings.Contains(content, "args::formatter") {
-			// 首先尝试匹配带引号的格式: args::formatter="value"
-			if m := regexp.MustCompile(`args::formatter\s*=\s*"(.*?)"`).FindStringSubmatch(content); len(m) > 1 {
-				return m[1]
-			}
-			// 然后尝试匹配不带引号的格式: args::formatter= value 或 args::formatter=value
-			if m := regexp.MustCompile(`args::formatter\s*=\s*([^;\s)]+)`).FindStringSubmatch(content); len(m) > 1 {
-				return strings.TrimSpace(m[1])
-			}
-			// 如果只有 args::formatter 没有值，返回DEFAULT表示需要使用默认值
-			if strings.Contains(content, "args::formatter") && !strings.Contains(content, "=") {
-				return "DEFAULT"
+		body, err := annotation.Parse(content)
+		if err != nil {
+			return "", err
+		}
+		if clause := body.Lookup("args::formatter"); clause != nil {
+			if clause.Text() == "" {
+				return "DEFAULT", nil
 			}
+			return clause.Text(), nil
 		}
 	}
-	return ""
+	return "", nil
 }
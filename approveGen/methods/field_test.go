@@ -61,6 +61,17 @@ func TestParseFieldMethod(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "redact",
+			contents: []string{
+				`args::field="account"; alias="卡号"; redact="mask"`,
+				`args::field="password"; redact=omit`,
+			},
+			expected: []*FieldInfo{
+				{Field: "account", Alias: "卡号", Redact: "mask"},
+				{Field: "password", Redact: "omit"},
+			},
+		},
 		{
 			name:     "empty input",
 			contents: []string{},
@@ -79,7 +90,8 @@ func TestParseFieldMethod(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := ParseFieldMethod(tt.contents)
+			result, err := ParseFieldMethod(tt.contents)
+			assert.NoError(t, err)
 			if tt.expected == nil {
 				assert.Empty(t, result)
 			} else {
@@ -88,8 +100,45 @@ func TestParseFieldMethod(t *testing.T) {
 					assert.Equal(t, expected.Field, result[i].Field)
 					assert.Equal(t, expected.Function, result[i].Function)
 					assert.Equal(t, expected.Alias, result[i].Alias)
+					assert.Equal(t, expected.Redact, result[i].Redact)
 				}
 			}
 		})
 	}
 }
+
+func TestParseFieldMethodErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown field", content: `args::field="rawID"; unknown="x"`},
+		{name: "empty field", content: `args::field=""; alias="别名"`},
+		{name: "unterminated string", content: `args::field="rawID; alias="别名"`},
+		{name: "unknown redact", content: `args::field="rawID"; redact="blur"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFieldMethod([]string{tt.content})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseFormatterMethod(t *testing.T) {
+	tests := []struct {
+		contents []string
+		expected string
+	}{
+		{contents: []string{`args::formatter="CreateHookRejected"`}, expected: "CreateHookRejected"},
+		{contents: []string{`args::formatter= CreateHookRejected`}, expected: "CreateHookRejected"},
+		{contents: []string{`args::note="x"`, `args::field="id"; args::formatter=FormatID`}, expected: "FormatID"},
+		{contents: []string{`args::formatter`}, expected: "DEFAULT"},
+		{contents: []string{`args::note="formatter"`}, expected: ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormatterMethod(tt.contents)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, got, tt.contents)
+	}
+}
@@ -18,6 +18,7 @@ type FormatterMethodStruct struct {
 	MethodName string               // 原始方法名，如 Create
 	CustomName string               // 自定义名称，如 CreateHookApproved 或默认为 MethodName
 	Fields     []FormatterFieldInfo // 结构体中的所有字段信息
+	Redacted   bool                 // 传给 formatter 的是 Redacted() 的副本
 }
 
 // FormatterFieldInfo 结构体字段信息
@@ -41,12 +42,13 @@ func NewFormatterMethod() *FormatterMethod {
 }
 
 // AddMethod 添加一个需要格式化的方法
-func (f *FormatterMethod) AddMethod(structName, methodName, customName string, fields []FormatterFieldInfo) {
+func (f *FormatterMethod) AddMethod(structName, methodName, customName string, fields []FormatterFieldInfo, redacted bool) {
 	f.Info.MethodStructs = append(f.Info.MethodStructs, FormatterMethodStruct{
 		StructName: structName,
 		MethodName: methodName,
 		CustomName: customName,
 		Fields:     fields,
+		Redacted:   redacted,
 	})
 }
 
@@ -146,6 +148,9 @@ func (f *FormatterMethod) Generate() jen.Code {
 				}
 
 				switchGroup.Case(jen.Op("*").Id(methodStruct.StructName)).BlockFunc(func(caseGroup *jen.Group) {
+					if methodStruct.Redacted {
+						caseGroup.Id("v").Op("=").Id("v").Dot("Redacted").Call()
+					}
 					// 生成接口定义
 					interfaceName := fmt.Sprintf("%s_Interface", methodStruct.StructName)
 					caseGroup.Type().Id(interfaceName).Interface(
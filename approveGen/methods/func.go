@@ -1,9 +1,9 @@
 package methods
 
 import (
-	"regexp"
 	"strings"
 
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
 	"github.com/donutnomad/gotoolkit/internal/utils"
 
 	"github.com/dave/jennifer/jen"
@@ -27,177 +27,82 @@ type FuncMethodInfo struct {
 // ParseFuncMethod 解析自定义函数方法的注解
 // 例子:
 // func:name="approveFor"; module="ABC"; event="EVENT"; nest=true; args=["name string", "codes github.com/donutnomad/gotoolkit/internal/utils#EString"]
-func ParseFuncMethod(content string) *FuncMethodInfo {
+func ParseFuncMethod(content string) (*FuncMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	head := body.Head()
+	if head == nil || !strings.HasPrefix(head.Key, "func:") || strings.HasPrefix(head.Key, "func::") {
+		return nil, nil
+	}
+	if head.Key != "func:name" {
+		return nil, annotation.Errorf(head.Offset, "func annotation must start with func:name, got %s", head.Key)
+	}
+	if head.Text() == "" {
+		return nil, annotation.Errorf(head.Offset, "func:name requires a non-empty value")
+	}
+
 	info := &FuncMethodInfo{
+		Name:       head.Text(),
 		Attributes: make(map[string]string),
 		Args:       []FuncMethodArg{},
 	}
-
-	// 检查是否以func:开头
-	if !strings.HasPrefix(content, "func:") {
-		return nil
-	}
-
-	// 分割分号分隔的部分
-	parts := strings.Split(content, ";")
-
-	// 处理第一部分，它应该包含name属性
-	firstPart := strings.TrimSpace(parts[0])
-	if strings.HasPrefix(firstPart, "func:name=") {
-		if m := regexp.MustCompile(`"(.*?)"`).FindStringSubmatch(firstPart); len(m) > 1 {
-			info.Name = m[1]
-		}
-	} else {
-		// 尝试解析其他格式的第一部分
-		nameMatch := regexp.MustCompile(`func:(.*?)="(.*?)"`).FindStringSubmatch(firstPart)
-		if len(nameMatch) > 2 {
-			key := strings.TrimSpace(nameMatch[1])
-			value := nameMatch[2]
-			if key == "name" {
-				info.Name = value
-			} else {
-				info.Attributes[key] = value
+	for _, clause := range body.Clauses[1:] {
+		switch clause.Key {
+		case "nest":
+			if info.Nest, err = clause.Bool(); err != nil {
+				return nil, err
 			}
-		}
-	}
-
-	// 处理其余部分
-	for _, part := range parts[1:] {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-
-		// 特殊处理nest=true
-		if part == "nest=true" {
-			info.Nest = true
-			continue
-		}
-
-		// 特殊处理args数组
-		if strings.HasPrefix(part, "args=") {
-			if args := ParseArgsArray(part); len(args) > 0 {
-				info.Args = args
+		case "args":
+			if info.Args, err = parseArgs(clause); err != nil {
+				return nil, err
 			}
-			continue
-		}
-
-		// 解析键值对
-		keyValue := regexp.MustCompile(`(.*?)="(.*?)"`).FindStringSubmatch(part)
-		if len(keyValue) > 2 {
-			key := strings.TrimSpace(keyValue[1])
-			value := keyValue[2]
-
-			// 另一种方式处理nest
-			if key == "nest" && value == "true" {
-				info.Nest = true
-			} else {
-				info.Attributes[key] = value
+		default:
+			if clause.Value == nil || clause.Value.Kind == annotation.List {
+				return nil, annotation.Errorf(clause.Offset, "func attribute %s requires a string value", clause.Key)
 			}
+			info.Attributes[clause.Key] = clause.Text()
 		}
 	}
-
-	// 如果没有找到函数名，返回nil
-	if info.Name == "" {
-		return nil
-	}
-
-	return info
+	return info, nil
 }
 
 // ParseArgsArray 解析args数组
 // 支持格式: args=["name string", "codes github.com/donutnomad/gotoolkit/internal/utils#EString"]
-func ParseArgsArray(argsStr string) []FuncMethodArg {
-	var args []FuncMethodArg
-
-	// 提取数组内容
-	arrayMatch := regexp.MustCompile(`args=\[(.*?)\]`).FindStringSubmatch(argsStr)
-	if len(arrayMatch) < 2 {
-		return args
+func ParseArgsArray(argsStr string) ([]FuncMethodArg, error) {
+	body, err := annotation.Parse(argsStr)
+	if err != nil {
+		return nil, err
 	}
-
-	arrayContent := arrayMatch[1]
-	if arrayContent == "" {
-		return args
-	}
-
-	// 解析数组元素，支持引号包围的字符串
-	elements := parseArrayElements(arrayContent)
-
-	for _, element := range elements {
-		if arg := parseArgElement(element); arg != nil {
-			args = append(args, *arg)
-		}
+	head := body.Head()
+	if head == nil || !strings.HasSuffix(head.Key, "args") {
+		return nil, annotation.Errorf(0, "expected args=[...]")
 	}
-
-	return args
+	return parseArgs(head)
 }
 
-// parseArrayElements 解析数组元素，处理引号包围的字符串
-func parseArrayElements(content string) []string {
-	var elements []string
-	var current strings.Builder
-	inQuotes := false
-	escaped := false
-
-	for i, r := range content {
-		if escaped {
-			current.WriteRune(r)
-			escaped = false
-			continue
-		}
-
-		if r == '\\' {
-			escaped = true
-			current.WriteRune(r)
-			continue
-		}
-
-		if r == '"' {
-			inQuotes = !inQuotes
-			current.WriteRune(r)
-			// 如果是最后一个字符且退出引号，保存元素
-			if i == len(content)-1 && !inQuotes {
-				if element := strings.TrimSpace(current.String()); element != "" {
-					elements = append(elements, element)
-				}
-			}
-			continue
-		}
-
-		if r == ',' && !inQuotes {
-			if element := strings.TrimSpace(current.String()); element != "" {
-				elements = append(elements, element)
-			}
-			current.Reset()
-			continue
-		}
-
-		current.WriteRune(r)
-
-		// 如果是最后一个字符且不在引号内，添加到结果中
-		if i == len(content)-1 && !inQuotes {
-			if element := strings.TrimSpace(current.String()); element != "" {
-				elements = append(elements, element)
-			}
+func parseArgs(clause *annotation.Clause) ([]FuncMethodArg, error) {
+	if clause.Value == nil || clause.Value.Kind != annotation.List {
+		return nil, annotation.Errorf(clause.Offset, "%s requires a list value like [\"name string\"]", clause.Key)
+	}
+	var args []FuncMethodArg
+	for _, item := range clause.Value.Items {
+		arg := parseArgElement(item.Text)
+		if arg == nil {
+			return nil, annotation.Errorf(item.Offset, "invalid arg %q, expected \"<name> <type>\"", item.Text)
 		}
+		args = append(args, *arg)
 	}
-
-	return elements
+	return args, nil
 }
 
 // parseArgElement 解析单个参数元素
 // 支持格式: "name string" 或 "codes github.com/donutnomad/gotoolkit/internal/utils#EString"
 func parseArgElement(element string) *FuncMethodArg {
-	// 移除引号
-	element = strings.Trim(element, `"`)
-	if element == "" {
-		return nil
-	}
-
 	// 解析参数名和类型
 	parts := strings.Fields(element)
-	if len(parts) < 2 {
+	if len(parts) != 2 {
 		return nil
 	}
 
@@ -236,6 +141,12 @@ type FuncMethod struct {
 	Receiver   string // (p *Persion) ==> p
 	StructName string // (p *Persion) ==> Persion
 	MethodArg  string // Struct Method Args ==> &_AAAeMethodBBBB{}
+	ZeroValues bool   // 返回 (T..., error) 时模板只返回 error, 其余返回值使用零值
+}
+
+func (m *FuncMethod) WithZeroValues(zeroValues bool) *FuncMethod {
+	m.ZeroValues = zeroValues
+	return m
 }
 
 func (m *FuncMethod) Generate(template, receiver, structName, methodName string, methodNames []string, methodArgCode string, methodStructArgCode string, returnArgsCode string, v2 bool) jen.Code {
@@ -252,6 +163,10 @@ func (m *FuncMethod) Generate(template, receiver, structName, methodName string,
 			cc := utils.MustExecuteTemplate(m, m.Template)
 			if strings.HasPrefix(cc, "\n") {
 				group.Id(cc[1:])
+			} else if m.ZeroValues && m.returnsValuesAndError(returnArgsCode) {
+				// 模板只返回 error, 其余返回值使用零值
+				group.Id("err := ").Id(cc)
+				group.Return().Id(m.generateDefaultReturnValues(returnArgsCode))
 			} else {
 				group.Return().Id(cc)
 			}
@@ -326,6 +241,12 @@ func (m *FuncMethod) needsReturnValueHandling(returnArgsCode string) bool {
 	return len(returnTypes) > 1 || (len(returnTypes) == 1 && returnTypes[0] != "error")
 }
 
+// returnsValuesAndError 返回值是否为 (T..., error), 例如 (int64, error)
+func (m *FuncMethod) returnsValuesAndError(returnArgsCode string) bool {
+	returnTypes := m.parseReturnTypes(returnArgsCode)
+	return len(returnTypes) > 1 && returnTypes[len(returnTypes)-1] == "error"
+}
+
 // parseReturnTypes 解析返回值类型
 func (m *FuncMethod) parseReturnTypes(returnArgsCode string) []string {
 	if returnArgsCode == "" {
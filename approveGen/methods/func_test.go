@@ -12,6 +12,7 @@ func TestParseFuncMethod(t *testing.T) {
 		content  string
 		expected *FuncMethodInfo
 		isNil    bool
+		wantErr  bool
 	}{
 		{
 			name:    "基本函数定义",
@@ -109,12 +110,44 @@ func TestParseFuncMethod(t *testing.T) {
 			name:    "无效格式 - 没有name属性",
 			content: `func:module="ABC"; event="EVENT"`,
 			isNil:   true,
+			wantErr: true,
+		},
+		{
+			name:    "属性值包含引号和分号",
+			content: `func:name="approveFor"; module="A\"B;C"`,
+			expected: &FuncMethodInfo{
+				Name:       "approveFor",
+				Attributes: map[string]string{"module": `A"B;C`},
+				Args:       []FuncMethodArg{},
+			},
+		},
+		{
+			name:    "hookRejected不是自定义函数",
+			content: `func::hookRejected`,
+			isNil:   true,
+		},
+		{
+			name:    "无效的args元素",
+			content: `func:name="approveFor"; args=["name"]`,
+			isNil:   true,
+			wantErr: true,
+		},
+		{
+			name:    "nest不是布尔值",
+			content: `func:name="approveFor"; nest=yes`,
+			isNil:   true,
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := ParseFuncMethod(tt.content)
+			result, err := ParseFuncMethod(tt.content)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
 
 			if tt.isNil {
 				assert.Nil(t, result)
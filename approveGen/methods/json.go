@@ -2,35 +2,41 @@ package methods
 
 import (
 	"github.com/dave/jennifer/jen"
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
 	"github.com/donutnomad/gotoolkit/internal/utils"
 	"github.com/samber/lo"
-	"regexp"
-	"strings"
 )
 
 // JsonMethodInfo 存储Json方法的定义信息
 type JsonMethodInfo struct {
 	InterfaceCheck bool // 是否需要生成接口检查
+	Generate       bool // 生成 Json(), 返回 JSON 格式的参数用于展示
 }
 
 // ParseJsonMethod 解析Json方法的注解
-// 例子: args::json
-func ParseJsonMethod(content string) *JsonMethodInfo {
-	info := &JsonMethodInfo{
-		InterfaceCheck: false,
+// 例子: args::json 或 args::json; generate
+func ParseJsonMethod(content string) (*JsonMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
 	}
-
-	// 检查是否包含args::json
-	if !strings.Contains(content, "args::json") {
-		return nil
+	if body.HeadKey() != "args::json" {
+		return nil, nil
 	}
-
-	// 简单解析，如果找到args::json则启用接口检查
-	if regexp.MustCompile(`args::json`).MatchString(content) {
-		info.InterfaceCheck = true
+	if err := body.CheckKeys("generate"); err != nil {
+		return nil, err
 	}
-
-	return info
+	if c := body.Lookup("generate"); c != nil {
+		generate, err := c.Bool()
+		if err != nil {
+			return nil, err
+		}
+		if generate {
+			return &JsonMethodInfo{Generate: true}, nil
+		}
+	}
+	// 找到args::json则启用接口检查
+	return &JsonMethodInfo{InterfaceCheck: true}, nil
 }
 
 func (info *JsonMethodInfo) Generator() *JsonMethod {
@@ -45,6 +51,10 @@ type JsonMethod struct {
 	Receiver   string
 	StructName string
 	MethodName string
+	Marshal    string   // 输出 JSON 的序列化函数, 例如 sonic.Marshal
+	Redacted   bool     // 序列化 Redacted() 的副本
+	Omit       []string // redact="omit" 的字段, 从输出的 JSON 中删除
+	Pkg        string   // lib/approvegen 的包名, 删除字段时使用
 }
 
 func (m *JsonMethod) WithMethod(name string) *JsonMethod {
@@ -52,6 +62,23 @@ func (m *JsonMethod) WithMethod(name string) *JsonMethod {
 	return m
 }
 
+func (m *JsonMethod) WithMarshal(marshal string) *JsonMethod {
+	m.Marshal = marshal
+	return m
+}
+
+func (m *JsonMethod) WithRedacted(redacted bool) *JsonMethod {
+	m.Redacted = redacted
+	return m
+}
+
+// WithOmit 从输出中删除 redact="omit" 的字段, Redacted() 只能把它们置为零值
+func (m *JsonMethod) WithOmit(pkg string, fields []string) *JsonMethod {
+	m.Pkg = pkg
+	m.Omit = fields
+	return m
+}
+
 func (m *JsonMethod) Generate(receiver, structName string) jen.Code {
 	m.Receiver = receiver
 	m.StructName = structName
@@ -63,6 +90,25 @@ func (m *JsonMethod) Generate(receiver, structName string) jen.Code {
 
 func (m *JsonMethod) generate() (string, error) {
 	var template string
+	if m.info.Generate {
+		// 返回 JSON 格式的参数用于展示, -codec 为 JSON 格式且没有脱敏字段时可以直接传给 UnmarshalMethodArgs
+		template = `
+func ({{.Receiver}} *{{.StructName}}) {{.MethodName}}() (any, error) {
+    bs, err := {{.Marshal}}({{.Receiver}}{{if .Redacted}}.Redacted(){{end}})
+    if err != nil {
+        return nil, err
+    }
+{{- if .Omit}}
+    bs, err = {{.Pkg}}.OmitKeys(bs{{range .Omit}}, "{{.}}"{{end}})
+    if err != nil {
+        return nil, err
+    }
+{{- end}}
+    return string(bs), nil
+}
+`
+		return utils.ExecuteTemplate(m, template)
+	}
 	if m.info.InterfaceCheck {
 		// 生成接口检查器
 		template = `var _ interface { Json() (any, error) } = (*{{.StructName}})(nil)`
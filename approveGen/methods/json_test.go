@@ -0,0 +1,47 @@
+package methods
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJsonMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *JsonMethodInfo
+		wantErr  bool
+	}{
+		{name: "interface check", content: `args::json`, expected: &JsonMethodInfo{InterfaceCheck: true}},
+		{name: "generate", content: `args::json; generate`, expected: &JsonMethodInfo{Generate: true}},
+		{name: "generate false", content: `args::json; generate=false`, expected: &JsonMethodInfo{InterfaceCheck: true}},
+		{name: "other annotation", content: `args::note="x"`},
+		{name: "unknown key", content: `args::json; codec="json"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseJsonMethod(tt.content)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
+
+func TestJsonMethodGenerate(t *testing.T) {
+	code, err := (&JsonMethod{
+		info:       &JsonMethodInfo{Generate: true},
+		Receiver:   "p",
+		StructName: "_XMethodY",
+		MethodName: "Json",
+		Marshal:    "json.Marshal",
+	}).generate()
+	require.NoError(t, err)
+	assert.Contains(t, code, "func (p *_XMethodY) Json() (any, error) {")
+	assert.Contains(t, code, "bs, err := json.Marshal(p)")
+}
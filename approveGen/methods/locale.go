@@ -0,0 +1,66 @@
+package methods
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/donutnomad/gotoolkit/internal/utils"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/samber/lo"
+)
+
+var localeRegexp = regexp.MustCompile(`^[A-Za-z]{2,8}([-_][A-Za-z0-9]{1,8})*$`)
+
+// checkLocale 校验 args::note[en] 中的语言标记, 例如 en, zh-CN, zh_Hant_TW
+func checkLocale(head *annotation.Clause) error {
+	if head.Qualifier != "" && !localeRegexp.MatchString(head.Qualifier) {
+		return annotation.Errorf(head.Offset, "%s: %q is not a valid locale", head.Key, head.Qualifier)
+	}
+	return nil
+}
+
+// LocaleSuffix 语言对应的方法名后缀, zh-CN ==> ZhCN
+func LocaleSuffix(locale string) string {
+	return utils.UpperCamelCase(strings.NewReplacer("-", "_").Replace(locale))
+}
+
+// LocaleVariant 一种语言的返回值
+type LocaleVariant struct {
+	Locale string
+	Expr   string // Go 表达式, 例如 "Refund" 或 p.stringEn()
+}
+
+// LocaleMethod 生成 NoteFor(locale)/StringFor(locale): 依次尝试 locale 及去掉地区后的语言
+// (en-US ==> en), 都没有时返回默认语言的 Note()/String()
+type LocaleMethod struct {
+	Base       string // Note 或 String
+	Variants   []LocaleVariant
+	Receiver   string
+	StructName string
+}
+
+func (m *LocaleMethod) Generate(receiver, structName string) jen.Code {
+	m.Receiver = receiver
+	m.StructName = structName
+	return jen.Id(lo.Must1(m.generate())).Line()
+}
+
+func (m *LocaleMethod) generate() (string, error) {
+	return utils.ExecuteTemplate(m, `
+// {{.Base}}For 指定语言的 {{.Base}}(), 没有对应语言时使用默认语言
+func ({{.Receiver}} *{{.StructName}}) {{.Base}}For(locale string) string {
+    switch locale {
+{{- range .Variants}}
+    case {{printf "%q" .Locale}}:
+        return {{.Expr}}
+{{- end}}
+    }
+    if i := strings.LastIndexAny(locale, "-_"); i > 0 {
+        return {{.Receiver}}.{{.Base}}For(locale[:i])
+    }
+    return {{.Receiver}}.{{.Base}}()
+}
+`)
+}
@@ -0,0 +1,42 @@
+package methods
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocaleSuffix(t *testing.T) {
+	assert.Equal(t, "En", LocaleSuffix("en"))
+	assert.Equal(t, "ZhCN", LocaleSuffix("zh-CN"))
+	assert.Equal(t, "ZhHantTW", LocaleSuffix("zh_Hant_TW"))
+}
+
+func TestLocaleMethodGenerate(t *testing.T) {
+	m := &LocaleMethod{
+		Base: "Note",
+		Variants: []LocaleVariant{
+			{Locale: "en", Expr: `"Refund"`},
+			{Locale: "zh-TW", Expr: `"退款申請"`},
+		},
+		Receiver:   "p",
+		StructName: "TestStruct",
+	}
+	code, err := m.generate()
+	require.NoError(t, err)
+	assert.Contains(t, code, "func (p *TestStruct) NoteFor(locale string) string {")
+	assert.Contains(t, code, `case "en":`)
+	assert.Contains(t, code, `return "退款申請"`)
+	assert.Contains(t, code, "return p.NoteFor(locale[:i])")
+	assert.Contains(t, code, "return p.Note()")
+}
+
+func TestCheckAnnotationLocale(t *testing.T) {
+	assert.NoError(t, CheckAnnotation(`args::note[en]="Refund"`))
+	assert.NoError(t, CheckAnnotation(`args::string[zh-CN]="$key=$value"`))
+
+	err := CheckAnnotation(`args::json[en]`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support locale [en]")
+}
@@ -1,9 +1,9 @@
 package methods
 
 import (
-	"regexp"
 	"strings"
 
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
 	"github.com/donutnomad/gotoolkit/internal/utils"
 
 	"github.com/dave/jennifer/jen"
@@ -12,23 +12,35 @@ import (
 
 // NoteMethodInfo stores the Note method definition information
 type NoteMethodInfo struct {
-	Note string // The note string to be returned
+	Note   string // The note string to be returned
+	Locale string // args::note[en] ==> en, empty for the default locale
 }
 
 // ParseNoteMethod parses the Note method annotation
-// Example: args::note="This is a note"
-func ParseNoteMethod(content string) *NoteMethodInfo {
-	info := &NoteMethodInfo{}
-
-	// Parse the note content
-	if m := regexp.MustCompile(`args::note="(.*?)"`).FindStringSubmatch(content); len(m) > 1 {
-		info.Note = strings.ReplaceAll(m[1], "\t", " ")
+// Example: args::note="This is a note", args::note[zh]="这是一个备注"
+func ParseNoteMethod(content string) (*NoteMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if body.HeadKey() != "args::note" {
+		return nil, nil
+	}
+	if err := body.CheckKeys(); err != nil {
+		return nil, err
+	}
+	if err := checkLocale(body.Head()); err != nil {
+		return nil, err
 	}
 
+	info := &NoteMethodInfo{
+		Note:   strings.ReplaceAll(body.Head().Text(), "\t", " "),
+		Locale: body.Head().Qualifier,
+	}
 	if info.Note == "" {
-		return nil
+		return nil, nil
 	}
-	return info
+	return info, nil
 }
 
 func (info *NoteMethodInfo) Generator() *NoteMethod {
@@ -60,7 +72,7 @@ func (m *NoteMethod) generate() (string, error) {
 	return utils.ExecuteTemplate(m,
 		`
 func ({{.Receiver}} *{{.StructName}}) {{.MethodName}}() string {
-    return "{{.Info.Note}}"
+    return {{printf "%q" .Info.Note}}
 }
 `)
 }
@@ -29,15 +29,32 @@ func TestParseNoteMethod(t *testing.T) {
 			content:  `args::invalid="test"`,
 			expected: nil,
 		},
+		{
+			name:    "locale",
+			content: `args::note[zh-CN]="退款"`,
+			expected: &NoteMethodInfo{
+				Note:   "退款",
+				Locale: "zh-CN",
+			},
+		},
+		{
+			name:    "escaped quote",
+			content: `args::note="say \"hi\""`,
+			expected: &NoteMethodInfo{
+				Note: `say "hi"`,
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := ParseNoteMethod(tt.content)
+			result, err := ParseNoteMethod(tt.content)
+			assert.NoError(t, err)
 			if tt.expected == nil {
 				assert.Nil(t, result)
 			} else {
 				assert.Equal(t, tt.expected.Note, result.Note)
+				assert.Equal(t, tt.expected.Locale, result.Locale)
 			}
 		})
 	}
@@ -57,4 +74,22 @@ func TestNoteMethod_Generate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Contains(t, code, "Test Note")
 	assert.Contains(t, code, "func (p *TestStruct) Note() string")
+
+	// 引号需要转义
+	code, err = (&NoteMethodInfo{Note: `say "hi"`}).Generator().generate()
+	assert.NoError(t, err)
+	assert.Contains(t, code, `return "say \"hi\""`)
+}
+
+func TestParseNoteMethodErrors(t *testing.T) {
+	for _, content := range []string{
+		`args::note="unterminated`,
+		`args::note="a" "b"`,
+		`args::note="a"; extra="b"`,
+		`args::note[e]="a"`,
+		`args::note[en US]="a"`,
+	} {
+		_, err := ParseNoteMethod(content)
+		assert.Error(t, err, content)
+	}
 }
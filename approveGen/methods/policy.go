@@ -0,0 +1,91 @@
+package methods
+
+import (
+	"strconv"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/donutnomad/gotoolkit/internal/utils"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/samber/lo"
+)
+
+// PolicyMethodInfo 审批策略
+type PolicyMethodInfo struct {
+	Quorum   int
+	Roles    []string
+	Distinct bool
+}
+
+// ParsePolicyMethod 解析审批策略注释
+// Example: policy::quorum=2; roles="finance,admin"; distinct=true
+func ParsePolicyMethod(content string) (*PolicyMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if body.HeadKey() != "policy::quorum" {
+		return nil, nil
+	}
+	if err := body.CheckKeys("roles", "distinct"); err != nil {
+		return nil, err
+	}
+
+	head := body.Head()
+	if head.Value == nil || head.Value.Kind == annotation.List {
+		return nil, annotation.Errorf(head.Offset, "policy::quorum requires a number")
+	}
+	quorum, err := strconv.Atoi(head.Text())
+	if err != nil || quorum <= 0 {
+		return nil, annotation.Errorf(head.Value.Offset, "policy::quorum: %q is not a positive integer", head.Text())
+	}
+
+	info := &PolicyMethodInfo{Quorum: quorum}
+	if roles := body.Lookup("roles"); roles != nil {
+		info.Roles = roles.Value.Strings()
+		if len(info.Roles) == 0 {
+			return nil, annotation.Errorf(roles.Offset, "roles must not be empty")
+		}
+	}
+	if distinct := body.Lookup("distinct"); distinct != nil {
+		if info.Distinct, err = distinct.Bool(); err != nil {
+			return nil, err
+		}
+	}
+	return info, nil
+}
+
+func (info *PolicyMethodInfo) Generator() *PolicyMethod {
+	return &PolicyMethod{Info: info}
+}
+
+type PolicyMethod struct {
+	Info       *PolicyMethodInfo
+	Receiver   string
+	StructName string
+	Pkg        string // lib/approvegen 的包名
+}
+
+func (m *PolicyMethod) Generate(receiver, structName, pkg string) jen.Code {
+	m.Receiver = receiver
+	m.StructName = structName
+	m.Pkg = pkg
+	return jen.Id(lo.Must1(m.generate())).Line()
+}
+
+func (m *PolicyMethod) generate() (string, error) {
+	return utils.ExecuteTemplate(m,
+		`
+func ({{.Receiver}} *{{.StructName}}) Policy() {{.Pkg}}.Policy {
+    return {{.Pkg}}.Policy{
+        Quorum: {{.Info.Quorum}},
+{{- if .Info.Roles}}
+        Roles: []string{ {{- range $i, $r := .Info.Roles}}{{if $i}}, {{end}}{{printf "%q" $r}}{{end -}} },
+{{- end}}
+{{- if .Info.Distinct}}
+        Distinct: true,
+{{- end}}
+    }
+}
+`)
+}
@@ -0,0 +1,53 @@
+package methods
+
+import (
+	"testing"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicyMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *PolicyMethodInfo
+		wantErr  bool
+		errAt    int
+	}{
+		{
+			name:     "full",
+			content:  `policy::quorum=2; roles="finance,admin"; distinct=true`,
+			expected: &PolicyMethodInfo{Quorum: 2, Roles: []string{"finance", "admin"}, Distinct: true},
+		},
+		{
+			name:     "roles list",
+			content:  `policy::quorum=1; roles=[finance, admin]`,
+			expected: &PolicyMethodInfo{Quorum: 1, Roles: []string{"finance", "admin"}},
+		},
+		{
+			name:     "not policy",
+			content:  `args::note="x"`,
+			expected: nil,
+		},
+		{name: "zero quorum", wantErr: true, content: `policy::quorum=0`, errAt: 15},
+		{name: "missing quorum", wantErr: true, content: `policy::quorum`, errAt: 0},
+		{name: "bad distinct", wantErr: true, content: `policy::quorum=2; distinct=yes`, errAt: 27},
+		{name: "unknown key", wantErr: true, content: `policy::quorum=2; role=admin`, errAt: 18},
+		{name: "empty roles", wantErr: true, content: `policy::quorum=2; roles=""`, errAt: 18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParsePolicyMethod(tt.content)
+			if tt.wantErr {
+				var ae *annotation.Error
+				require.ErrorAs(t, err, &ae)
+				assert.Equal(t, tt.errAt, ae.Offset)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package methods
+
+import (
+	"go/token"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+)
+
+// PreviewMethodInfo 审批前预览, 生成的 ApprovalCaller.Preview 调用目标上的 Method
+type PreviewMethodInfo struct {
+	Method string // 为空时使用 <方法名>Preview
+}
+
+// ParsePreviewMethod 解析 func::preview
+// Example: func::preview
+// Example: func::preview="DiffRefund"
+func ParsePreviewMethod(content string) (*PreviewMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if body.HeadKey() != "func::preview" {
+		return nil, nil
+	}
+	if err := body.CheckKeys(); err != nil {
+		return nil, err
+	}
+	head := body.Head()
+	if head.Value == nil {
+		return &PreviewMethodInfo{}, nil
+	}
+	if head.Value.Kind == annotation.List || !token.IsIdentifier(head.Value.Text) || !token.IsExported(head.Value.Text) {
+		return nil, annotation.Errorf(head.Value.Offset, "func::preview: %q is not an exported method name", head.Value.Text)
+	}
+	return &PreviewMethodInfo{Method: head.Value.Text}, nil
+}
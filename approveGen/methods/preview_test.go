@@ -0,0 +1,39 @@
+package methods
+
+import (
+	"testing"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePreviewMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *PreviewMethodInfo
+		wantErr  bool
+		errAt    int
+	}{
+		{name: "bare", content: `func::preview`, expected: &PreviewMethodInfo{}},
+		{name: "custom", content: `func::preview="DiffRefund"`, expected: &PreviewMethodInfo{Method: "DiffRefund"}},
+		{name: "not preview", content: `func::hookRejected`, expected: nil},
+		{name: "unexported", wantErr: true, content: `func::preview="diff"`, errAt: 14},
+		{name: "not ident", wantErr: true, content: `func::preview="Diff Refund"`, errAt: 14},
+		{name: "unknown key", wantErr: true, content: `func::preview; name="x"`, errAt: 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParsePreviewMethod(tt.content)
+			if tt.wantErr {
+				var ae *annotation.Error
+				require.ErrorAs(t, err, &ae)
+				assert.Equal(t, tt.errAt, ae.Offset)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package methods
+
+import (
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/donutnomad/gotoolkit/internal/utils"
+	"github.com/samber/lo"
+)
+
+// RedactField 需要脱敏的字段
+type RedactField struct {
+	Field string // 结构体字段名
+	Mode  string // mask, hash, omit
+	Ptr   bool   // *string
+}
+
+// Const lib/approvegen 中脱敏方式的常量名
+func (f RedactField) Const() string {
+	return "Redact" + strings.ToUpper(f.Mode[:1]) + f.Mode[1:]
+}
+
+// RedactMethod 生成 Redacted(), 返回脱敏后的副本
+type RedactMethod struct {
+	Fields     []RedactField
+	Receiver   string
+	StructName string
+	Pkg        string // lib/approvegen 的包名
+}
+
+func (m *RedactMethod) Generate(receiver, structName, pkg string) jen.Code {
+	m.Receiver = receiver
+	m.StructName = structName
+	m.Pkg = pkg
+	return jen.Id(lo.Must1(m.generate())).Line()
+}
+
+// HasOmit 是否有需要清空的字段
+func (m *RedactMethod) HasOmit() bool {
+	return lo.ContainsBy(m.Fields, func(item RedactField) bool { return item.Mode == "omit" })
+}
+
+func (m *RedactMethod) generate() (string, error) {
+	return utils.ExecuteTemplate(m, `
+// Redacted 返回脱敏后的副本, 用于 String()/Json()/Format 的输出; 执行时使用原始值
+func ({{.Receiver}} *{{.StructName}}) Redacted() *{{.StructName}} {
+    c := *{{.Receiver}}
+{{- if .HasOmit}}
+    var zero {{.StructName}}
+{{- end}}
+{{- range .Fields}}
+{{- if eq .Mode "omit"}}
+    c.{{.Field}} = zero.{{.Field}}
+{{- else if .Ptr}}
+    if c.{{.Field}} != nil {
+        v := {{$.Pkg}}.Redact(*c.{{.Field}}, {{$.Pkg}}.{{.Const}})
+        c.{{.Field}} = &v
+    }
+{{- else}}
+    c.{{.Field}} = {{$.Pkg}}.Redact(c.{{.Field}}, {{$.Pkg}}.{{.Const}})
+{{- end}}
+{{- end}}
+    return &c
+}
+`)
+}
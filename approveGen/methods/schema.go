@@ -0,0 +1,62 @@
+package methods
+
+import (
+	"github.com/dave/jennifer/jen"
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
+	"github.com/donutnomad/gotoolkit/internal/utils"
+	"github.com/samber/lo"
+)
+
+// MigrateMethodInfo 将旧版本参数升级到当前版本的函数
+type MigrateMethodInfo struct {
+	Func string // func(content []byte) (*_XxxMethodYyy, error)
+	From string // 旧版本的 hash
+}
+
+// ParseMigrateMethod 解析迁移函数注释
+// Example: args::migrate="migrateCreateV1"; from="v1:0123456789ab"
+func ParseMigrateMethod(content string) (*MigrateMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if body.HeadKey() != "args::migrate" {
+		return nil, nil
+	}
+	if err := body.CheckKeys("from"); err != nil {
+		return nil, err
+	}
+	head := body.Head()
+	if head.Text() == "" {
+		return nil, annotation.Errorf(head.Offset, "args::migrate requires a function name")
+	}
+	from := body.Lookup("from")
+	if from.Text() == "" {
+		return nil, annotation.Errorf(head.Offset, "args::migrate requires from=\"<schema hash>\"")
+	}
+	return &MigrateMethodInfo{Func: head.Text(), From: from.Text()}, nil
+}
+
+// SchemaMethod 生成 SchemaHash() 以及写入版本的 MarshalJSON()
+type SchemaMethod struct {
+	Hash       string
+	Receiver   string
+	StructName string
+	Pkg        string // lib/approvegen 的包名
+}
+
+func (m *SchemaMethod) Generate(receiver, structName, pkg string) jen.Code {
+	m.Receiver = receiver
+	m.StructName = structName
+	m.Pkg = pkg
+	return jen.Id(lo.Must1(utils.ExecuteTemplate(m, `
+func ({{.Receiver}} *{{.StructName}}) SchemaHash() string {
+    return {{printf "%q" .Hash}}
+}
+
+func ({{.Receiver}} *{{.StructName}}) MarshalJSON() ([]byte, error) {
+    type plain {{.StructName}}
+    return {{.Pkg}}.MarshalWithSchema((*plain)({{.Receiver}}), {{.Receiver}}.SchemaHash())
+}
+`))).Line()
+}
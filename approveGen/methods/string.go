@@ -1,11 +1,12 @@
 package methods
 
 import (
+	"strings"
+
 	"github.com/dave/jennifer/jen"
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
 	"github.com/donutnomad/gotoolkit/internal/utils"
 	"github.com/samber/lo"
-	"regexp"
-	"strings"
 )
 
 // StringMethodInfo 存储String方法的定义信息
@@ -14,69 +15,54 @@ type StringMethodInfo struct {
 	Separator     string   // 分隔符
 	IncludeFields []string // 需要包含的字段（优先级高于exclude）
 	ExcludeFields []string // 需要排除的字段
+	Locale        string   // args::string[en] ==> en, 为空表示默认语言
 }
 
 // ParseStringMethod 解析String方法的注解
 // 例子:
 // args::string="$key==>$key, 索引$idx==>$value"; sep="|| "; exclude="email,roles"; include="rawID"
-func ParseStringMethod(content string) *StringMethodInfo {
-	info := &StringMethodInfo{
-		Separator: ", ",
+func ParseStringMethod(content string) (*StringMethodInfo, error) {
+	body, err := annotation.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if body.HeadKey() != "args::string" {
+		return nil, nil
+	}
+	if err := body.CheckKeys("sep", "include", "exclude"); err != nil {
+		return nil, err
+	}
+	if err := checkLocale(body.Head()); err != nil {
+		return nil, err
 	}
 
-	// 分割分号分隔的部分
-	for idx, part := range strings.Split(content, ";") {
-		part = strings.TrimSpace(part)
-
-		if idx == 0 && !strings.HasPrefix(part, "args::string=") {
-			return nil
-		}
-
-		switch {
-		// 解析参数模板
-		case strings.HasPrefix(part, "args::string="):
-			t := part[len("args::string="):]
-			if strings.Count(t, "\"") != 2 {
-				panic("unknown field: " + part + ", use ;")
-			}
-			t = t[1 : len(t)-1]
-			info.ArgsTemplate = t
-		case strings.HasPrefix(part, "include="):
-			value := strings.TrimPrefix(part, "include=")
-			value = strings.Trim(value, `"`)
-			if value != "" {
-				info.IncludeFields = strings.Split(value, ",")
-				for i, field := range info.IncludeFields {
-					info.IncludeFields[i] = strings.TrimSpace(field)
-				}
+	info := &StringMethodInfo{
+		Separator:    ", ",
+		ArgsTemplate: body.Head().Text(),
+		Locale:       body.Head().Qualifier,
+	}
+	for _, clause := range body.Clauses[1:] {
+		switch clause.Key {
+		case "include":
+			if fields := clause.Value.Strings(); len(fields) > 0 {
+				info.IncludeFields = fields
 				// 当include存在时，清空exclude
 				info.ExcludeFields = nil
 			}
-
 		// 解析排除字段
-		case strings.HasPrefix(part, "exclude="):
-			value := strings.TrimPrefix(part, "exclude=")
-			value = strings.Trim(value, `"`)
-			if value != "" {
-				info.ExcludeFields = strings.Split(value, ",")
-				// 清理每个字段的空白
-				for i, field := range info.ExcludeFields {
-					info.ExcludeFields[i] = strings.TrimSpace(field)
-				}
+		case "exclude":
+			if fields := clause.Value.Strings(); len(fields) > 0 {
+				info.ExcludeFields = fields
 			}
 		// 解析分隔符
-		case strings.HasPrefix(part, "sep="):
-			if m := regexp.MustCompile(`"(.*?)"`).FindStringSubmatch(part); len(m) > 1 {
-				info.Separator = m[1]
-			}
-		default:
-			panic("unknown field: " + part)
+		case "sep":
+			info.Separator = clause.Text()
 		}
 	}
 	if info.ArgsTemplate == "" {
-		return nil
+		return nil, nil
 	}
-	return info
+	return info, nil
 }
 
 func (info *StringMethodInfo) Generator() *StringMethod {
@@ -93,6 +79,7 @@ type StringMethod struct {
 	MethodName string
 	ArgsSep    string
 	Args       []ArgInfo
+	Redacted   bool // 使用 Redacted() 的副本输出
 }
 
 type ArgInfo struct {
@@ -101,6 +88,7 @@ type ArgInfo struct {
 	FormatFunc string // 为Field格式化使用, 例如将uid ==> 转换为email调用方法uinfo(uid)
 	IsPtr      bool   // 字段类型是否是指针
 	IsMoOption bool   // 字段类型是否是mo.Option[x]
+	Omit       bool   // redact="omit", 不输出
 }
 
 func (m *StringMethod) WithMethod(name string) *StringMethod {
@@ -108,11 +96,19 @@ func (m *StringMethod) WithMethod(name string) *StringMethod {
 	return m
 }
 
+func (m *StringMethod) WithRedacted(redacted bool) *StringMethod {
+	m.Redacted = redacted
+	return m
+}
+
 func (m *StringMethod) Generate(receiver, structName string, args []ArgInfo) jen.Code {
 	m.Receiver = receiver
 	m.StructName = structName
 	m.ArgsSep = m.info.Separator
 	m.Args = lo.Filter(args, func(item ArgInfo, index int) bool {
+		if item.Omit {
+			return false
+		}
 		name_ := strings.ToLower(item.Field[:1]) + item.Field[1:]
 		if len(m.info.IncludeFields) > 0 {
 			return lo.Contains(m.info.IncludeFields, name_)
@@ -129,6 +125,9 @@ func (m *StringMethod) generate() (string, error) {
 	return utils.ExecuteTemplate(m,
 		`
 func ({{.Receiver}} *{{.StructName}}) {{.MethodName}}() string {
+{{- if .Redacted}}
+    {{.Receiver}} = {{.Receiver}}.Redacted()
+{{- end}}
     ss := make([]string, 0, {{len .Args}})
     {{- range $idx, $arg := .Args}}
 		{{- if eq $arg.FormatFunc "" -}}  
@@ -52,11 +52,42 @@ func TestParseStringMethod(t *testing.T) {
 				Separator:     " && ",
 			},
 		},
+		{
+			name:    "unknown field",
+			content: `args::string="$key=$value"; sepp=" && "`,
+			wantErr: true,
+		},
+		{
+			name:    "separator with semicolon",
+			content: `args::string="$key=$value"; sep="; "`,
+			want: &StringMethodInfo{
+				ArgsTemplate: "$key=$value",
+				Separator:    "; ",
+			},
+		},
+		{
+			name:    "locale",
+			content: `args::string[en]="$key=$value"; include="Amount"`,
+			want: &StringMethodInfo{
+				ArgsTemplate:  "$key=$value",
+				IncludeFields: []string{"Amount"},
+				Separator:     ", ",
+				Locale:        "en",
+			},
+		},
+		{
+			name:    "invalid locale",
+			content: `args::string[e]="$key=$value"`,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := ParseStringMethod(tt.content)
+			got, err := ParseStringMethod(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStringMethod() error = %v, wantErr %v", err, tt.wantErr)
+			}
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ParseStringMethod() = %v, want %v", got, tt.want)
 			}
@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Field 参数结构体的一个字段, Type 中的包使用完整导入路径, 避免 import 别名变化影响 hash
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Version 参数结构体的一个历史版本
+type Version struct {
+	Hash   string  `json:"hash"`
+	Fields []Field `json:"fields"`
+}
+
+// NewVersion 根据字段计算版本, hash 与字段顺序无关
+func NewVersion(fields []Field) Version {
+	fields = slices.Clone(fields)
+	slices.SortFunc(fields, func(a, b Field) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	h := sha256.New()
+	for _, f := range fields {
+		fmt.Fprintf(h, "%s %s;", f.Name, f.Type)
+	}
+	return Version{
+		Hash:   "v1:" + hex.EncodeToString(h.Sum(nil))[:12],
+		Fields: fields,
+	}
+}
+
+// Diff 一个旧版本与当前版本的不兼容之处
+type Diff struct {
+	From     Version
+	Problems []string
+}
+
+// Lock 记录每个方法参数结构体的所有历史版本, 保存在 -schema 指定的文件中并提交到仓库;
+// 一个 lock 文件可以被多个包共享, key 由 Key 生成
+type Lock struct {
+	Methods map[string][]Version `json:"methods"`
+}
+
+// Key 方法在 Lock 中的 key, 包含包的导入路径, 不同包中同名的 Struct_Method 互不影响
+func Key(pkgPath, method string) string {
+	return pkgPath + "." + method
+}
+
+// Load 读取 lock 文件, 文件不存在时返回空的 Lock
+func Load(path string) (*Lock, error) {
+	lock := &Lock{Methods: make(map[string][]Version)}
+	bs, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bs, lock); err != nil {
+		return nil, fmt.Errorf("invalid schema file %s: %w", path, err)
+	}
+	if lock.Methods == nil {
+		lock.Methods = make(map[string][]Version)
+	}
+	return lock, nil
+}
+
+// Save 写入 lock 文件, 输出稳定以便 code review
+func (l *Lock) Save(path string) error {
+	bs, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(bs, '\n'), 0o644)
+}
+
+// History 方法的历史版本
+func (l *Lock) History(method string) []Version {
+	return l.Methods[method]
+}
+
+// Record 记录方法的当前版本, 返回与当前版本不兼容的旧版本;
+// 新增字段是兼容的(旧数据解码为零值), 删除字段或修改类型是不兼容的
+func (l *Lock) Record(method string, current Version) []Diff {
+	history := l.Methods[method]
+	var diffs []Diff
+	for _, old := range history {
+		if old.Hash == current.Hash {
+			continue
+		}
+		if problems := compare(old, current); len(problems) > 0 {
+			diffs = append(diffs, Diff{From: old, Problems: problems})
+		}
+	}
+	if !slices.ContainsFunc(history, func(v Version) bool { return v.Hash == current.Hash }) {
+		l.Methods[method] = append(history, current)
+	}
+	return diffs
+}
+
+func compare(old, current Version) []string {
+	var problems []string
+	for _, f := range old.Fields {
+		idx := slices.IndexFunc(current.Fields, func(c Field) bool { return c.Name == f.Name })
+		if idx < 0 {
+			problems = append(problems, fmt.Sprintf("field %s removed", f.Name))
+		} else if c := current.Fields[idx]; c.Type != f.Type {
+			problems = append(problems, fmt.Sprintf("field %s changed from %s to %s", f.Name, f.Type, c.Type))
+		}
+	}
+	return problems
+}
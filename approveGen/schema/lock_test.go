@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVersion(t *testing.T) {
+	a := NewVersion([]Field{{Name: "To", Type: "string"}, {Name: "Amount", Type: "int64"}})
+	b := NewVersion([]Field{{Name: "Amount", Type: "int64"}, {Name: "To", Type: "string"}})
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a.Hash, NewVersion([]Field{{Name: "Amount", Type: "int"}, {Name: "To", Type: "string"}}).Hash)
+}
+
+func TestLockRecord(t *testing.T) {
+	lock := &Lock{Methods: make(map[string][]Version)}
+	v1 := NewVersion([]Field{{Name: "To", Type: "string"}, {Name: "Amount", Type: "int"}})
+	assert.Empty(t, lock.Record("Pay", v1))
+	assert.Empty(t, lock.Record("Pay", v1))
+	assert.Len(t, lock.History("Pay"), 1)
+
+	// 新增字段是兼容的
+	v2 := NewVersion([]Field{{Name: "To", Type: "string"}, {Name: "Amount", Type: "int"}, {Name: "Memo", Type: "string"}})
+	assert.Empty(t, lock.Record("Pay", v2))
+
+	// 修改类型和删除字段与所有旧版本都不兼容
+	v3 := NewVersion([]Field{{Name: "Amount", Type: "int64"}, {Name: "Memo", Type: "string"}})
+	diffs := lock.Record("Pay", v3)
+	require.Len(t, diffs, 2)
+	assert.Equal(t, v1.Hash, diffs[0].From.Hash)
+	assert.Equal(t, []string{"field Amount changed from int to int64", "field To removed"}, diffs[0].Problems)
+	assert.Len(t, lock.History("Pay"), 3)
+}
+
+func TestLockLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.lock.json")
+	lock, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, lock.Methods)
+
+	lock.Record("Pay", NewVersion([]Field{{Name: "To", Type: "string"}}))
+	require.NoError(t, lock.Save(path))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, lock, loaded)
+}
@@ -0,0 +1,42 @@
+package capture
+
+import (
+	"context"
+	"time"
+)
+
+type tenantKey struct{}
+type userKey struct{}
+type deadlineKey struct{}
+
+type User struct {
+	ID   int64
+	Name string
+}
+
+func TenantFromCtx(ctx context.Context) string {
+	v, _ := ctx.Value(tenantKey{}).(string)
+	return v
+}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func UserFromCtx(ctx context.Context) (User, bool) {
+	v, ok := ctx.Value(userKey{}).(User)
+	return v, ok
+}
+
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+func DeadlineFromCtx(ctx context.Context) time.Time {
+	v, _ := ctx.Value(deadlineKey{}).(time.Time)
+	return v
+}
+
+func WithDeadline(ctx context.Context, deadline time.Time) context.Context {
+	return context.WithValue(ctx, deadlineKey{}, deadline)
+}
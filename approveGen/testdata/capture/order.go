@@ -0,0 +1,19 @@
+package capture
+
+import "context"
+
+type Order struct{}
+
+// Pay 支付订单
+// @Approve(args::note="支付订单")
+// @Approve(ctx::capture="tenant=TenantFromCtx, user=UserFromCtx, deadline=DeadlineFromCtx")
+// @Approve(func::hookRejected)
+func (o *Order) Pay(ctx context.Context, orderID int64) error {
+	return nil
+}
+
+// Cancel 取消订单
+// @Approve(args::note="取消订单")
+func (o *Order) Cancel(ctx context.Context, orderID int64) error {
+	return nil
+}
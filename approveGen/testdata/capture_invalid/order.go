@@ -0,0 +1,14 @@
+package capture
+
+import "context"
+
+type Order struct{}
+
+// @Approve(ctx::capture="tenant=TenantFromCtx, user=UserFromCtx")
+func (o *Order) Pay(ctx context.Context, orderID int64) error {
+	return nil
+}
+
+func TenantFromCtx(ctx context.Context) (string, error) {
+	return "", nil
+}
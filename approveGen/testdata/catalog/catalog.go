@@ -0,0 +1,31 @@
+package catalog
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Status int
+
+type Account struct{}
+
+// Open 开户
+// @Approve(args::note="开户")
+// @Approve(args::field="name"; alias="户名")
+// @Approve(args::field="limit"; alias="额度")
+func (a *Account) Open(ctx context.Context, name string, limit decimal.Decimal, tags []string, expireAt *time.Time, status Status, extra map[string]any, avatar []byte) error {
+	return nil
+}
+
+// Close 销户
+func (a *Account) Close(ctx context.Context, id int64) error {
+	return nil
+}
+
+// Freeze 冻结
+// @Approve(args::note="冻结账户")
+func Freeze(ctx context.Context, ids []int64, until *time.Time) error {
+	return nil
+}
@@ -0,0 +1,15 @@
+package codec
+
+import (
+	"context"
+	"time"
+)
+
+type Report struct{}
+
+// Upload 上传报表
+// @Approve(args::note="上传报表")
+// @Approve(args::json; generate)
+func (r *Report) Upload(ctx context.Context, name string, rows [][]string, at time.Time) error {
+	return nil
+}
@@ -0,0 +1,24 @@
+package decision
+
+import (
+	"context"
+
+	"github.com/donutnomad/gotoolkit/lib/approvegen"
+)
+
+type Payment struct{}
+
+// RefundHookApproved 退款
+// @Approve(args::note="退款")
+// @Approve(args::string="$key=$value")
+// @Approve(args::formatter)
+// @Approve(func::hookRejected)
+func (p *Payment) RefundHookApproved(ctx context.Context, orderID int64, d approvegen.Decision, amount int64) error {
+	return nil
+}
+
+// Close 关闭订单
+// @Approve(args::note="关闭订单")
+func (p *Payment) Close(ctx context.Context, orderID int64) error {
+	return nil
+}
@@ -0,0 +1,18 @@
+package exec
+
+import "context"
+
+type Mailer struct{}
+
+// Send 发送邮件, 外部服务不支持幂等, 失败后不重试
+// @Approve(args::note="发送邮件")
+// @Approve(exec::noRetry)
+func (m *Mailer) Send(ctx context.Context, to string, subject string) error {
+	return nil
+}
+
+// Archive 归档邮件
+// @Approve(args::note="归档邮件")
+func (m *Mailer) Archive(ctx context.Context, id int64) error {
+	return nil
+}
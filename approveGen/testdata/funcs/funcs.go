@@ -0,0 +1,28 @@
+package funcs
+
+import "context"
+
+// Transfer 转账
+// @Approve(args::note="转账")
+// @Approve(func::hookRejected)
+func Transfer(ctx context.Context, to string, amount int64) error {
+	return nil
+}
+
+func TransferHookRejected(ctx context.Context, to string, amount int64) error {
+	return nil
+}
+
+// resetCache 清空缓存
+// @Approve(args::note="清空缓存")
+func resetCache(ctx context.Context, keys []string) (int, error) {
+	return 0, nil
+}
+
+type Notifier interface {
+	// Broadcast 群发通知
+	// @Approve(args::note="群发通知")
+	Broadcast(ctx context.Context, title, body string) error
+
+	Send(ctx context.Context, to, body string) error
+}
@@ -0,0 +1,10 @@
+package funcs_invalid
+
+import "context"
+
+// Transfer 转账
+// @Approve(args::note="转账")
+// @Approve(func:name="ApproveFor")
+func Transfer(ctx context.Context, to string) error {
+	return nil
+}
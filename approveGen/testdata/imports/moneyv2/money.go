@@ -0,0 +1,7 @@
+// Package money 包名与目录名 moneyv2 不同
+package money
+
+type Amount struct {
+	Currency string
+	Value    int64
+}
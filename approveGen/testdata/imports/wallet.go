@@ -0,0 +1,16 @@
+package imports
+
+import (
+	"context"
+
+	"github.com/donutnomad/gotoolkit/approveGen/testdata/imports/moneyv2"
+	approval "github.com/donutnomad/gotoolkit/lib/approvegen"
+)
+
+type Wallet struct{}
+
+// Withdraw 提现, money 由 go/types 解析为 moneyv2 的包名
+// @Approve(args::note="提现")
+func (w *Wallet) Withdraw(ctx context.Context, amount money.Amount, d approval.Decision) error {
+	return nil
+}
@@ -0,0 +1,13 @@
+package imports
+
+import (
+	"context"
+
+	. "github.com/donutnomad/gotoolkit/approveGen/testdata/imports/moneyv2"
+)
+
+// Deposit 充值, 点导入的类型在生成文件中需要包名
+// @Approve(args::note="充值")
+func (w *Wallet) Deposit(ctx context.Context, amount Amount, limits map[string][]Amount) error {
+	return nil
+}
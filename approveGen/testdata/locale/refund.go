@@ -0,0 +1,23 @@
+package locale
+
+import (
+	"context"
+)
+
+type Payment struct{}
+
+// Refund 退款
+// @Approve(args::note="退款")
+// @Approve(args::note[en]="Refund")
+// @Approve(args::note[zh-TW]="退款申請")
+// @Approve(args::string="$key=$value"; include="orderID,amount")
+// @Approve(args::string[en]="$key: $value"; include="orderID"; sep=" / ")
+func (p *Payment) Refund(ctx context.Context, orderID string, amount int64) error {
+	return nil
+}
+
+// Cancel 只有默认语言
+// @Approve(args::note="取消")
+func (p *Payment) Cancel(ctx context.Context, orderID string) error {
+	return nil
+}
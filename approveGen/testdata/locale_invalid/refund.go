@@ -0,0 +1,12 @@
+package locale
+
+import (
+	"context"
+)
+
+type Payment struct{}
+
+// @Approve(args::note[en]="Refund")
+func (p *Payment) Refund(ctx context.Context, orderID string) error {
+	return nil
+}
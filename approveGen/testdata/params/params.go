@@ -0,0 +1,31 @@
+package params
+
+import (
+	"context"
+)
+
+type Repo[T any] struct{}
+
+// Save 保存
+// @Approve(args::note="保存")
+func (r *Repo[T]) Save(ctx context.Context, name string, tags ...string) error { return nil }
+
+type Pair[K comparable, V any] struct{}
+
+// Put 写入
+// @Approve(args::note="写入")
+func (p Pair[K, V]) Put(ctx context.Context, key string) error { return nil }
+
+type Svc struct{}
+
+// Batch 批量
+// @Approve(args::string="$key=$value")
+func (s *Svc) Batch(ctx context.Context, prefix string, ids ...int64) error { return nil }
+
+// Tag 标签
+// @Approve(args::note="标签")
+// @Approve(func::hookRejected)
+// @Approve(args::formatter="FormatTag")
+func Tag(ctx context.Context, id int64, labels ...string) (int, error) { return len(labels), nil }
+
+func TagHookRejected(ctx context.Context, id int64, labels ...string) (int, error) { return 0, nil }
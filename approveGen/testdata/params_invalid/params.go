@@ -0,0 +1,29 @@
+package params
+
+import (
+	"context"
+)
+
+type Repo[T any] struct{}
+
+// Save 保存
+// @Approve(args::note="保存")
+func (r *Repo[T]) Save(ctx context.Context, item T) error { return nil }
+
+type Svc struct{}
+
+// Watch 订阅
+// @Approve(args::note="订阅")
+func (s *Svc) Watch(ctx context.Context, name string, onChange func(string)) error { return nil }
+
+type Callback func()
+
+type Notifier interface{ Notify() }
+
+// Subscribe 命名的函数类型
+// @Approve(args::note="订阅")
+func (s *Svc) Subscribe(ctx context.Context, cb Callback) error { return nil }
+
+// Notify 非空接口
+// @Approve(args::note="通知")
+func (s *Svc) Notify(ctx context.Context, targets []Notifier, payload any) error { return nil }
@@ -0,0 +1,28 @@
+package policy
+
+import "context"
+
+type Payment struct{}
+
+// Transfer 转账
+// @Approve(args::note="转账")
+// @Approve(policy::quorum=2; roles="finance,admin"; distinct=true)
+// @Approve(policy::ttl="24h"; onExpire="reject")
+func (p *Payment) Transfer(ctx context.Context, to string, amount int64) error {
+	return nil
+}
+
+// Refund 退款
+// @Approve(args::note="退款")
+// @Approve(policy::quorum=1)
+// @Approve(policy::ttl="72h"; onExpire="approve")
+func (p *Payment) Refund(ctx context.Context, orderID int64) error {
+	return nil
+}
+
+// Query 查询
+// @Approve(args::note="查询")
+// @Approve(policy::ttl="30m")
+func (p *Payment) Query(ctx context.Context, orderID int64) error {
+	return nil
+}
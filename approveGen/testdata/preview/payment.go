@@ -0,0 +1,58 @@
+package preview
+
+import (
+	"context"
+
+	"github.com/donutnomad/gotoolkit/lib/approvegen"
+)
+
+type Payment struct {
+	balance map[int64]int64
+}
+
+// RefundHookApproved 退款
+// @Approve(args::note="退款")
+// @Approve(func::hookRejected)
+// @Approve(func::preview)
+func (p *Payment) RefundHookApproved(ctx context.Context, orderID int64, d approvegen.Decision, amount int64) error {
+	return nil
+}
+
+// RefundPreview 审批前展示退款前后的余额
+func (p *Payment) RefundPreview(ctx context.Context, orderID int64, d approvegen.Decision, amount int64) (*approvegen.Preview, error) {
+	before := p.balance[orderID]
+	return &approvegen.Preview{
+		Summary: "退款",
+		Changes: []approvegen.Change{{Field: "balance", Label: "余额", Before: before, After: before - amount}},
+	}, nil
+}
+
+// Close 关闭订单
+// @Approve(args::note="关闭订单")
+// @Approve(func::preview="DiffClose")
+func (p *Payment) Close(ctx context.Context, orderID int64, reasons ...string) error {
+	return nil
+}
+
+// DiffClose 审批前展示订单状态的变化
+func (p *Payment) DiffClose(ctx context.Context, orderID int64, reasons ...string) (*approvegen.Preview, error) {
+	return &approvegen.Preview{Changes: []approvegen.Change{{Field: "status", Before: "open", After: "closed"}}}, nil
+}
+
+// Cancel 取消订单, 没有预览
+// @Approve(args::note="取消订单")
+func (p *Payment) Cancel(ctx context.Context, orderID int64) error {
+	return nil
+}
+
+// Transfer 转账
+// @Approve(args::note="转账")
+// @Approve(func::preview)
+func Transfer(ctx context.Context, from, to string, amount int64) error {
+	return nil
+}
+
+// TransferPreview 审批前展示转账金额
+func TransferPreview(ctx context.Context, from, to string, amount int64) (*approvegen.Preview, error) {
+	return &approvegen.Preview{Changes: []approvegen.Change{{Field: from, Before: amount, After: int64(0)}}}, nil
+}
@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"context"
+	"time"
+)
+
+type UserService struct{}
+
+// Create 创建用户
+// @Approve(args::note="创建用户")
+func (s *UserService) Create(ctx context.Context, name string, age int) error {
+	return nil
+}
+
+// Update 更新用户
+// @Approve(args::note="更新用户")
+func (s *UserService) Update(_ context.Context, id int64, expireAt *time.Time) (bool, error) {
+	return false, nil
+}
+
+// Delete 删除用户
+// @Approve(args::note="删除用户")
+func (s UserService) Delete(id int64) (int, string, error) {
+	return 0, "", nil
+}
+
+func (s *UserService) Get(ctx context.Context, id int64) (string, error) {
+	return "", nil
+}
+
+func (s *UserService) Tags(prefix string, tags ...string) {}
+
+func (s *UserService) helper() {}
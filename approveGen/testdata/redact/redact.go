@@ -0,0 +1,18 @@
+package redact
+
+import "context"
+
+type Bank struct{}
+
+// Bind 绑定银行卡
+// @Approve(args::note="绑定银行卡")
+// @Approve(args::string="$key=$value")
+// @Approve(args::json; generate)
+// @Approve(args::formatter)
+// @Approve(args::field="account"; alias="卡号"; redact="mask")
+// @Approve(args::field="apiKey"; redact="hash")
+// @Approve(args::field="password"; redact="omit")
+// @Approve(args::field="memo"; redact="mask")
+func (b *Bank) Bind(ctx context.Context, owner string, account string, apiKey string, password string, memo *string) error {
+	return nil
+}
@@ -0,0 +1,9 @@
+package redact_invalid
+
+import "context"
+
+// @Approve(args::note="转账")
+// @Approve(args::field="amount"; redact="hash")
+func Transfer(ctx context.Context, amount int64) error {
+	return nil
+}
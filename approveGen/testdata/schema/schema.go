@@ -0,0 +1,32 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type Payment struct{}
+
+// Transfer 转账, Amount 从 int 改为了 int64
+// @Approve(args::note="转账")
+// @Approve(args::migrate="migrateTransferV1"; from="v1:0fabcbba7740")
+func (p *Payment) Transfer(ctx context.Context, to string, amount int64) error {
+	return nil
+}
+
+// Refund 退款
+// @Approve(args::note="退款")
+func (p *Payment) Refund(ctx context.Context, orderID int64) error {
+	return nil
+}
+
+func migrateTransferV1(content []byte) (*_PaymentMethodTransfer, error) {
+	var old struct {
+		To     string
+		Amount int
+	}
+	if err := json.Unmarshal(content, &old); err != nil {
+		return nil, err
+	}
+	return &_PaymentMethodTransfer{To: old.To, Amount: int64(old.Amount)}, nil
+}
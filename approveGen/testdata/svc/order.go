@@ -0,0 +1,34 @@
+package svc
+
+import (
+	"context"
+	"time"
+)
+
+type Order struct{}
+
+// PayHookApproved 支付
+// @Approve(args::note="支付订单")
+// @Approve(args::json; generate)
+// @Approve(func::hookRejected)
+// @Approve(args::formatter="FormatPay")
+func (o *Order) PayHookApproved(ctx context.Context, orderID int64, amount float64, at *time.Time) (string, error) {
+	return "", nil
+}
+
+// Close 关闭
+// @Approve(args::note="关闭订单")
+func (o *Order) Close(orderID int64) {}
+
+// Refund 退款
+// @Approve(args::string="$key:$value")
+// @Approve(func:name="ApproveFor")
+func (o *Order) Refund(ctx context.Context, orderID int64, reason string) (int, string, error) {
+	return 0, "", nil
+}
+
+// Count 数量
+// @Approve(args::note="数量")
+func (o *Order) Count(ctx context.Context) int64 { return 0 }
+
+func (o *Order) approve(ctx context.Context, arg any) error { return nil }
@@ -0,0 +1,26 @@
+package svc
+
+import "context"
+
+type Service struct{}
+
+// @Approve(global::template="ApproveFor={{.Receiver}}.approve(ctx, {{.MethodArg}})")
+func approveTemplate() {}
+
+// Create 创建
+// @Approve(func:name="ApproveFor")
+// @Approve(args::string="$key=$value"; sep=", ")
+// @Approve(args::note="创建用户")
+// @Approve(args::field="name"; alias="名字")
+// @Approve(func::hookRejected)
+func (s *Service) Create(ctx context.Context, name string, age int) error {
+	return nil
+}
+
+// Update 更新
+// @Approve(args::note="更新")
+func (s *Service) Update(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (s *Service) approve(ctx context.Context, arg any) error { return nil }
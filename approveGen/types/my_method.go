@@ -3,7 +3,12 @@ package types
 import (
 	"fmt"
 	"go/ast"
+	"go/scanner"
+	"go/token"
 	"strings"
+	"unicode"
+
+	"github.com/donutnomad/gotoolkit/approveGen/annotation"
 
 	utils2 "github.com/donutnomad/gotoolkit/internal/utils"
 	xast2 "github.com/donutnomad/gotoolkit/internal/xast"
@@ -18,6 +23,13 @@ func (s MyMethodSlice) ToMap() map[string]MyMethod {
 	})
 }
 
+// HasStruct 是否包含 pkgPath 包中 structName 的方法
+func (s MyMethodSlice) HasStruct(pkgPath, structName string) bool {
+	return lo.ContainsBy(s, func(item MyMethod) bool {
+		return item.PkgPath == pkgPath && item.StructNameWithoutPtr() == structName
+	})
+}
+
 type MyMethod struct {
 	ObjName    string // (p *Struct) ==> p
 	StructName string // (p *Struct) ==> *Struct
@@ -26,11 +38,17 @@ type MyMethod struct {
 	MethodParams  []*ast.Field
 	MethodResults []*ast.Field
 
-	Func     *ast.FuncDecl
-	Comment  []string
-	StartPos int
-	EndPos   int
-	Recv     *ast.FieldList
+	Func    *ast.FuncDecl
+	Comment []string
+	// CommentPos 与 Comment 一一对应的位置信息
+	CommentPos []token.Position
+	StartPos   int
+	EndPos     int
+	Recv       *ast.FieldList
+	// Interface 方法声明在接口中, StructName 为接口名称
+	Interface bool
+	// TypeParams 泛型接收者的类型参数, (r *Repo[K, V]) ==> [K V]
+	TypeParams []string
 
 	Imports     xast2.ImportInfoSlice
 	PkgPath     string
@@ -46,9 +64,12 @@ func (m *MyMethod) Copy() MyMethod {
 		MethodResults: m.MethodResults,
 		Func:          m.Func,
 		Comment:       m.Comment,
+		CommentPos:    m.CommentPos,
 		StartPos:      m.StartPos,
 		EndPos:        m.EndPos,
 		Recv:          m.Recv,
+		Interface:     m.Interface,
+		TypeParams:    m.TypeParams,
 		Imports:       m.Imports,
 		PkgPath:       m.PkgPath,
 		FilePkgName:   m.FilePkgName,
@@ -63,8 +84,11 @@ func (m *MyMethod) ExtractImportPath() []string {
 	var out []string
 	for _, param := range newSlice {
 		xast2.GetFieldType(param.Type, func(expr *ast.SelectorExpr) string {
-			x := expr.X.(*ast.Ident).Name // mo
-			out = append(out, m.Imports.Find(x).GetPath())
+			if x, ok := expr.X.(*ast.Ident); ok { // mo
+				if info := m.Imports.Find(x.Name); info != nil {
+					out = append(out, info.GetPath())
+				}
+			}
 			return ""
 		})
 	}
@@ -73,11 +97,24 @@ func (m *MyMethod) ExtractImportPath() []string {
 }
 
 func (m *MyMethod) GenMethod() string {
+	if !m.IsStructMethod() {
+		return m.MethodName
+	}
 	return fmt.Sprintf("%s_%s", m.StructNameWithoutPtr(), m.MethodName)
 }
 
+// StructNameWithoutPtr 结构体名称, 不包含指针和类型参数, (r *Repo[T]) ==> Repo
 func (m *MyMethod) StructNameWithoutPtr() string {
-	return parseString(m.StructName)
+	name := parseString(m.StructName)
+	if i := strings.IndexByte(name, '['); i > 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// IsGeneric 接收者是否为泛型类型
+func (m *MyMethod) IsGeneric() bool {
+	return len(m.TypeParams) > 0
 }
 
 func (m *MyMethod) AsParams(getType func(typ ast.Expr) string) []Param {
@@ -97,36 +134,60 @@ func (m *MyMethod) IsStructMethod() bool {
 	return m.StructName != ""
 }
 
+// FindAnnoBody 查找 @name(...) 注释的内容, 有语法错误时同时返回其余注释的内容和错误
 func (m *MyMethod) FindAnnoBody(name string) ([]string, error) {
-	var out = make([]string, 0, len(m.Comment))
-	for _, comment := range m.Comment {
-		comment = strings.TrimSpace(strings.TrimPrefix(comment, "//"))
+	sources, err := m.FindAnnotations(name)
+	return lo.Map(sources, func(item annotation.Source, _ int) string {
+		return item.Body
+	}), err
+}
+
+// FindAnnotations 查找 @name(...) 注释, 返回带位置信息的注释内容;
+// 语法错误的注释被跳过并收集到返回的 scanner.ErrorList 中, 不影响后面的注释
+func (m *MyMethod) FindAnnotations(name string) ([]annotation.Source, error) {
+	var out = make([]annotation.Source, 0, len(m.Comment))
+	var errs scanner.ErrorList
+	for i, raw := range m.Comment {
+		var pos token.Position
+		if i < len(m.CommentPos) {
+			pos = m.CommentPos[i]
+		}
+		text := strings.TrimPrefix(raw, "//")
+		comment := strings.TrimSpace(text)
 		if !strings.HasPrefix(comment, "@"+name) {
 			continue
 		}
+		start := len(raw) - len(text) + strings.Index(text, "@"+name) + len("@"+name)
 		comment = comment[len("@"+name):]
 		if len(comment) < 2 {
 			continue
 		}
-		if comment[0] != '(' && comment[len(comment)-1] != ')' {
-			return nil, fmt.Errorf("invalid syntax %s", m.Comment)
+		if comment[0] != '(' || comment[len(comment)-1] != ')' {
+			pos.Column += start
+			pos.Offset += start
+			errs.Add(pos, fmt.Sprintf("invalid syntax, expected @%s(...)", name))
+			continue
 		}
-		comment = strings.TrimSpace(comment[1 : len(comment)-1])
-		if len(comment) == 0 {
+		inner := comment[1 : len(comment)-1]
+		trimmed := strings.TrimLeftFunc(inner, unicode.IsSpace)
+		start += 1 + len(inner) - len(trimmed)
+		body := strings.TrimSpace(inner)
+		if len(body) == 0 {
 			continue
 		}
-		out = append(out, comment)
+		pos.Column += start
+		pos.Offset += start
+		out = append(out, annotation.Source{Body: body, Pos: pos})
 	}
-	return out, nil
+	return out, errs.Err()
 }
 
-// OutStructName 最终生成的结构体的名称
+// OutStructName 最终生成的结构体的名称, 函数为 _FuncXxx
 func (m *MyMethod) OutStructName() string {
-	var structName = m.StructName
-	if strings.HasPrefix(structName, "*") {
-		structName = structName[1:]
+	if !m.IsStructMethod() {
+		return "_Func" + m.MethodName
 	}
-	return fmt.Sprintf("_%sMethod%s", structName, m.MethodName)
+	return fmt.Sprintf("_%sMethod%s", m.StructNameWithoutPtr(), m.MethodName)
 }
 
 func parseString(input string) string {
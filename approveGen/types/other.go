@@ -24,6 +24,19 @@ func (t Type) NoPtr() Type {
 	return t
 }
 
+// IsVariadic 可变参数 ...T
+func (t Type) IsVariadic() bool {
+	return strings.HasPrefix(string(t), "...")
+}
+
+// Storage 参数结构体中字段的类型, 可变参数 ...T 保存为 []T
+func (t Type) Storage() Type {
+	if t.IsVariadic() {
+		return "[]" + t[3:]
+	}
+	return t
+}
+
 func (t Type) Placeholder() string {
 	typ := string(t.NoPtr())
 	if lo.Contains([]string{"int", "int8", "int16", "int32", "int64"}, typ) {
@@ -56,6 +56,15 @@ type FieldMapping2 struct {
 
 	// FieldPosition 在 PO 结构体中的字段位置（用于排序）
 	FieldPosition int
+
+	// ValueExpr ToPO 中赋给 PO 字段的原始表达式，局部变量会被展开为其初始值
+	// 如 "d.Name", "*d.Age", "decimal.NewFromBigInt(d.Amount, 0)"
+	// 反向生成 ToDomain 时据此判断该映射能否被还原
+	ValueExpr string
+
+	// Condition 条件赋值的条件表达式（使用 ToPO 的参数名），为空表示无条件赋值
+	// 如 ToPO 中 if d.Addr != nil { po.City = d.Addr.City }，则为 "d.Addr != nil"
+	Condition string
 }
 
 // MappingGroup 映射组（表示一组相关的映射）
@@ -69,6 +78,10 @@ type MappingGroup struct {
 	// TargetField 目标字段名（对于ManyToOne/Embedded/MethodCall，这是PO中的字段名）
 	TargetField string
 
+	// SourceFieldType 源字段在 Domain 中的类型表达式（对于OneToMany，如 "Location" 或 "*Location"）
+	// 仅能解析同包定义的 Domain，无法解析时为空
+	SourceFieldType string
+
 	// MethodName 方法名（对于MethodCall，这是调用的方法名）
 	MethodName string
 
@@ -98,6 +111,9 @@ type ParseResult2 struct {
 	// 例如："github.com/donutnomad/project/domain"
 	SourceTypeImportPath string
 
+	// ParamName ToPO 函数的参数名（如 "d"）
+	ParamName string
+
 	// TargetType 目标类型（PO）
 	TargetType string
 
@@ -114,6 +130,36 @@ type ParseResult2 struct {
 
 	// AllMappings 所有映射的扁平列表（便于遍历）
 	AllMappings []FieldMapping2
+
+	// Coverage 字段覆盖报告
+	Coverage CoverageReport
+}
+
+// CoverageReport ToPO 的字段覆盖报告，为空表示所有列都被映射、所有 Domain 字段都被读取
+type CoverageReport struct {
+	// UnmappedColumns 没有被任何映射写入的 PO 列，位置指向 PO 中对应的字段
+	UnmappedColumns []CoverageItem
+
+	// UnreadFields ToPO 中从未读取的 Domain 字段（Domain 定义在外部包时无法统计）
+	UnreadFields []CoverageItem
+
+	// SkippedMappings 因表达式无法识别而被跳过的赋值，位置指向赋值表达式
+	SkippedMappings []CoverageItem
+}
+
+// CoverageItem 覆盖报告中的一项
+type CoverageItem struct {
+	// Name 列名、Domain 字段名或 PO 字段路径（如 "Model.ID"）
+	Name string
+
+	// Pos 源码位置，格式为 "file:line"
+	Pos string
+
+	// Expr 被跳过的赋值表达式（仅 SkippedMappings）
+	Expr string
+
+	// Reason 被跳过的原因（仅 SkippedMappings）
+	Reason string
 }
 
 // Parse 解析 ToPO 函数，返回映射关系
@@ -124,3 +170,36 @@ func Parse(filePath string, receiverType string, funcName string) (*ParseResult2
 	mapper := NewMapper(filePath)
 	return mapper.Parse(receiverType, funcName)
 }
+
+// ReverseTODO 反向映射（ToDomain）中无法自动还原、需要手写的映射
+type ReverseTODO struct {
+	// Type 映射类型
+	Type MappingType
+
+	// TargetField PO 字段路径，如 "Address"
+	TargetField string
+
+	// Columns 涉及的数据库列名
+	Columns []string
+
+	// SourcePaths 无法被赋值的 Domain 字段路径
+	SourcePaths []string
+
+	// ValueExpr ToPO 中的原始赋值表达式
+	ValueExpr string
+
+	// Reason 无法还原的原因
+	Reason string
+
+	// Lossy 已生成赋值，但逆转换有损，需要人工确认
+	Lossy bool
+}
+
+// ReverseReport 反向映射生成报告
+type ReverseReport struct {
+	// FuncName 生成的函数名（如 "ToDomain"）
+	FuncName string
+
+	// TODOs 需要手写或确认（有损转换）的映射列表，为空表示所有映射都已准确还原
+	TODOs []ReverseTODO
+}
@@ -1,6 +1,9 @@
 package automap_test
 
 import (
+	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/donutnomad/gotoolkit/automap"
@@ -161,6 +164,99 @@ func TestParseJSONSliceLoMapMethodCall(t *testing.T) {
 	assertParseResult(t, result, expected)
 }
 
+// TestParseImperative 测试逐步构建 PO 的映射
+func TestParseImperative(t *testing.T) {
+	result, err := automap.Parse("testdata/models.go", "ImperativePO", "ToPO")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := testdata.ExpectedImperativeMapping
+	assertParseResult(t, result, expected)
+}
+
+// TestParseCoverage 测试字段覆盖报告
+func TestParseCoverage(t *testing.T) {
+	result, err := automap.Parse("testdata/models.go", "CoveragePO", "ToPO")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	coverage := result.Coverage
+
+	// summary、tags 和 display 的赋值被跳过，remark 未映射
+	expectedColumns := []automap.CoverageItem{
+		{Name: "summary", Pos: fixtureLine(t, "Summary string")},
+		{Name: "tags", Pos: fixtureLine(t, "Tags    string")},
+		{Name: "display", Pos: fixtureLine(t, "Display string")},
+		{Name: "remark", Pos: fixtureLine(t, "Remark  string")},
+	}
+	assertCoverageItems(t, "UnmappedColumns", coverage.UnmappedColumns, expectedColumns)
+
+	// Title 只在被跳过的表达式中读取，同样算作已读取
+	expectedFields := []automap.CoverageItem{
+		{Name: "Internal", Pos: fixtureLine(t, "Internal string")},
+	}
+	assertCoverageItems(t, "UnreadFields", coverage.UnreadFields, expectedFields)
+
+	expectedSkipped := []automap.CoverageItem{
+		{Name: "Summary", Pos: fixtureLine(t, "Summary: d.Name"), Expr: `d.Name + " " + d.Title`, Reason: "无法识别的表达式"},
+		{Name: "Tags", Pos: fixtureLine(t, "po.Tags = "), Expr: `po.Tags + tag + ","`, Reason: "位于循环或 switch 语句中"},
+		{Name: "Display", Pos: fixtureLine(t, "po.Display = d.Title"), Expr: "d.Title", Reason: `条件 po.Summary != "" 无法解析`},
+		{Name: "Display", Pos: fixtureLine(t, "po.Display = d.Name"), Expr: "d.Name", Reason: `条件 po.Summary != "" 无法解析`},
+	}
+	assertCoverageItems(t, "SkippedMappings", coverage.SkippedMappings, expectedSkipped)
+
+	if coverage.IsEmpty() {
+		t.Error("Coverage report should not be empty")
+	}
+	if !strings.Contains(coverage.String(), "unmapped column remark") {
+		t.Errorf("Unexpected report:\n%s", coverage.String())
+	}
+
+	// 完全映射的场景报告为空
+	result, err = automap.Parse("testdata/models.go", "SimpleUserPO", "ToPO")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if !result.Coverage.IsEmpty() {
+		t.Errorf("Expected empty coverage report, got:\n%s", result.Coverage.String())
+	}
+}
+
+// fixtureLine 返回 testdata/models.go 场景28中首个包含 substr 的行，格式为 "file:line"
+func fixtureLine(t *testing.T, substr string) string {
+	t.Helper()
+	data, err := os.ReadFile("testdata/models.go")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	// 只在场景28中查找，避免匹配到其他场景的同名字段
+	inScenario := false
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.Contains(line, "测试场景28") {
+			inScenario = true
+		}
+		if inScenario && strings.Contains(line, substr) {
+			return fmt.Sprintf("testdata/models.go:%d", i+1)
+		}
+	}
+	t.Fatalf("%q not found in fixture", substr)
+	return ""
+}
+
+// assertCoverageItems 验证覆盖报告中的条目
+func assertCoverageItems(t *testing.T, name string, result, expected []automap.CoverageItem) {
+	t.Helper()
+	if len(result) != len(expected) {
+		t.Fatalf("%s count mismatch: got %d, want %d\ngot: %+v", name, len(result), len(expected), result)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("%s[%d] mismatch:\ngot:  %+v\nwant: %+v", name, i, result[i], expected[i])
+		}
+	}
+}
+
 // assertParseResult 验证解析结果
 func assertParseResult(t *testing.T, result *automap.ParseResult2, expected testdata.ParseResult) {
 	t.Helper()
@@ -242,4 +338,7 @@ func assertFieldMapping(t *testing.T, groupIdx, mappingIdx int, result automap.F
 	if result.JSONPath != expected.JSONPath {
 		t.Errorf(prefix()+".JSONPath mismatch: got %s, want %s", groupIdx, mappingIdx, result.JSONPath, expected.JSONPath)
 	}
+	if result.Condition != expected.Condition {
+		t.Errorf(prefix()+".Condition mismatch: got %s, want %s", groupIdx, mappingIdx, result.Condition, expected.Condition)
+	}
 }
@@ -123,6 +123,13 @@ func (m *Mapper) processAssignStmt(s *ast.AssignStmt) {
 		varName := ident.Name
 		rhs := s.Rhs[i]
 
+		// 记录首次赋值的表达式（忽略 x := x 这类自引用）
+		if rhsIdent, ok := rhs.(*ast.Ident); !ok || rhsIdent.Name != varName {
+			if _, exists := m.varExprs[varName]; !exists {
+				m.varExprs[varName] = rhs
+			}
+		}
+
 		// 检查是否是方法调用：d.MethodName()
 		if methodInfo := m.extractMethodCallInfo(rhs); methodInfo != nil {
 			if _, exists := m.methodCallMap[varName]; !exists {
@@ -166,6 +173,9 @@ func (m *Mapper) processValueSpec(spec *ast.ValueSpec) {
 	for i, name := range spec.Names {
 		varName := name.Name
 		value := spec.Values[i]
+		if _, exists := m.varExprs[varName]; !exists {
+			m.varExprs[varName] = value
+		}
 
 		sourcePath := m.extractSourcePathFromExpr(value)
 		if sourcePath != "" {
@@ -206,10 +216,22 @@ func (m *Mapper) collectTargetColumns() {
 	}
 
 	// 从解析结果中提取列名，同时记录字段位置
+	fieldPositions := m.targetFieldPositions()
 	for i, field := range structInfo.Fields {
 		columnName := gormparse.ExtractColumnNameWithPrefix(field.Name, field.Tag, field.EmbeddedPrefix)
 		m.result.TargetColumns = append(m.result.TargetColumns, columnName)
 		m.result.TargetFieldPositions[columnName] = i
+
+		// 记录列的源码位置，来自嵌入结构体的列定位到嵌入字段
+		fieldName := field.Name
+		if field.SourceType != "" {
+			fieldName = field.SourceType
+		}
+		pos, ok := fieldPositions[fieldName]
+		if !ok && m.typeSpecs[m.receiverType] != nil {
+			pos = m.typeSpecs[m.receiverType].Pos()
+		}
+		m.targetColumnPositions[columnName] = m.position(pos)
 		if DebugMode {
 			fmt.Printf("[DEBUG] Column: %s (from field %s, position %d)\n", columnName, field.Name, i)
 		}
@@ -266,6 +288,7 @@ func (m *Mapper) collectTargetColumnsSimple() {
 	// 同时填充位置映射
 	for i, col := range columns {
 		m.result.TargetFieldPositions[col] = i
+		m.targetColumnPositions[col] = m.position(typeSpec.Pos())
 	}
 }
 
@@ -0,0 +1,219 @@
+package automap
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// IsEmpty 报告是否为空
+func (r *CoverageReport) IsEmpty() bool {
+	return len(r.UnmappedColumns) == 0 && len(r.UnreadFields) == 0 && len(r.SkippedMappings) == 0
+}
+
+// String 返回可读的报告文本，为空时返回空字符串
+func (r *CoverageReport) String() string {
+	if r.IsEmpty() {
+		return ""
+	}
+	var builder strings.Builder
+	for _, item := range r.UnmappedColumns {
+		builder.WriteString(fmt.Sprintf("%s: unmapped column %s\n", item.Pos, item.Name))
+	}
+	for _, item := range r.UnreadFields {
+		builder.WriteString(fmt.Sprintf("%s: domain field %s is never read\n", item.Pos, item.Name))
+	}
+	for _, item := range r.SkippedMappings {
+		builder.WriteString(fmt.Sprintf("%s: skipped %s = %s: %s\n", item.Pos, item.Name, item.Expr, item.Reason))
+	}
+	return builder.String()
+}
+
+// skipMapping 记录因表达式无法识别而被跳过的赋值
+// 只记录读取了 ToPO 参数的表达式，常量等与 Domain 无关的赋值不算作跳过
+func (m *Mapper) skipMapping(target string, value ast.Expr, reason string) {
+	if !m.readsParam(value) {
+		return
+	}
+	m.recordSkipped(target, value, reason)
+}
+
+// recordSkipped 将被跳过的赋值写入覆盖报告
+func (m *Mapper) recordSkipped(target string, value ast.Expr, reason string) {
+	if DebugMode {
+		fmt.Printf("[DEBUG] Skipped mapping %s = %s: %s\n", target, types.ExprString(value), reason)
+	}
+	m.result.Coverage.SkippedMappings = append(m.result.Coverage.SkippedMappings, CoverageItem{
+		Name:   target,
+		Pos:    m.position(value.Pos()),
+		Expr:   m.valueExpr(value),
+		Reason: reason,
+	})
+}
+
+// skipResultAssignments 记录语句中所有对结果变量字段的赋值为跳过
+// 用于 for/switch 等无法确定执行次数或分支条件的语句，赋值的值常引用循环变量，因此不检查是否读取了参数
+func (m *Mapper) skipResultAssignments(stmt ast.Stmt, resultVar, reason string) {
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			if path := m.resultFieldPath(lhs, resultVar); len(path) > 0 {
+				m.recordSkipped(strings.Join(path, "."), assign.Rhs[i], reason)
+			}
+		}
+		return true
+	})
+}
+
+// readsParam 判断表达式是否读取了 ToPO 参数（局部变量会被展开）
+func (m *Mapper) readsParam(expr ast.Expr) bool {
+	visited := make(map[string]bool)
+	var reads func(ast.Expr) bool
+	reads = func(expr ast.Expr) bool {
+		found := false
+		ast.Inspect(expr, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok || found {
+				return !found
+			}
+			if ident.Name == m.paramName {
+				found = true
+			} else if init, exists := m.varExprs[ident.Name]; exists && !visited[ident.Name] {
+				visited[ident.Name] = true
+				found = reads(init)
+			}
+			return !found
+		})
+		return found
+	}
+	return reads(expr)
+}
+
+// position 返回 "file:line" 格式的源码位置
+func (m *Mapper) position(pos token.Pos) string {
+	if !pos.IsValid() {
+		return ""
+	}
+	p := m.fset.Position(pos)
+	return fmt.Sprintf("%s:%d", p.Filename, p.Line)
+}
+
+// collectCoverage 计算未映射的 PO 列和未读取的 Domain 字段
+func (m *Mapper) collectCoverage() {
+	coverage := &m.result.Coverage
+
+	mappedColumns := make(map[string]bool)
+	for _, mapping := range m.result.AllMappings {
+		mappedColumns[mapping.ColumnName] = true
+	}
+	for _, column := range m.result.TargetColumns {
+		if !mappedColumns[column] {
+			coverage.UnmappedColumns = append(coverage.UnmappedColumns, CoverageItem{
+				Name: column,
+				Pos:  m.targetColumnPositions[column],
+			})
+		}
+	}
+
+	// Domain 类型定义在外部包时无法得知其字段列表
+	if m.result.SourceTypePackage != "" {
+		return
+	}
+	structType, ok := m.getStructType(m.typeSpecs[m.sourceType])
+	if !ok {
+		return
+	}
+
+	readFields := m.readDomainFields()
+	for _, field := range m.domainFields(structType, nil) {
+		if readFields[field.name] || slices.ContainsFunc(field.owners, func(owner string) bool { return readFields[owner] }) {
+			continue
+		}
+		coverage.UnreadFields = append(coverage.UnreadFields, CoverageItem{
+			Name: field.name,
+			Pos:  m.position(field.pos),
+		})
+	}
+}
+
+// readDomainFields 收集 ToPO 读取过的 Domain 顶层字段
+// 包括映射的源字段（含方法体中使用的字段）以及函数体中直接出现的 d.Xxx（如条件、被跳过的表达式）
+func (m *Mapper) readDomainFields() map[string]bool {
+	readFields := make(map[string]bool)
+	for _, mapping := range m.result.AllMappings {
+		field, _, _ := strings.Cut(mapping.SourcePath, ".")
+		readFields[field] = true
+	}
+	if m.funcBody != nil {
+		ast.Inspect(m.funcBody, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == m.paramName {
+					readFields[sel.Sel.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	return readFields
+}
+
+// domainField Domain 结构体中的字段
+type domainField struct {
+	name   string
+	pos    token.Pos
+	owners []string // 所在的匿名嵌入字段（由外到内），读取嵌入字段本身即视为读取了其所有字段
+}
+
+// domainFields 展开 Domain 结构体的字段，同包中定义的匿名嵌入结构体会被递归展开
+func (m *Mapper) domainFields(structType *ast.StructType, owners []string) []domainField {
+	var fields []domainField
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			embeddedName := extractTypeName(field.Type)
+			embeddedStructType, ok := m.getStructType(m.typeSpecs[embeddedName])
+			if ok && !slices.Contains(owners, embeddedName) {
+				fields = append(fields, m.domainFields(embeddedStructType, append(slices.Clone(owners), embeddedName))...)
+			} else {
+				fields = append(fields, domainField{name: embeddedName, pos: field.Pos(), owners: owners})
+			}
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				continue
+			}
+			fields = append(fields, domainField{name: name.Name, pos: name.Pos(), owners: owners})
+		}
+	}
+	return fields
+}
+
+// targetFieldPositions 返回 PO 结构体中字段的位置：字段名（匿名嵌入为类型名）-> 位置
+// 用于为列定位源码，来自嵌入结构体的列定位到 PO 中的嵌入字段
+func (m *Mapper) targetFieldPositions() map[string]token.Pos {
+	positions := make(map[string]token.Pos)
+	structType, ok := m.getStructType(m.typeSpecs[m.receiverType])
+	if !ok {
+		return positions
+	}
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			fieldType := field.Type
+			if star, ok := fieldType.(*ast.StarExpr); ok {
+				fieldType = star.X
+			}
+			positions[types.ExprString(fieldType)] = field.Pos()
+			continue
+		}
+		for _, name := range field.Names {
+			positions[name.Name] = name.Pos()
+		}
+	}
+	return positions
+}
@@ -0,0 +1,187 @@
+package automap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// libPkgPath 生成的 DiffPatch 使用的运行时库, 提供 Equal
+const libPkgPath = "github.com/donutnomad/gotoolkit/lib/automap"
+
+// DiffGenerator 根据 ToPO 的映射分析结果生成基于快照比较的 Patch 函数
+// 生成的函数对新旧两个 Domain 分别调用 ToPO，逐列比较映射到的 PO 字段，
+// 不依赖 setterGen 生成的 ExportPatch 变更追踪，适用于普通的 Domain 结构体
+type DiffGenerator struct {
+	result      *ParseResult2
+	genFuncName string
+	imports     map[string]bool
+
+	receiverVar string
+
+	// 已生成比较代码的列，同一列被多次赋值（如 if/else 分支）时只比较一次
+	columns map[string]bool
+}
+
+// NewDiffGenerator 创建快照比较代码生成器
+func NewDiffGenerator(result *ParseResult2, genFuncName string) *DiffGenerator {
+	receiverVar := "p"
+	if len(result.ReceiverType) > 0 {
+		receiverVar = strings.ToLower(result.ReceiverType[:1])
+	}
+
+	g := &DiffGenerator{
+		result:      result,
+		genFuncName: genFuncName,
+		imports:     make(map[string]bool),
+		receiverVar: receiverVar,
+		columns:     make(map[string]bool),
+	}
+
+	// 如果源类型是外部包，添加导入
+	if result.SourceTypeImportPath != "" {
+		g.imports[result.SourceTypeImportPath] = true
+	}
+
+	return g
+}
+
+// Generate 生成代码
+// 返回: (带imports的完整代码, 纯函数代码)
+func (g *DiffGenerator) Generate() (string, string) {
+	sourceTypeName := g.result.SourceType
+	if g.result.SourceTypePackage != "" {
+		sourceTypeName = g.result.SourceTypePackage + "." + g.result.SourceType
+	}
+
+	var funcBuilder strings.Builder
+	funcBuilder.WriteString(fmt.Sprintf("func (%s *%s) %s(oldInput, newInput *%s) map[string]any {\n",
+		g.receiverVar, g.result.ReceiverType, g.genFuncName, sourceTypeName))
+	g.generateFunctionBody(&funcBuilder)
+	funcBuilder.WriteString("\treturn values\n")
+	funcBuilder.WriteString("}\n")
+	funcCode := funcBuilder.String()
+
+	var fullBuilder strings.Builder
+	writeImports(&fullBuilder, g.imports)
+	fullBuilder.WriteString(funcCode)
+
+	return fullBuilder.String(), funcCode
+}
+
+// generateFunctionBody 生成函数体
+func (g *DiffGenerator) generateFunctionBody(builder *strings.Builder) {
+	if len(g.result.Groups) == 0 {
+		builder.WriteString("\tvar values map[string]any\n")
+		return
+	}
+
+	// 分别转换新旧快照，任一为 nil 时无法比较
+	builder.WriteString(fmt.Sprintf("\toldPO, newPO := %s.%s(oldInput), %s.%s(newInput)\n",
+		g.receiverVar, g.result.FuncName, g.receiverVar, g.result.FuncName))
+	builder.WriteString("\tif oldPO == nil || newPO == nil {\n\t\treturn nil\n\t}\n")
+	builder.WriteString(fmt.Sprintf("\tvalues := make(map[string]any, %d)\n", len(g.result.AllMappings)))
+
+	for _, item := range sortedGenerationItems(g.result.Groups) {
+		if !item.isGroup {
+			g.writeColumnDiff(builder, item.mapping.ColumnName, item.mapping.TargetPath)
+			continue
+		}
+		switch item.group.Type {
+		case Embedded:
+			builder.WriteString(fmt.Sprintf("\t// Embedded: %s\n", item.group.TargetField))
+			g.writeMappingDiffs(builder, item.group.Mappings)
+		case ManyToOne:
+			g.generateManyToOne(builder, item.group)
+		case OneToMany:
+			builder.WriteString(fmt.Sprintf("\t// OneToMany: %s\n", item.group.SourceField))
+			g.writeMappingDiffs(builder, item.group.Mappings)
+		case MethodCall:
+			builder.WriteString(fmt.Sprintf("\t// MethodCall: %s() -> %s\n", item.group.MethodName, item.group.TargetField))
+			if len(item.group.Mappings) > 0 {
+				g.writeColumnDiff(builder, item.group.Mappings[0].ColumnName, item.group.TargetField)
+			}
+		case EmbeddedOneToMany:
+			builder.WriteString(fmt.Sprintf("\t// EmbeddedOneToMany: %s -> %s\n", item.group.SourceField, item.group.TargetField))
+			g.writeMappingDiffs(builder, item.group.Mappings)
+		}
+	}
+}
+
+// writeMappingDiffs 逐个映射比较对应的 PO 字段
+func (g *DiffGenerator) writeMappingDiffs(builder *strings.Builder, mappings []FieldMapping2) {
+	for _, mapping := range mappings {
+		g.writeColumnDiff(builder, mapping.ColumnName, mapping.TargetPath)
+	}
+}
+
+// writeColumnDiff 比较一列：values["name"] = newPO.Name
+// automap.Equal 优先使用类型的 Equal 方法，time.Time 和 decimal.Decimal 的等值不会被当作变化
+func (g *DiffGenerator) writeColumnDiff(builder *strings.Builder, columnName, targetPath string) {
+	if g.columns[columnName] {
+		return
+	}
+	g.columns[columnName] = true
+	g.imports[libPkgPath] = true
+
+	builder.WriteString(fmt.Sprintf("\tif !automap.Equal(oldPO.%s, newPO.%s) {\n", targetPath, targetPath))
+	builder.WriteString(fmt.Sprintf("\t\tvalues[\"%s\"] = newPO.%s\n", columnName, targetPath))
+	builder.WriteString("\t}\n")
+}
+
+// generateManyToOne 按 JSON key 比较，只更新变化的 key
+func (g *DiffGenerator) generateManyToOne(builder *strings.Builder, group MappingGroup) {
+	if len(group.Mappings) == 0 {
+		return
+	}
+	columnName := group.Mappings[0].ColumnName
+	if g.columns[columnName] {
+		return
+	}
+	g.columns[columnName] = true
+	g.imports["github.com/donutnomad/gsql"] = true
+	g.imports[libPkgPath] = true
+
+	// 同一 JSON key 可能被多次赋值，按 JSONPath 去重并排序保证输出稳定
+	keys := make(map[string]FieldMapping2)
+	for _, mapping := range group.Mappings {
+		if _, exists := keys[mapping.JSONPath]; !exists {
+			keys[mapping.JSONPath] = mapping
+		}
+	}
+	jsonPaths := make([]string, 0, len(keys))
+	for jsonPath := range keys {
+		jsonPaths = append(jsonPaths, jsonPath)
+	}
+	sort.Strings(jsonPaths)
+
+	builder.WriteString(fmt.Sprintf("\t// B.%s\n", group.TargetField))
+	builder.WriteString("\t{\n")
+	builder.WriteString(fmt.Sprintf("\t\tset := gsql.JSONSet(\"%s\")\n", columnName))
+	builder.WriteString(fmt.Sprintf("\t\toldField, newField := oldPO.%s.Data(), newPO.%s.Data()\n", group.TargetField, group.TargetField))
+	for _, jsonPath := range jsonPaths {
+		fieldPath := keys[jsonPath].GoFieldPath
+		builder.WriteString(fmt.Sprintf("\t\tif !automap.Equal(oldField.%s, newField.%s) {\n", fieldPath, fieldPath))
+		builder.WriteString(fmt.Sprintf("\t\t\tset.Set(\"%s\", newField.%s)\n", jsonPath, fieldPath))
+		builder.WriteString("\t\t}\n")
+	}
+	builder.WriteString("\t\tif set.Len() > 0 {\n")
+	builder.WriteString(fmt.Sprintf("\t\t\tvalues[\"%s\"] = set\n", columnName))
+	builder.WriteString("\t\t}\n")
+	builder.WriteString("\t}\n")
+}
+
+// GenerateDiff 生成基于快照比较的 Patch 函数
+// filePath: 源文件路径
+// receiverType: 接收者类型名（如 "ListingPO"）
+// funcName: 原函数名（如 "ToPO"）
+// genFuncName: 生成的函数名（如 "DiffPatch"）
+func GenerateDiff(filePath, receiverType, funcName, genFuncName string) (string, string, error) {
+	result, err := Parse(filePath, receiverType, funcName)
+	if err != nil {
+		return "", "", fmt.Errorf("解析失败: %w", err)
+	}
+
+	fullCode, funcCode := NewDiffGenerator(result, genFuncName).Generate()
+	return fullCode, funcCode, nil
+}
@@ -0,0 +1,193 @@
+package automap_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/donutnomad/gotoolkit/automap"
+)
+
+// TestGenerateDiffImperative 测试快照比较：条件赋值、嵌入字段和 JSON 列
+func TestGenerateDiffImperative(t *testing.T) {
+	fullCode, funcCode, err := automap.GenerateDiff("testdata/models.go", "ImperativePO", "ToPO", "DiffPatch")
+	if err != nil {
+		t.Fatalf("GenerateDiff failed: %v", err)
+	}
+
+	expected := []string{
+		"func (i *ImperativePO) DiffPatch(oldInput, newInput *ImperativeDomain) map[string]any {",
+		"oldPO, newPO := i.ToPO(oldInput), i.ToPO(newInput)",
+		"if oldPO == nil || newPO == nil {",
+		"if !automap.Equal(oldPO.Model.ID, newPO.Model.ID) {\n\t\tvalues[\"id\"] = newPO.Model.ID",
+		"if !automap.Equal(oldPO.City, newPO.City) {\n\t\tvalues[\"city\"] = newPO.City",
+		`set := gsql.JSONSet("contact")`,
+		"oldField, newField := oldPO.Contact.Data(), newPO.Contact.Data()",
+		"if !automap.Equal(oldField.Phone, newField.Phone) {\n\t\t\tset.Set(\"phone\", newField.Phone)",
+		"return values",
+	}
+	for _, want := range expected {
+		if !strings.Contains(funcCode, want) {
+			t.Errorf("Missing expected code: %s\ngot:\n%s", want, funcCode)
+		}
+	}
+
+	// 不依赖 setterGen 的变更追踪
+	if strings.Contains(funcCode, "ExportPatch") || strings.Contains(funcCode, "IsPresent") {
+		t.Errorf("DiffPatch should not use change tracking, got:\n%s", funcCode)
+	}
+	// nickname 在 if/else 两个分支中赋值，只比较一次
+	if n := strings.Count(funcCode, `values["nickname"]`); n != 1 {
+		t.Errorf("Expected nickname to be compared once, got %d:\n%s", n, funcCode)
+	}
+	for _, imp := range []string{`"github.com/donutnomad/gotoolkit/lib/automap"`, `"github.com/donutnomad/gsql"`} {
+		if !strings.Contains(fullCode, imp) {
+			t.Errorf("Missing import %s, got:\n%s", imp, fullCode)
+		}
+	}
+}
+
+// TestGenerateDiffNestedJSON 测试嵌套 JSON 按 key 比较
+func TestGenerateDiffNestedJSON(t *testing.T) {
+	_, funcCode, err := automap.GenerateDiff("testdata/models.go", "ArticlePO", "ToPO", "DiffPatch")
+	if err != nil {
+		t.Fatalf("GenerateDiff failed: %v", err)
+	}
+
+	expected := []string{
+		"if !automap.Equal(oldField.Author.Name, newField.Author.Name) {",
+		`set.Set("author.name", newField.Author.Name)`,
+		`values["metadata"] = set`,
+		"if !automap.Equal(oldPO.ViewCount, newPO.ViewCount) {",
+	}
+	for _, want := range expected {
+		if !strings.Contains(funcCode, want) {
+			t.Errorf("Missing expected code: %s\ngot:\n%s", want, funcCode)
+		}
+	}
+}
+
+// TestGenerateDiffMethodCall 测试方法调用映射按目标列整体比较
+func TestGenerateDiffMethodCall(t *testing.T) {
+	_, funcCode, err := automap.GenerateDiff("testdata/models.go", "CustomerPO", "ToPO", "DiffPatch")
+	if err != nil {
+		t.Fatalf("GenerateDiff failed: %v", err)
+	}
+
+	want := "if !automap.Equal(oldPO.Address, newPO.Address) {\n\t\tvalues[\"address\"] = newPO.Address"
+	if !strings.Contains(funcCode, want) {
+		t.Errorf("Missing expected code: %s\ngot:\n%s", want, funcCode)
+	}
+	if n := strings.Count(funcCode, `values["address"]`); n != 1 {
+		t.Errorf("Expected address to be compared once, got %d:\n%s", n, funcCode)
+	}
+}
+
+// TestGenerateDiffEmpty 测试没有映射时的代码生成
+func TestGenerateDiffEmpty(t *testing.T) {
+	fullCode, funcCode, err := automap.GenerateDiff("testdata/models.go", "ExternalNoPrefixPO", "ToPO2", "DiffPatch")
+	if err != nil {
+		t.Fatalf("GenerateDiff failed: %v", err)
+	}
+	if !strings.Contains(funcCode, "\tvar values map[string]any\n\treturn values") {
+		t.Errorf("Missing return statement, got:\n%s", funcCode)
+	}
+	if strings.Contains(fullCode, `"github.com/donutnomad/gotoolkit/lib/automap"`) {
+		t.Errorf("Unexpected lib/automap import, got:\n%s", fullCode)
+	}
+}
+
+// diffRunTest 与生成的 DiffPatch 一起编译运行，验证比较的结果而不只是生成的代码
+const diffRunTest = `package testdata
+
+import (
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func keys(values map[string]any) []string {
+	ret := make([]string, 0, len(values))
+	for k := range values {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func TestDiffPatch(t *testing.T) {
+	cst := time.FixedZone("CST", 8*3600)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end, endCST := start.Add(time.Hour), start.Add(time.Hour).In(cst)
+	old := &PricingDomain{ID: 1, Price: decimal.RequireFromString("1.0"), StartAt: start, EndAt: &end, Note: "a"}
+	cur := &PricingDomain{ID: 1, Price: decimal.RequireFromString("1.00"), StartAt: start.In(cst), EndAt: &endCST, Note: "a"}
+	if got := keys(new(PricingPO).DiffPatch(old, cur)); len(got) != 0 {
+		t.Errorf("equal values reported as changed: %v", got)
+	}
+	cur.Price, cur.EndAt, cur.Note = decimal.RequireFromString("2"), nil, "b"
+	if got := keys(new(PricingPO).DiffPatch(old, cur)); !slices.Equal(got, []string{"end_at", "note", "price"}) {
+		t.Errorf("unexpected changes: %v", got)
+	}
+
+	// 嵌入结构体中的 time.Time
+	oldAudit := &AuditDomain{ID: 1, Title: "a", CreatedAt: start, UpdatedAt: start}
+	curAudit := &AuditDomain{ID: 1, Title: "a", CreatedAt: start.In(cst), UpdatedAt: end}
+	if got := keys(new(AuditPO).DiffPatch(oldAudit, curAudit)); !slices.Equal(got, []string{"audit_updated_at"}) {
+		t.Errorf("unexpected changes: %v", got)
+	}
+	if got := new(AuditPO).DiffPatch(oldAudit, nil); got != nil {
+		t.Errorf("expected nil for nil snapshot, got %v", got)
+	}
+}
+`
+
+// TestDiffPatchRun 将生成的 DiffPatch 与 testdata 的模型一起编译并运行
+func TestDiffPatchRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("go test is slow")
+	}
+	// 以 _ 开头的目录不会被 ./... 匹配, 需要放在模块内才能解析 import
+	root, err := os.MkdirTemp("testdata", "_diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(root) })
+
+	files, err := filepath.Glob("testdata/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		bs, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, filepath.Base(file)), bs, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// ManyToOne 的 JSON 列依赖 gsql, 它不是本模块的依赖, 这里只编译不含 JSON 列的模型
+	for _, receiverType := range []string{"PricingPO", "AuditPO"} {
+		fullCode, _, err := automap.GenerateDiff("testdata/models.go", receiverType, "ToPO", "DiffPatch")
+		if err != nil {
+			t.Fatalf("GenerateDiff %s failed: %v", receiverType, err)
+		}
+		name := filepath.Join(root, strings.ToLower(receiverType)+"_diff.go")
+		if err := os.WriteFile(name, []byte("package testdata\n\n"+fullCode), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "diff_run_test.go"), []byte(diffRunTest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("go", "test", "-run", "TestDiffPatch", "./"+filepath.ToSlash(root)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated DiffPatch failed: %v\n%s", err, out)
+	}
+}
@@ -32,25 +32,9 @@ func (m *Mapper) analyzeEmbeddedCompositeLit(fieldName string, compLit *ast.Comp
 			continue
 		}
 
-		sourcePath, convertExpr := m.extractSourcePath(kv.Value)
-		if sourcePath == "" {
-			continue
-		}
-
-		// 获取子字段的列名
-		subFieldInfo := m.getFieldInfo(embeddedStructType, subFieldName)
-		columnName := subFieldInfo.ColumnName
-		if fieldInfo.EmbeddedPrefix != "" {
-			columnName = fieldInfo.EmbeddedPrefix + columnName
-		}
-
-		mapping := FieldMapping2{
-			SourcePath:  sourcePath,
-			TargetPath:  fieldName + "." + subFieldName,
-			ColumnName:  columnName,
-			ConvertExpr: convertExpr,
+		if mapping, ok := m.embeddedFieldMapping(fieldName, subFieldName, kv.Value, embeddedStructType, fieldInfo); ok {
+			group.Mappings = append(group.Mappings, mapping)
 		}
-		group.Mappings = append(group.Mappings, mapping)
 	}
 
 	if len(group.Mappings) > 0 {
@@ -59,6 +43,53 @@ func (m *Mapper) analyzeEmbeddedCompositeLit(fieldName string, compLit *ast.Comp
 	return nil
 }
 
+// analyzeEmbeddedFieldAssign 分析对嵌入字段子字段的直接赋值
+// 处理场景：po.Model.ID = d.ID，追加到同一嵌入字段的 Embedded 映射组
+func (m *Mapper) analyzeEmbeddedFieldAssign(fieldName, subFieldName string, value ast.Expr, fieldInfo *FieldAnalysisInfo) {
+	embeddedStructType, _ := m.getStructType(m.typeSpecs[fieldInfo.EmbeddedTypeName])
+	mapping, ok := m.embeddedFieldMapping(fieldName, subFieldName, value, embeddedStructType, fieldInfo)
+	if !ok {
+		return
+	}
+
+	for i := range m.result.Groups {
+		group := &m.result.Groups[i]
+		if group.Type == Embedded && group.TargetField == fieldName {
+			group.Mappings = append(group.Mappings, mapping)
+			return
+		}
+	}
+	m.result.Groups = append(m.result.Groups, MappingGroup{
+		Type:        Embedded,
+		TargetField: fieldName,
+		Mappings:    []FieldMapping2{mapping},
+	})
+}
+
+// embeddedFieldMapping 构建嵌入字段中单个子字段的映射
+func (m *Mapper) embeddedFieldMapping(fieldName, subFieldName string, value ast.Expr, embeddedStructType *ast.StructType, fieldInfo *FieldAnalysisInfo) (FieldMapping2, bool) {
+	sourcePath, convertExpr := m.extractSourcePath(value)
+	if sourcePath == "" {
+		m.skipMapping(fieldName+"."+subFieldName, value, "无法识别的表达式")
+		return FieldMapping2{}, false
+	}
+
+	// 获取子字段的列名
+	subFieldInfo := m.getFieldInfo(embeddedStructType, subFieldName)
+	columnName := subFieldInfo.ColumnName
+	if fieldInfo.EmbeddedPrefix != "" {
+		columnName = fieldInfo.EmbeddedPrefix + columnName
+	}
+
+	return FieldMapping2{
+		SourcePath:  sourcePath,
+		TargetPath:  fieldName + "." + subFieldName,
+		ColumnName:  columnName,
+		ConvertExpr: convertExpr,
+		ValueExpr:   m.valueExpr(value),
+	}, true
+}
+
 // analyzeEmbeddedOneToManyMapping 分析嵌入字段的一对多映射
 // 处理场景：Account: d.Account 或 Account: d.Account.ToColumns()
 // 一个输入字段映射到多个输出列（嵌入结构体的所有字段）
@@ -73,6 +104,7 @@ func (m *Mapper) analyzeEmbeddedOneToManyMapping(fieldName string, value ast.Exp
 		}
 	}
 	if sourcePath == "" {
+		m.skipMapping(fieldName, value, "无法识别的表达式")
 		return nil
 	}
 
@@ -104,6 +136,11 @@ func (m *Mapper) analyzeEmbeddedOneToManyMapping(fieldName string, value ast.Exp
 		}
 	}
 
+	// 所有展开的列都来自同一个赋值表达式
+	for i := range group.Mappings {
+		group.Mappings[i].ValueExpr = m.valueExpr(value)
+	}
+
 	if len(group.Mappings) > 0 {
 		m.result.Groups = append(m.result.Groups, group)
 	}
@@ -19,6 +19,9 @@ type Generator2 struct {
 	// 接收者信息
 	receiverType string
 	receiverVar  string
+
+	// 已写入的条件赋值列，同一列的多个条件赋值只写入一次
+	writtenColumns map[string]bool
 }
 
 // NewGenerator2 创建新的代码生成器
@@ -36,6 +39,8 @@ func NewGenerator2(result *ParseResult2, genFuncName string) *Generator2 {
 		receiverVar:   receiverVar,
 		sourceType:    result.SourceType,
 		sourcePackage: result.SourceTypePackage,
+
+		writtenColumns: make(map[string]bool),
 	}
 
 	// 如果源类型是外部包，添加导入
@@ -63,31 +68,31 @@ func (g *Generator2) Generate() (string, string) {
 
 	funcCode := funcBuilder.String()
 
-	// 验证字段覆盖情况，添加 Missing fields 注释
-	missingComment := g.validateFieldCoverage(funcCode)
-	if missingComment != "" {
-		funcCode = missingComment + funcCode
-	}
-
 	// 生成带 imports 的完整代码
 	var fullBuilder strings.Builder
-	if len(g.imports) > 0 {
-		fullBuilder.WriteString("import (\n")
-		importList := make([]string, 0, len(g.imports))
-		for imp := range g.imports {
-			importList = append(importList, imp)
-		}
-		sort.Strings(importList)
-		for _, imp := range importList {
-			fullBuilder.WriteString(fmt.Sprintf("\t\"%s\"\n", imp))
-		}
-		fullBuilder.WriteString(")\n\n")
-	}
+	writeImports(&fullBuilder, g.imports)
 	fullBuilder.WriteString(funcCode)
 
 	return fullBuilder.String(), funcCode
 }
 
+// writeImports 按字母顺序写入 import 块
+func writeImports(builder *strings.Builder, imports map[string]bool) {
+	if len(imports) == 0 {
+		return
+	}
+	builder.WriteString("import (\n")
+	importList := make([]string, 0, len(imports))
+	for imp := range imports {
+		importList = append(importList, imp)
+	}
+	sort.Strings(importList)
+	for _, imp := range importList {
+		builder.WriteString(fmt.Sprintf("\t\"%s\"\n", imp))
+	}
+	builder.WriteString(")\n\n")
+}
+
 // generateFunctionSignature 生成函数签名
 func (g *Generator2) generateFunctionSignature(builder *strings.Builder) {
 	sourceTypeName := g.sourceType
@@ -135,7 +140,7 @@ func (g *Generator2) generateFunctionBody(builder *strings.Builder) {
 			}
 		} else {
 			// 单个 OneToOne 映射
-			g.writeFieldMapping(builder, item.mapping.SourcePath, item.mapping.TargetPath, item.mapping.ColumnName)
+			g.writeFieldMapping(builder, item.mapping)
 		}
 	}
 }
@@ -151,9 +156,14 @@ type generationItem struct {
 // createSortedGenerationItems 创建按位置排序的生成项列表
 // OneToOne 类型的映射会被拆分为单独的项，以便与其他组类型交错
 func (g *Generator2) createSortedGenerationItems() []generationItem {
+	return sortedGenerationItems(g.result.Groups)
+}
+
+// sortedGenerationItems 将映射组拆分为生成项并按 PO 字段位置排序
+func sortedGenerationItems(groups []MappingGroup) []generationItem {
 	var items []generationItem
 
-	for _, group := range g.result.Groups {
+	for _, group := range groups {
 		if group.Type == OneToOne {
 			// OneToOne 类型：每个映射作为单独的项
 			for _, mapping := range group.Mappings {
@@ -185,7 +195,7 @@ func (g *Generator2) createSortedGenerationItems() []generationItem {
 func (g *Generator2) generateEmbeddedMappings(builder *strings.Builder, group MappingGroup) {
 	builder.WriteString(fmt.Sprintf("\t// Embedded: %s\n", group.TargetField))
 	for _, mapping := range group.Mappings {
-		g.writeFieldMapping(builder, mapping.SourcePath, mapping.TargetPath, mapping.ColumnName)
+		g.writeFieldMapping(builder, mapping)
 	}
 }
 
@@ -228,7 +238,7 @@ func (g *Generator2) generateManyToOneMappings(builder *strings.Builder, group M
 		for _, mapping := range mappings {
 			// 从 TargetPath 获取字段路径 (去掉 JSON 字段名前缀)
 			fieldPath := mapping.GoFieldPath
-			g.writeJSONFieldMapping(builder, mapping.SourcePath, mapping.JSONPath, fieldPath)
+			g.writeJSONFieldMapping(builder, mapping, fieldPath)
 		}
 	}
 
@@ -243,9 +253,9 @@ func (g *Generator2) generateOneToManyMappings(builder *strings.Builder, group M
 	builder.WriteString(fmt.Sprintf("\t// OneToMany: %s\n", group.SourceField))
 
 	// 一对多：一个源字段展开为多个目标字段
-	// 使用源字段的第一部分作为条件检查
+	// 源字段或条件赋值引用的字段任一变化时写入
 	if len(group.Mappings) > 0 {
-		builder.WriteString(fmt.Sprintf("\tif fields.%s.IsPresent() {\n", group.SourceField))
+		builder.WriteString(fmt.Sprintf("\tif %s {\n", g.presentCondition(g.groupSources(group))))
 		for _, mapping := range group.Mappings {
 			builder.WriteString(fmt.Sprintf("\t\tvalues[\"%s\"] = b.%s\n", mapping.ColumnName, mapping.TargetPath))
 		}
@@ -260,14 +270,14 @@ func (g *Generator2) generateMethodCallMappings(builder *strings.Builder, group
 	// 方法调用映射：多个源字段通过方法组合为一个目标字段
 	// 如果任一源字段被修改，则更新目标字段
 	if len(group.Mappings) > 0 {
-		// 生成条件检查：任一字段 IsPresent
-		var conditions []string
+		// 生成条件检查：任一字段（包括条件赋值引用的字段）IsPresent
+		var sources []string
 		for _, mapping := range group.Mappings {
-			conditions = append(conditions, fmt.Sprintf("fields.%s.IsPresent()", mapping.SourcePath))
+			sources = append(sources, g.mappingSources(mapping)...)
 		}
 
 		columnName := group.Mappings[0].ColumnName
-		builder.WriteString(fmt.Sprintf("\tif %s {\n", strings.Join(conditions, " || ")))
+		builder.WriteString(fmt.Sprintf("\tif %s {\n", g.presentCondition(sources)))
 		builder.WriteString(fmt.Sprintf("\t\tvalues[\"%s\"] = b.%s\n", columnName, group.TargetField))
 		builder.WriteString("\t}\n")
 	}
@@ -279,8 +289,8 @@ func (g *Generator2) generateEmbeddedOneToManyMappings(builder *strings.Builder,
 	builder.WriteString(fmt.Sprintf("\t// EmbeddedOneToMany: %s -> %s\n", group.SourceField, group.TargetField))
 
 	if len(group.Mappings) > 0 {
-		// 使用源字段作为条件检查
-		builder.WriteString(fmt.Sprintf("\tif fields.%s.IsPresent() {\n", group.SourceField))
+		// 使用源字段以及条件赋值引用的字段作为条件检查
+		builder.WriteString(fmt.Sprintf("\tif %s {\n", g.presentCondition(g.groupSources(group))))
 		for _, mapping := range group.Mappings {
 			builder.WriteString(fmt.Sprintf("\t\tvalues[\"%s\"] = b.%s\n", mapping.ColumnName, mapping.TargetPath))
 		}
@@ -289,16 +299,71 @@ func (g *Generator2) generateEmbeddedOneToManyMappings(builder *strings.Builder,
 }
 
 // writeFieldMapping 写入字段映射
-func (g *Generator2) writeFieldMapping(builder *strings.Builder, sourcePath, targetPath, columnName string) {
-	builder.WriteString(fmt.Sprintf("\tif fields.%s.IsPresent() {\n", sourcePath))
-	builder.WriteString(fmt.Sprintf("\t\tvalues[\"%s\"] = b.%s\n", columnName, targetPath))
+// 条件赋值的列在任一分支的源字段或条件引用的字段变化时写入，
+// b 由 ToPO 计算得到，条件不成立时已是 else 分支的值或零值，因此直接写入 b 中的值
+func (g *Generator2) writeFieldMapping(builder *strings.Builder, mapping FieldMapping2) {
+	sources := []string{mapping.SourcePath}
+	if conditional, ok := g.columnSources(mapping.ColumnName); ok {
+		if g.writtenColumns[mapping.ColumnName] {
+			return
+		}
+		g.writtenColumns[mapping.ColumnName] = true
+		sources = conditional
+	}
+	builder.WriteString(fmt.Sprintf("\tif %s {\n", g.presentCondition(sources)))
+	builder.WriteString(fmt.Sprintf("\t\tvalues[\"%s\"] = b.%s\n", mapping.ColumnName, mapping.TargetPath))
 	builder.WriteString("\t}\n")
 }
 
+// columnSources 返回含有条件赋值的列所依赖的 Domain 字段：各次赋值的源字段以及条件引用的字段
+// 列中没有条件赋值时返回 false
+func (g *Generator2) columnSources(column string) ([]string, bool) {
+	var sources []string
+	conditional := false
+	for _, group := range g.result.Groups {
+		for _, mapping := range group.Mappings {
+			if mapping.ColumnName != column {
+				continue
+			}
+			conditional = conditional || mapping.Condition != ""
+			sources = append(sources, g.mappingSources(mapping)...)
+		}
+	}
+	return sources, conditional
+}
+
+// groupSources 返回组的源字段以及组内条件赋值引用的字段
+func (g *Generator2) groupSources(group MappingGroup) []string {
+	sources := []string{group.SourceField}
+	for _, mapping := range group.Mappings {
+		sources = append(sources, conditionFields(mapping.Condition, g.result.ParamName)...)
+	}
+	return sources
+}
+
+// mappingSources 返回映射的源字段以及条件引用的字段
+func (g *Generator2) mappingSources(mapping FieldMapping2) []string {
+	return append([]string{mapping.SourcePath}, conditionFields(mapping.Condition, g.result.ParamName)...)
+}
+
+// presentCondition 生成任一字段 IsPresent 的条件表达式，重复的字段只检查一次
+func (g *Generator2) presentCondition(sources []string) string {
+	seen := make(map[string]bool, len(sources))
+	var conditions []string
+	for _, source := range sources {
+		if seen[source] {
+			continue
+		}
+		seen[source] = true
+		conditions = append(conditions, fmt.Sprintf("fields.%s.IsPresent()", source))
+	}
+	return strings.Join(conditions, " || ")
+}
+
 // writeJSONFieldMapping 写入 JSON 字段映射
-func (g *Generator2) writeJSONFieldMapping(builder *strings.Builder, sourcePath, jsonPath, fieldPath string) {
-	builder.WriteString(fmt.Sprintf("\t\tif fields.%s.IsPresent() {\n", sourcePath))
-	builder.WriteString(fmt.Sprintf("\t\t\tset.Set(\"%s\", field.%s)\n", jsonPath, fieldPath))
+func (g *Generator2) writeJSONFieldMapping(builder *strings.Builder, mapping FieldMapping2, fieldPath string) {
+	builder.WriteString(fmt.Sprintf("\t\tif %s {\n", g.presentCondition(g.mappingSources(mapping))))
+	builder.WriteString(fmt.Sprintf("\t\t\tset.Set(\"%s\", field.%s)\n", mapping.JSONPath, fieldPath))
 	builder.WriteString("\t\t}\n")
 }
 
@@ -317,48 +382,6 @@ func (g *Generator2) groupByJSONPathPrefix(mappings []FieldMapping2) map[string]
 	return result
 }
 
-// validateFieldCoverage 验证字段覆盖情况，返回 Missing fields 注释
-func (g *Generator2) validateFieldCoverage(generatedCode string) string {
-	if len(g.result.TargetColumns) == 0 {
-		return ""
-	}
-
-	// 收集所有被赋值的数据库字段名
-	assignedFields := make(map[string]bool)
-
-	// 查找所有的 values["字段名"] 赋值
-	lines := strings.Split(generatedCode, "\n")
-	for _, line := range lines {
-		// 简单的字符串匹配来查找 values["字段名"] 模式
-		if strings.Contains(line, "values[") && strings.Contains(line, "] =") {
-			// 提取字段名
-			start := strings.Index(line, `values["`) + 8
-			if start > 7 { // 确保找到了 values["
-				end := strings.Index(line[start:], `"]`)
-				if end > 0 {
-					fieldName := line[start : start+end]
-					assignedFields[fieldName] = true
-				}
-			}
-		}
-	}
-
-	// 检查哪些字段缺失
-	var missingFields []string
-	for _, expectedField := range g.result.TargetColumns {
-		if !assignedFields[expectedField] {
-			missingFields = append(missingFields, expectedField)
-		}
-	}
-
-	if len(missingFields) == 0 {
-		return ""
-	}
-
-	sort.Strings(missingFields)
-	return fmt.Sprintf("// Missing fields: %s\n", strings.Join(missingFields, ", "))
-}
-
 // Generate2 使用新方案生成代码
 // filePath: 源文件路径
 // receiverType: 接收者类型名（如 "ListingPO"）
@@ -271,25 +271,23 @@ func TestGenerate2ExternalEmbedded(t *testing.T) {
 	t.Logf("Generated full code:\n%s", fullCode)
 }
 
-// TestGenerate2MissingFields 测试缺失字段注释的代码生成
+// TestGenerate2MissingFields 测试缺失字段通过覆盖报告给出，而不是生成注释
 func TestGenerate2MissingFields(t *testing.T) {
 	fullCode, funcCode, err := automap.Generate2("testdata/models.go", "PartialUserPO", "ToPO", "ToPatch")
 	if err != nil {
 		t.Fatalf("Generate2 failed: %v", err)
 	}
 
-	// 验证 Missing fields 注释存在
-	if !strings.Contains(funcCode, "// Missing fields:") {
-		t.Errorf("Missing 'Missing fields' comment, got:\n%s", funcCode)
+	if strings.Contains(funcCode, "// Missing fields:") {
+		t.Errorf("Generated code should not contain 'Missing fields' comment, got:\n%s", funcCode)
 	}
 
-	// 验证缺失的字段名
-	if !strings.Contains(funcCode, "default_id") {
-		t.Errorf("Missing 'default_id' in missing fields comment, got:\n%s", funcCode)
-	}
-	if !strings.Contains(funcCode, "deleted_at") {
-		t.Errorf("Missing 'deleted_at' in missing fields comment, got:\n%s", funcCode)
+	// 验证缺失的列出现在覆盖报告中
+	result, err := automap.Parse("testdata/models.go", "PartialUserPO", "ToPO")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
 	}
+	assertUnmappedColumns(t, result, "default_id", "deleted_at")
 
 	// 验证已映射的字段
 	expectedMappings := []string{
@@ -335,18 +333,18 @@ func TestGenerate2GormModel(t *testing.T) {
 		}
 	}
 
-	// 验证 Missing fields 注释
+	// 验证覆盖报告
 	// deleted_at: 因为 DeletedAt 使用了 gorm.DeletedAt{} 复杂转换，mapper 无法识别
 	// last_login: 故意未映射
-	if !strings.Contains(funcCode, "// Missing fields:") {
-		t.Errorf("Missing 'Missing fields' comment, got:\n%s", funcCode)
-	}
-	// 验证 gorm.Model 的 deleted_at 被正确识别为目标列（证明外部包解析正常）
-	if !strings.Contains(funcCode, "deleted_at") {
-		t.Errorf("Missing 'deleted_at' in missing fields (gorm.Model column should be recognized), got:\n%s", funcCode)
+	result, err := automap.Parse("testdata/models.go", "GormUserPO", "ToPO")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
 	}
-	if !strings.Contains(funcCode, "last_login") {
-		t.Errorf("Missing 'last_login' in missing fields comment, got:\n%s", funcCode)
+	// gorm.Model 的 deleted_at 被识别为目标列，证明外部包解析正常
+	assertUnmappedColumns(t, result, "deleted_at", "last_login")
+	skipped := result.Coverage.SkippedMappings
+	if len(skipped) != 1 || skipped[0].Name != "Model.DeletedAt" {
+		t.Errorf("Expected Model.DeletedAt to be skipped, got: %+v", skipped)
 	}
 
 	t.Logf("Generated full code:\n%s", fullCode)
@@ -422,26 +420,41 @@ func TestGenerate2CrossFileFromMapperFile(t *testing.T) {
 	t.Logf("Generated full code:\n%s", fullCode)
 }
 
-// TestGenerate2CrossFileWithMissingFields 测试跨文件场景下的Missing fields注释
-// 验证当从方法文件解析时，能正确计算并生成Missing fields注释
+// TestGenerate2CrossFileWithMissingFields 测试跨文件场景下的覆盖报告
+// 验证当从方法文件解析时，能正确计算未映射的列
 func TestGenerate2CrossFileWithMissingFields(t *testing.T) {
 	// 传入方法所在的文件
-	fullCode, funcCode, err := automap.Generate2("testdata/cross_file_mapper.go", "CrossFilePO", "ToPO", "ToPatch")
+	_, funcCode, err := automap.Generate2("testdata/cross_file_mapper.go", "CrossFilePO", "ToPO", "ToPatch")
 	if err != nil {
 		t.Fatalf("Generate2 failed: %v", err)
 	}
 
 	// CrossFilePO 嵌入了 Model（包含 ID, CreatedAt, UpdatedAt）
-	// ToPO 方法映射了所有这些字段，所以不应该有 Missing fields 注释
-	// 如果出现 Missing fields，说明跨文件解析出了问题
-	if strings.Contains(fullCode, "// Missing fields:") {
-		// 如果有 Missing fields，检查是否是合理的缺失
-		t.Logf("Full code contains Missing fields comment:\n%s", fullCode)
+	// ToPO 方法映射了所有这些字段，如果出现未映射的列，说明跨文件解析出了问题
+	result, err := automap.Parse("testdata/cross_file_mapper.go", "CrossFilePO", "ToPO")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
 	}
+	assertUnmappedColumns(t, result)
 
 	t.Logf("Generated func code:\n%s", funcCode)
 }
 
+// assertUnmappedColumns 验证覆盖报告中未映射的列
+func assertUnmappedColumns(t *testing.T, result *automap.ParseResult2, expected ...string) {
+	t.Helper()
+	var columns []string
+	for _, item := range result.Coverage.UnmappedColumns {
+		columns = append(columns, item.Name)
+		if item.Pos == "" {
+			t.Errorf("Unmapped column %s has no position", item.Name)
+		}
+	}
+	if strings.Join(columns, ",") != strings.Join(expected, ",") {
+		t.Errorf("Unmapped columns mismatch: got %v, want %v", columns, expected)
+	}
+}
+
 // TestGenerate2CustomJSONTag 测试 JSON tag 与 Go 字段名不同的情况
 // 验证生成代码使用真实的 Go 字段名，而不是从 JSON tag 推断
 func TestGenerate2CustomJSONTag(t *testing.T) {
@@ -925,6 +938,42 @@ func TestGenerate2ExternalPackageEmbeddedNoPrefix(t *testing.T) {
 	t.Logf("Generated full code:\n%s", fullCode)
 }
 
+// TestGenerate2Imperative 测试逐步构建 PO 时条件赋值生成的 patch
+// 条件赋值的列在源字段或条件引用的字段任一变化时写入 ToPO 的结果，只修改条件引用的字段也会更新该列
+func TestGenerate2Imperative(t *testing.T) {
+	fullCode, funcCode, err := automap.Generate2("testdata/models.go", "ImperativePO", "ToPO", "ToPatch")
+	if err != nil {
+		t.Fatalf("Generate2 failed: %v", err)
+	}
+
+	expectedMappings := []string{
+		"if fields.ID.IsPresent() {\n\t\tvalues[\"id\"] = b.Model.ID",
+		"if fields.Name.IsPresent() {\n\t\tvalues[\"name\"] = b.Name",
+		// if/else 两个分支合并为一次写入，Nickname 改为 "" 时写入 else 分支的值
+		"if fields.Nickname.IsPresent() || fields.Name.IsPresent() {\n\t\tvalues[\"nickname\"] = b.Nickname\n\t}",
+		// 条件不成立时写入零值
+		"if fields.Level.IsPresent() {\n\t\tvalues[\"level\"] = b.Level\n\t}",
+		// Addr 改为 nil 时 city/street 写入零值
+		"if fields.Addr.IsPresent() {\n\t\tvalues[\"city\"] = b.City\n\t\tvalues[\"street\"] = b.Street\n\t}",
+		// 只修改条件引用的 Vip 也会更新 title
+		"if fields.Title.IsPresent() || fields.Vip.IsPresent() {\n\t\tvalues[\"title\"] = b.Title\n\t}",
+		`set.Set("phone", field.Phone)`,
+	}
+	for _, expected := range expectedMappings {
+		if !strings.Contains(funcCode, expected) {
+			t.Errorf("Missing expected mapping: %s", expected)
+		}
+	}
+	if n := strings.Count(funcCode, `values["nickname"]`); n != 1 {
+		t.Errorf("Expected nickname to be written once, got %d", n)
+	}
+	if strings.Contains(funcCode, "&& input.") {
+		t.Errorf("Conditions should not gate the write, got:\n%s", funcCode)
+	}
+
+	t.Logf("Generated full code:\n%s", fullCode)
+}
+
 // 测试映射关系为空时候的代码
 func TestGenerate2Empty(t *testing.T) {
 	_, funcCode, err := automap.Generate2("testdata/models.go", "ExternalNoPrefixPO", "ToPO2", "ToPatch")
@@ -0,0 +1,296 @@
+package automap
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// analyzeResultVar 分析逐步构建 PO 的函数体
+// 处理场景：
+//
+//	po := &XxxPO{ID: d.ID}
+//	po.Name = d.Name
+//	if d.Addr != nil {
+//		po.City = d.Addr.City
+//	}
+//	return po
+func (m *Mapper) analyzeResultVar(body *ast.BlockStmt, resultVar string) error {
+	// 初始值中的结构体字面量
+	if init, exists := m.varExprs[resultVar]; exists {
+		if err := m.analyzeReturnExpr(init); err != nil {
+			return err
+		}
+	}
+
+	// 逐条分析对结果变量字段的赋值
+	return m.analyzeFieldAssignments(body.List, resultVar, "")
+}
+
+// analyzeFieldAssignments 递归分析语句列表中对结果变量字段的赋值
+// cond: 外层 if 条件（使用 ToPO 的参数名），为空表示无条件
+func (m *Mapper) analyzeFieldAssignments(stmts []ast.Stmt, resultVar, cond string) error {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.AssignStmt:
+			if err := m.analyzeFieldAssignStmt(s, resultVar, cond); err != nil {
+				return err
+			}
+
+		case *ast.BlockStmt:
+			if err := m.analyzeFieldAssignments(s.List, resultVar, cond); err != nil {
+				return err
+			}
+
+		case *ast.IfStmt:
+			if err := m.analyzeIfStmt(s, resultVar, cond); err != nil {
+				return err
+			}
+
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			m.skipResultAssignments(s, resultVar, "位于循环或 switch 语句中")
+		}
+	}
+	return nil
+}
+
+// analyzeIfStmt 分析 if 语句，分支中的赋值带上对应的条件
+func (m *Mapper) analyzeIfStmt(s *ast.IfStmt, resultVar, cond string) error {
+	if assignStmt, ok := s.Init.(*ast.AssignStmt); ok {
+		m.processAssignStmt(assignStmt)
+	}
+
+	ifCond, ok := m.conditionExpr(s.Cond, resultVar)
+	if !ok {
+		// 条件引用了结果变量或无法追踪的局部变量，无法确定分支是否执行，两个分支中的赋值都跳过
+		m.skipResultAssignments(s, resultVar, fmt.Sprintf("条件 %s 无法解析", types.ExprString(s.Cond)))
+		return nil
+	}
+
+	if err := m.analyzeFieldAssignments(s.Body.List, resultVar, andCondition(cond, ifCond)); err != nil {
+		return err
+	}
+	if s.Else != nil {
+		return m.analyzeFieldAssignments([]ast.Stmt{s.Else}, resultVar, andCondition(cond, negateCondition(ifCond)))
+	}
+	return nil
+}
+
+// analyzeFieldAssignStmt 分析赋值语句：po.Name = d.Name 或 po.Model.ID = d.ID
+func (m *Mapper) analyzeFieldAssignStmt(s *ast.AssignStmt, resultVar, cond string) error {
+	if len(s.Lhs) != len(s.Rhs) {
+		return nil
+	}
+
+	for i, lhs := range s.Lhs {
+		path := m.resultFieldPath(lhs, resultVar)
+		if len(path) == 0 {
+			// 不是对结果变量的赋值，按局部变量处理
+			if s.Tok == token.DEFINE || s.Tok == token.ASSIGN {
+				m.processAssignStmt(&ast.AssignStmt{Lhs: []ast.Expr{lhs}, Tok: s.Tok, Rhs: []ast.Expr{s.Rhs[i]}})
+			}
+			continue
+		}
+		if s.Tok != token.ASSIGN {
+			continue
+		}
+
+		value := s.Rhs[i]
+		err := m.withCondition(cond, func() error {
+			return m.analyzeFieldAssign(path, value)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analyzeFieldAssign 分析对结果变量某个字段路径的赋值
+func (m *Mapper) analyzeFieldAssign(path []string, value ast.Expr) error {
+	structType, _ := m.getStructType(m.typeSpecs[m.receiverType])
+	fieldInfo := m.getFieldInfo(structType, path[0])
+
+	switch {
+	case len(path) == 1:
+		return m.analyzeFieldValue(path[0], value, "", fieldInfo, "")
+	case len(path) == 2 && fieldInfo.IsEmbedded:
+		m.analyzeEmbeddedFieldAssign(path[0], path[1], value, fieldInfo)
+	default:
+		m.skipMapping(strings.Join(path, "."), value, "不支持的字段路径")
+	}
+	return nil
+}
+
+// resultFieldPath 提取对结果变量的字段路径：po.Model.ID -> ["Model", "ID"]
+func (m *Mapper) resultFieldPath(expr ast.Expr, resultVar string) []string {
+	var path []string
+	for {
+		switch e := expr.(type) {
+		case *ast.SelectorExpr:
+			path = append([]string{e.Sel.Name}, path...)
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			if e.Name != resultVar {
+				return nil
+			}
+			return path
+		default:
+			return nil
+		}
+	}
+}
+
+// withCondition 执行 fn，并为 fn 新增的映射设置条件
+func (m *Mapper) withCondition(cond string, fn func() error) error {
+	if cond == "" {
+		return fn()
+	}
+
+	counts := make([]int, len(m.result.Groups))
+	for i, group := range m.result.Groups {
+		counts[i] = len(group.Mappings)
+	}
+
+	err := fn()
+
+	for i := range m.result.Groups {
+		start := 0
+		if i < len(counts) {
+			start = counts[i]
+		}
+		for j := start; j < len(m.result.Groups[i].Mappings); j++ {
+			m.result.Groups[i].Mappings[j].Condition = cond
+		}
+	}
+	return err
+}
+
+// conditionExpr 将 if 条件转换为只引用 ToPO 参数的表达式
+// 局部变量会被展开为其首次赋值的表达式，无法展开（如引用了结果变量）时返回 false
+func (m *Mapper) conditionExpr(cond ast.Expr, resultVar string) (string, bool) {
+	text := types.ExprString(cond)
+
+	// 每轮展开一层局部变量，以变量数量为上限，避免变量间循环引用
+	for range len(m.varExprs) + 1 {
+		// 重新解析得到独立的 AST，避免修改原函数体
+		expr, err := parser.ParseExpr(text)
+		if err != nil {
+			return "", false
+		}
+
+		replaced, resolved := false, true
+		expr = astutil.Apply(expr, func(c *astutil.Cursor) bool {
+			ident, ok := c.Node().(*ast.Ident)
+			if !ok || c.Name() == "Sel" {
+				return true
+			}
+			init, isLocal := m.varExprs[ident.Name]
+			switch {
+			case ident.Name == m.paramName:
+			case ident.Name == resultVar:
+				resolved = false
+			case isLocal:
+				if _, ok := init.(*ast.BinaryExpr); ok {
+					init = &ast.ParenExpr{X: init}
+				}
+				c.Replace(init)
+				replaced = true
+			case types.Universe.Lookup(ident.Name) != nil:
+			case m.resolveImportPath(ident.Name) != "":
+			default:
+				resolved = false
+			}
+			return true
+		}, nil).(ast.Expr)
+
+		if !resolved {
+			return "", false
+		}
+		text = types.ExprString(expr)
+		if !replaced {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// andCondition 用 && 连接两个条件
+func andCondition(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return wrapCondition(a) + " && " + wrapCondition(b)
+}
+
+// negateCondition 对条件取反，比较表达式直接翻转运算符：a != b -> a == b
+func negateCondition(cond string) string {
+	if expr, err := parser.ParseExpr(cond); err == nil {
+		if binary, ok := expr.(*ast.BinaryExpr); ok {
+			if op, ok := negatedOps[binary.Op]; ok {
+				binary.Op = op
+				return types.ExprString(binary)
+			}
+		}
+	}
+	if strings.Contains(cond, " ") {
+		return "!(" + cond + ")"
+	}
+	return "!" + cond
+}
+
+// negatedOps 比较运算符及其取反
+var negatedOps = map[token.Token]token.Token{
+	token.EQL: token.NEQ,
+	token.NEQ: token.EQL,
+	token.LSS: token.GEQ,
+	token.GEQ: token.LSS,
+	token.GTR: token.LEQ,
+	token.LEQ: token.GTR,
+}
+
+// wrapCondition 含有 || 的条件在与其他条件连接时需要加括号
+func wrapCondition(cond string) string {
+	if strings.Contains(cond, "||") {
+		return "(" + cond + ")"
+	}
+	return cond
+}
+
+// conditionFields 返回条件表达式引用的 Domain 顶层字段：d.Addr != nil -> ["Addr"]
+// 方法调用（如 d.IsVIP()）不是字段，不会被返回
+func conditionFields(cond, paramName string) []string {
+	if cond == "" || paramName == "" {
+		return nil
+	}
+	expr, err := parser.ParseExpr(cond)
+	if err != nil {
+		return nil
+	}
+	var fields []string
+	astutil.Apply(expr, func(c *astutil.Cursor) bool {
+		sel, ok := c.Node().(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == paramName {
+			if call, ok := c.Parent().(*ast.CallExpr); !ok || call.Fun != sel {
+				fields = append(fields, sel.Sel.Name)
+			}
+		}
+		return true
+	}, nil)
+	return fields
+}
@@ -62,6 +62,7 @@ func (m *Mapper) extractJSONMappings(group *MappingGroup, compLit *ast.Composite
 		// 提取源路径
 		sourcePath, convertExpr := m.extractSourcePath(kv.Value)
 		if sourcePath == "" {
+			m.skipMapping(group.TargetField+"."+goFieldPath, kv.Value, "无法识别的表达式")
 			continue
 		}
 
@@ -72,6 +73,7 @@ func (m *Mapper) extractJSONMappings(group *MappingGroup, compLit *ast.Composite
 			JSONPath:    jsonPath,
 			GoFieldPath: goFieldPath,
 			ConvertExpr: convertExpr,
+			ValueExpr:   m.valueExpr(kv.Value),
 		}
 		group.Mappings = append(group.Mappings, mapping)
 	}
@@ -5,6 +5,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"reflect"
 	"strings"
@@ -38,6 +39,15 @@ type Mapper struct {
 	// 方法调用映射：变量名 -> (methodName, receiverType)
 	methodCallMap map[string]methodCallInfo
 
+	// 局部变量初始值：变量名 -> 首次赋值的表达式
+	varExprs map[string]ast.Expr
+
+	// 当前分析的函数体
+	funcBody *ast.BlockStmt
+
+	// 目标类型列的源码位置：列名 -> "file:line"
+	targetColumnPositions map[string]string
+
 	// 解析结果
 	result *ParseResult2
 }
@@ -61,6 +71,7 @@ func NewMapper(filePath string) *Mapper {
 func (m *Mapper) Parse(receiverType, funcName string) (*ParseResult2, error) {
 	m.receiverType = receiverType
 	m.funcName = funcName
+	m.targetColumnPositions = make(map[string]string)
 	m.result = &ParseResult2{
 		FuncName:             funcName,
 		ReceiverType:         receiverType,
@@ -90,6 +101,9 @@ func (m *Mapper) Parse(receiverType, funcName string) (*ParseResult2, error) {
 	// 根据字段位置设置映射的 FieldPosition
 	m.setFieldPositions()
 
+	// 统计字段覆盖情况
+	m.collectCoverage()
+
 	return m.result, nil
 }
 
@@ -161,6 +175,7 @@ func (m *Mapper) extractFuncParams(funcDecl *ast.FuncDecl) {
 	param := funcDecl.Type.Params.List[0]
 	if len(param.Names) > 0 {
 		m.paramName = param.Names[0].Name
+		m.result.ParamName = m.paramName
 	}
 
 	// 提取源类型和包信息
@@ -206,11 +221,13 @@ func (m *Mapper) analyzeFuncBody(body *ast.BlockStmt) error {
 	// 初始化变量映射表
 	m.varMap = make(map[string]string)
 	m.methodCallMap = make(map[string]methodCallInfo)
+	m.varExprs = make(map[string]ast.Expr)
+	m.funcBody = body
 
 	// 第一遍：收集所有局部变量的赋值
 	m.collectVariableAssignments(body)
 
-	// 第二遍：分析 return 语句
+	// 第二遍：分析 return 语句（返回结构体字面量或结果变量）
 	for _, stmt := range body.List {
 		// 查找 return 语句
 		retStmt, ok := stmt.(*ast.ReturnStmt)
@@ -218,8 +235,16 @@ func (m *Mapper) analyzeFuncBody(body *ast.BlockStmt) error {
 			continue
 		}
 
-		// 分析返回的结构体字面量
 		for _, result := range retStmt.Results {
+			// 返回局部变量：逐步构建 PO 的写法
+			if ident, ok := result.(*ast.Ident); ok && ident.Name != "nil" {
+				if err := m.analyzeResultVar(body, ident.Name); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// 分析返回的结构体字面量
 			if err := m.analyzeReturnExpr(result); err != nil {
 				return err
 			}
@@ -285,6 +310,7 @@ func (m *Mapper) analyzeReturnExpr(expr ast.Expr) error {
 	// 获取结构体字面量
 	compLit, ok := expr.(*ast.CompositeLit)
 	if !ok {
+		m.skipMapping(m.receiverType, expr, "返回值不是结构体字面量")
 		return nil
 	}
 
@@ -420,6 +446,25 @@ func (m *Mapper) getFieldInfo(structType *ast.StructType, fieldName string) *Fie
 	return &FieldAnalysisInfo{ColumnName: toSnakeCase(fieldName)}
 }
 
+// domainFieldType 返回 Domain 结构体中字段的类型表达式，Domain 不在当前包或没有该字段时返回空
+func (m *Mapper) domainFieldType(fieldName string) string {
+	if m.result.SourceTypePackage != "" {
+		return ""
+	}
+	structType, ok := m.getStructType(m.typeSpecs[m.sourceType])
+	if !ok {
+		return ""
+	}
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == fieldName {
+				return types.ExprString(field.Type)
+			}
+		}
+	}
+	return ""
+}
+
 // getStructType 获取结构体类型
 func (m *Mapper) getStructType(typeSpec *ast.TypeSpec) (*ast.StructType, bool) {
 	if typeSpec == nil {
@@ -471,6 +516,7 @@ func (m *Mapper) analyzeFieldValue(fieldName string, value ast.Expr, targetPrefi
 						SourcePath: sourcePath,
 						TargetPath: targetPath,
 						ColumnName: fieldInfo.ColumnName,
+						ValueExpr:  m.valueExpr(value),
 					}
 					m.addMapping(mapping, fieldInfo, jsonColumn)
 					return nil
@@ -478,7 +524,7 @@ func (m *Mapper) analyzeFieldValue(fieldName string, value ast.Expr, targetPrefi
 
 				// 情况2: lo.Map(entity.GetMethod(), func...) - 方法调用
 				if methodInfo := m.extractLoMapMethodCall(innerCall); methodInfo != nil {
-					return m.analyzeMethodCallMapping(fieldName, methodInfo, fieldInfo)
+					return m.analyzeMethodCallMapping(fieldName, value, methodInfo, fieldInfo)
 				}
 			}
 
@@ -490,6 +536,7 @@ func (m *Mapper) analyzeFieldValue(fieldName string, value ast.Expr, targetPrefi
 						SourcePath: sourcePath,
 						TargetPath: targetPath,
 						ColumnName: fieldInfo.ColumnName,
+						ValueExpr:  m.valueExpr(value),
 					}
 					m.addMapping(mapping, fieldInfo, jsonColumn)
 					return nil
@@ -500,19 +547,20 @@ func (m *Mapper) analyzeFieldValue(fieldName string, value ast.Expr, targetPrefi
 
 	// 检查是否是直接的方法调用 d.MethodName()
 	if methodInfo := m.extractMethodCallInfo(value); methodInfo != nil {
-		return m.analyzeMethodCallMapping(fieldName, methodInfo, fieldInfo)
+		return m.analyzeMethodCallMapping(fieldName, value, methodInfo, fieldInfo)
 	}
 
 	// 检查是否是来自方法调用的局部变量
 	if ident, ok := value.(*ast.Ident); ok {
 		if methodInfo, exists := m.methodCallMap[ident.Name]; exists {
-			return m.analyzeMethodCallMapping(fieldName, &methodInfo, fieldInfo)
+			return m.analyzeMethodCallMapping(fieldName, value, &methodInfo, fieldInfo)
 		}
 	}
 
 	// 提取源路径和转换表达式
 	sourcePath, convertExpr := m.extractSourcePath(value)
 	if sourcePath == "" {
+		m.skipMapping(targetPath, value, "无法识别的表达式")
 		return nil
 	}
 
@@ -527,6 +575,7 @@ func (m *Mapper) analyzeFieldValue(fieldName string, value ast.Expr, targetPrefi
 		TargetPath:  targetPath,
 		ColumnName:  columnName,
 		ConvertExpr: convertExpr,
+		ValueExpr:   m.valueExpr(value),
 	}
 
 	// 添加到对应的组
@@ -538,7 +587,7 @@ func (m *Mapper) analyzeFieldValue(fieldName string, value ast.Expr, targetPrefi
 // 支持两种情况：
 // 1. 方法在本包中定义：分析方法体，提取使用的字段
 // 2. 方法在外部包中定义：从方法名推断字段名（如 GetExchangeRules -> ExchangeRules）
-func (m *Mapper) analyzeMethodCallMapping(fieldName string, methodInfo *methodCallInfo, fieldInfo *FieldAnalysisInfo) error {
+func (m *Mapper) analyzeMethodCallMapping(fieldName string, value ast.Expr, methodInfo *methodCallInfo, fieldInfo *FieldAnalysisInfo) error {
 	// 查找方法定义
 	methods, exists := m.methodDecls[methodInfo.receiverType]
 	if exists {
@@ -558,6 +607,7 @@ func (m *Mapper) analyzeMethodCallMapping(fieldName string, methodInfo *methodCa
 						SourcePath: usedField,
 						TargetPath: fieldName,
 						ColumnName: fieldInfo.ColumnName,
+						ValueExpr:  m.valueExpr(value),
 					}
 					group.Mappings = append(group.Mappings, mapping)
 				}
@@ -576,6 +626,7 @@ func (m *Mapper) analyzeMethodCallMapping(fieldName string, methodInfo *methodCa
 			SourcePath: sourcePath,
 			TargetPath: fieldName,
 			ColumnName: fieldInfo.ColumnName,
+			ValueExpr:  m.valueExpr(value),
 		}
 		m.addMapping(mapping, fieldInfo, "")
 	}
@@ -593,6 +644,23 @@ func inferFieldNameFromMethod(methodName string) string {
 	return methodName
 }
 
+// valueExpr 返回赋值表达式的源码文本，局部变量展开为其首次赋值的表达式
+func (m *Mapper) valueExpr(expr ast.Expr) string {
+	// 以变量数量为上限，避免变量间循环引用
+	for range len(m.varExprs) {
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			break
+		}
+		init, exists := m.varExprs[ident.Name]
+		if !exists {
+			break
+		}
+		expr = init
+	}
+	return types.ExprString(expr)
+}
+
 // extractSourcePath 提取源路径和转换表达式
 func (m *Mapper) extractSourcePath(expr ast.Expr) (sourcePath, convertExpr string) {
 	switch e := expr.(type) {
@@ -707,9 +775,10 @@ func (m *Mapper) addMapping(mapping FieldMapping2, fieldInfo *FieldAnalysisInfo,
 
 		// 创建新组
 		group := MappingGroup{
-			Type:        OneToMany,
-			SourceField: sourceField,
-			Mappings:    []FieldMapping2{mapping},
+			Type:            OneToMany,
+			SourceField:     sourceField,
+			SourceFieldType: m.domainFieldType(sourceField),
+			Mappings:        []FieldMapping2{mapping},
 		}
 		m.result.Groups = append(m.result.Groups, group)
 		return
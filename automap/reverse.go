@@ -0,0 +1,360 @@
+package automap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// inverseConv 一条逆转换规则
+type inverseConv struct {
+	expr  string // 逆转换表达式，%s 为 PO 侧表达式
+	guard string // 赋值前的判断条件（可选），%s 为 PO 侧表达式
+	pkg   string // 逆转换表达式需要的导入路径（可选）
+	lossy string // 有损转换的说明（可选），仍然生成赋值，同时记录到 TODO 报告
+}
+
+// inverseConvs 已知的逆转换规则
+// key 为 ToPO 中的赋值表达式模式，%s 为 Domain 字段（如 "d.CreatedAt"）
+var inverseConvs = map[string]inverseConv{
+	"%s":  {expr: "%s"},
+	"*%s": {expr: "lo.ToPtr(%s)", pkg: "github.com/samber/lo"},
+	"&%s": {expr: "*%s", guard: "%s != nil"},
+
+	// time.Time <-> Unix 时间戳
+	"%s.Unix()":          {expr: "time.Unix(%s, 0)", pkg: "time"},
+	"%s.UnixMilli()":     {expr: "time.UnixMilli(%s)", pkg: "time"},
+	"%s.UnixMicro()":     {expr: "time.UnixMicro(%s)", pkg: "time"},
+	"%s.UnixNano()":      {expr: "time.Unix(0, %s)", pkg: "time"},
+	"time.Unix(%s, 0)":   {expr: "%s.Unix()"},
+	"time.UnixMilli(%s)": {expr: "%s.UnixMilli()"},
+
+	// decimal.Decimal <-> 数值
+	"%s.BigInt()":                  {expr: "decimal.NewFromBigInt(%s, 0)", pkg: "github.com/shopspring/decimal", lossy: "BigInt() 丢弃了小数部分"},
+	"%s.IntPart()":                 {expr: "decimal.NewFromInt(%s)", pkg: "github.com/shopspring/decimal", lossy: "IntPart() 丢弃了小数部分"},
+	"%s.InexactFloat64()":          {expr: "decimal.NewFromFloat(%s)", pkg: "github.com/shopspring/decimal", lossy: "InexactFloat64() 可能损失精度"},
+	"decimal.NewFromBigInt(%s, 0)": {expr: "%s.BigInt()"},
+	"decimal.NewFromInt(%s)":       {expr: "%s.IntPart()"},
+	"decimal.NewFromInt32(%s)":     {expr: "int32(%s.IntPart())"},
+	"decimal.NewFromFloat(%s)":     {expr: "%s.InexactFloat64()"},
+
+	// JSON 包装类型
+	"datatypes.NewJSONType(%s)":  {expr: "%s.Data()"},
+	"datatypes.NewJSONSlice(%s)": {expr: "%s"},
+}
+
+// ReverseGenerator 根据 ToPO 的映射分析结果生成反向的 ToDomain 函数
+// 无法还原的映射不会被猜测，而是以 TODO 注释写入代码并记录到 ReverseReport
+type ReverseGenerator struct {
+	result      *ParseResult2
+	genFuncName string
+	imports     map[string]bool
+
+	receiverVar string
+	poVar       string
+	domainVar   string
+
+	report *ReverseReport
+}
+
+// NewReverseGenerator 创建反向映射代码生成器
+func NewReverseGenerator(result *ParseResult2, genFuncName string) *ReverseGenerator {
+	receiverVar := "p"
+	if len(result.ReceiverType) > 0 {
+		receiverVar = strings.ToLower(result.ReceiverType[:1])
+	}
+
+	// Domain 变量沿用 ToPO 的参数名，与接收者或 PO 参数冲突时换名
+	domainVar := result.ParamName
+	if domainVar == "" || domainVar == "po" || domainVar == receiverVar {
+		domainVar = "d"
+	}
+	if domainVar == receiverVar {
+		domainVar = "dm"
+	}
+
+	g := &ReverseGenerator{
+		result:      result,
+		genFuncName: genFuncName,
+		imports:     make(map[string]bool),
+		receiverVar: receiverVar,
+		poVar:       "po",
+		domainVar:   domainVar,
+		report:      &ReverseReport{FuncName: genFuncName},
+	}
+
+	// 如果源类型是外部包，添加导入
+	if result.SourceTypeImportPath != "" {
+		g.imports[result.SourceTypeImportPath] = true
+	}
+
+	return g
+}
+
+// Generate 生成代码
+// 返回: (带imports的完整代码, 纯函数代码, 无法还原的映射报告)
+func (g *ReverseGenerator) Generate() (string, string, *ReverseReport) {
+	sourceTypeName := g.result.SourceType
+	if g.result.SourceTypePackage != "" {
+		sourceTypeName = g.result.SourceTypePackage + "." + g.result.SourceType
+	}
+
+	var funcBuilder strings.Builder
+	funcBuilder.WriteString(fmt.Sprintf("func (%s *%s) %s(%s *%s) *%s {\n",
+		g.receiverVar, g.result.ReceiverType, g.genFuncName, g.poVar, g.result.ReceiverType, sourceTypeName))
+	funcBuilder.WriteString(fmt.Sprintf("\tif %s == nil {\n\t\treturn nil\n\t}\n", g.poVar))
+	funcBuilder.WriteString(fmt.Sprintf("\t%s := &%s{}\n", g.domainVar, sourceTypeName))
+
+	for _, item := range sortedGenerationItems(g.result.Groups) {
+		if !item.isGroup {
+			g.writeAssign(&funcBuilder, "\t", OneToOne, item.mapping, g.poVar+"."+item.mapping.TargetPath)
+			continue
+		}
+		switch item.group.Type {
+		case Embedded:
+			g.generateEmbedded(&funcBuilder, item.group)
+		case ManyToOne:
+			g.generateManyToOne(&funcBuilder, item.group)
+		case OneToMany:
+			g.generateOneToMany(&funcBuilder, item.group)
+		case MethodCall:
+			g.generateMethodCall(&funcBuilder, item.group)
+		case EmbeddedOneToMany:
+			g.generateEmbeddedOneToMany(&funcBuilder, item.group)
+		}
+	}
+
+	funcBuilder.WriteString(fmt.Sprintf("\treturn %s\n", g.domainVar))
+	funcBuilder.WriteString("}\n")
+	funcCode := funcBuilder.String()
+
+	var fullBuilder strings.Builder
+	writeImports(&fullBuilder, g.imports)
+	fullBuilder.WriteString(funcCode)
+
+	return fullBuilder.String(), funcCode, g.report
+}
+
+// generateEmbedded 还原嵌入字段：d.ID = po.Model.ID
+func (g *ReverseGenerator) generateEmbedded(builder *strings.Builder, group MappingGroup) {
+	builder.WriteString(fmt.Sprintf("\t// Embedded: %s\n", group.TargetField))
+	for _, mapping := range group.Mappings {
+		g.writeAssign(builder, "\t", Embedded, mapping, g.poVar+"."+mapping.TargetPath)
+	}
+}
+
+// generateManyToOne 还原 JSON 字段：先取出 Data()，再逐个字段赋值
+func (g *ReverseGenerator) generateManyToOne(builder *strings.Builder, group MappingGroup) {
+	builder.WriteString(fmt.Sprintf("\t// ManyToOne: %s\n", group.TargetField))
+
+	// 所有字段都无法还原时不声明 field，避免未使用的变量
+	if !g.anyInverse(group.Mappings) {
+		for _, mapping := range group.Mappings {
+			g.writeAssign(builder, "\t", ManyToOne, mapping, "")
+		}
+		return
+	}
+
+	builder.WriteString("\t{\n")
+	builder.WriteString(fmt.Sprintf("\t\tfield := %s.%s.Data()\n", g.poVar, group.TargetField))
+	for _, mapping := range group.Mappings {
+		g.writeAssign(builder, "\t\t", ManyToOne, mapping, "field."+mapping.GoFieldPath)
+	}
+	builder.WriteString("\t}\n")
+}
+
+// generateOneToMany 还原一对多映射：d.Location.Country = po.Country
+// 源字段为指针时先分配；无法确定源字段类型时（可能是指针，直接赋值会解引用 nil）只记录 TODO
+func (g *ReverseGenerator) generateOneToMany(builder *strings.Builder, group MappingGroup) {
+	builder.WriteString(fmt.Sprintf("\t// OneToMany: %s\n", group.SourceField))
+	if group.SourceFieldType == "" {
+		for _, mapping := range group.Mappings {
+			g.writeTODO(builder, "\t", ReverseTODO{
+				Type:        OneToMany,
+				TargetField: mapping.TargetPath,
+				Columns:     []string{mapping.ColumnName},
+				SourcePaths: []string{mapping.SourcePath},
+				ValueExpr:   mapping.ValueExpr,
+				Reason:      fmt.Sprintf("无法确定 Domain 字段 %s 的类型，可能是 nil 指针", group.SourceField),
+			})
+		}
+		return
+	}
+	if elem, ok := strings.CutPrefix(group.SourceFieldType, "*"); ok && g.anyInverse(group.Mappings) {
+		field := g.domainVar + "." + group.SourceField
+		builder.WriteString(fmt.Sprintf("\tif %s == nil {\n\t\t%s = &%s{}\n\t}\n", field, field, elem))
+	}
+	for _, mapping := range group.Mappings {
+		g.writeAssign(builder, "\t", OneToMany, mapping, g.poVar+"."+mapping.TargetPath)
+	}
+}
+
+// generateMethodCall 方法调用把多个 Domain 字段合成一个列，无法反向拆分，只记录 TODO
+func (g *ReverseGenerator) generateMethodCall(builder *strings.Builder, group MappingGroup) {
+	todo := ReverseTODO{
+		Type:        MethodCall,
+		TargetField: group.TargetField,
+		Reason:      fmt.Sprintf("由方法 %s() 组合生成，无法反向拆分", group.MethodName),
+	}
+	for _, mapping := range group.Mappings {
+		todo.SourcePaths = append(todo.SourcePaths, mapping.SourcePath)
+		todo.ValueExpr = mapping.ValueExpr
+	}
+	if len(group.Mappings) > 0 {
+		todo.Columns = []string{group.Mappings[0].ColumnName}
+	}
+	g.writeTODO(builder, "\t", todo)
+}
+
+// generateEmbeddedOneToMany 还原嵌入一对多映射
+// 只有 ToPO 中直接赋值（Account: d.Account）时才能整体还原
+func (g *ReverseGenerator) generateEmbeddedOneToMany(builder *strings.Builder, group MappingGroup) {
+	builder.WriteString(fmt.Sprintf("\t// EmbeddedOneToMany: %s -> %s\n", group.SourceField, group.TargetField))
+	if len(group.Mappings) == 0 {
+		return
+	}
+
+	valueExpr := group.Mappings[0].ValueExpr
+	if valueExpr == g.domainExpr(group.SourceField) && group.Mappings[0].Condition == "" {
+		builder.WriteString(fmt.Sprintf("\t%s.%s = %s.%s\n", g.domainVar, group.SourceField, g.poVar, group.TargetField))
+		return
+	}
+
+	todo := ReverseTODO{
+		Type:        EmbeddedOneToMany,
+		TargetField: group.TargetField,
+		SourcePaths: []string{group.SourceField},
+		ValueExpr:   valueExpr,
+		Reason:      fmt.Sprintf("不支持反向还原的表达式 %s", valueExpr),
+	}
+	if condition := group.Mappings[0].Condition; condition != "" {
+		todo.Reason = fmt.Sprintf("ToPO 中的条件赋值 (if %s)", condition)
+	}
+	for _, mapping := range group.Mappings {
+		todo.Columns = append(todo.Columns, mapping.ColumnName)
+	}
+	g.writeTODO(builder, "\t", todo)
+}
+
+// writeAssign 写入单个字段的反向赋值，无法还原时写入 TODO
+func (g *ReverseGenerator) writeAssign(builder *strings.Builder, indent string, typ MappingType, mapping FieldMapping2, poExpr string) {
+	target := g.domainVar + "." + mapping.SourcePath
+	conv, ok := g.findInverse(mapping)
+	if !ok {
+		reason := fmt.Sprintf("不支持反向还原的表达式 %s", mapping.ValueExpr)
+		switch {
+		case mapping.Condition != "":
+			reason = fmt.Sprintf("ToPO 中的条件赋值 (if %s)", mapping.Condition)
+		case mapping.ValueExpr == "":
+			reason = "缺少 ToPO 中的赋值表达式"
+		}
+		g.writeTODO(builder, indent, ReverseTODO{
+			Type:        typ,
+			TargetField: mapping.TargetPath,
+			Columns:     []string{mapping.ColumnName},
+			SourcePaths: []string{mapping.SourcePath},
+			ValueExpr:   mapping.ValueExpr,
+			Reason:      reason,
+		})
+		return
+	}
+
+	if conv.pkg != "" {
+		g.imports[conv.pkg] = true
+	}
+	if conv.lossy != "" {
+		g.writeTODO(builder, indent, ReverseTODO{
+			Type:        typ,
+			TargetField: mapping.TargetPath,
+			Columns:     []string{mapping.ColumnName},
+			SourcePaths: []string{mapping.SourcePath},
+			ValueExpr:   mapping.ValueExpr,
+			Reason:      fmt.Sprintf("有损转换，%s，还原结果可能与原值不同", conv.lossy),
+			Lossy:       true,
+		})
+	}
+	assign := fmt.Sprintf("%s = %s", target, fmt.Sprintf(conv.expr, poExpr))
+	if conv.guard == "" {
+		builder.WriteString(fmt.Sprintf("%s%s\n", indent, assign))
+		return
+	}
+	builder.WriteString(fmt.Sprintf("%sif %s {\n", indent, fmt.Sprintf(conv.guard, poExpr)))
+	builder.WriteString(fmt.Sprintf("%s\t%s\n", indent, assign))
+	builder.WriteString(fmt.Sprintf("%s}\n", indent))
+}
+
+// findInverse 根据 ToPO 中的赋值表达式查找逆转换规则
+// 条件赋值无法确定还原时是否满足条件，不做还原
+func (g *ReverseGenerator) findInverse(mapping FieldMapping2) (inverseConv, bool) {
+	if mapping.ValueExpr == "" || mapping.Condition != "" {
+		return inverseConv{}, false
+	}
+	source := g.domainExpr(mapping.SourcePath)
+	for pattern, conv := range inverseConvs {
+		if fmt.Sprintf(pattern, source) == mapping.ValueExpr {
+			return conv, true
+		}
+	}
+	return inverseConv{}, false
+}
+
+// anyInverse 是否有映射可以还原
+func (g *ReverseGenerator) anyInverse(mappings []FieldMapping2) bool {
+	for _, mapping := range mappings {
+		if _, ok := g.findInverse(mapping); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// domainExpr 返回 ToPO 中访问 Domain 字段的表达式，如 "d.Location.City"
+func (g *ReverseGenerator) domainExpr(sourcePath string) string {
+	if g.result.ParamName == "" {
+		return sourcePath
+	}
+	return g.result.ParamName + "." + sourcePath
+}
+
+// writeTODO 写入 TODO 注释并记录到报告
+func (g *ReverseGenerator) writeTODO(builder *strings.Builder, indent string, todo ReverseTODO) {
+	targets := make([]string, 0, len(todo.SourcePaths))
+	for _, path := range todo.SourcePaths {
+		targets = append(targets, g.domainVar+"."+path)
+	}
+	builder.WriteString(fmt.Sprintf("%s// TODO(automap): %s <- %s.%s: %s\n",
+		indent, strings.Join(targets, ", "), g.poVar, todo.TargetField, todo.Reason))
+	g.report.TODOs = append(g.report.TODOs, todo)
+}
+
+// String 返回报告的可读文本，没有 TODO 时返回空字符串
+func (r *ReverseReport) String() string {
+	if r == nil || len(r.TODOs) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%s: %d mapping(s) need manual conversion or review\n", r.FuncName, len(r.TODOs)))
+	for _, todo := range r.TODOs {
+		kind := string(todo.Type)
+		if todo.Lossy {
+			kind += ", lossy"
+		}
+		builder.WriteString(fmt.Sprintf("  - [%s] %s (%s) -> %s: %s\n",
+			kind, strings.Join(todo.SourcePaths, ", "), strings.Join(todo.Columns, ", "), todo.TargetField, todo.Reason))
+	}
+	return builder.String()
+}
+
+// GenerateReverse 生成 ToPO 的反向映射函数
+// filePath: 源文件路径
+// receiverType: 接收者类型名（如 "ListingPO"）
+// funcName: 原函数名（如 "ToPO"）
+// genFuncName: 生成的函数名（如 "ToDomain"）
+func GenerateReverse(filePath, receiverType, funcName, genFuncName string) (string, string, *ReverseReport, error) {
+	result, err := Parse(filePath, receiverType, funcName)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("解析失败: %w", err)
+	}
+
+	fullCode, funcCode, report := NewReverseGenerator(result, genFuncName).Generate()
+	return fullCode, funcCode, report, nil
+}
@@ -0,0 +1,238 @@
+package automap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/donutnomad/gotoolkit/automap"
+)
+
+// TestGenerateReverseMixed 测试嵌入、JSON 和 Unix 时间戳的反向映射
+func TestGenerateReverseMixed(t *testing.T) {
+	fullCode, funcCode, report, err := automap.GenerateReverse("testdata/models.go", "AccountPO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+
+	expected := []string{
+		"func (a *AccountPO) ToDomain(po *AccountPO) *AccountDomain {",
+		"d := &AccountDomain{}",
+		"d.ID = po.Model.ID",
+		"d.CreatedAt = po.Model.CreatedAt",
+		"field := po.Settings.Data()",
+		"d.Theme = field.Theme",
+		"d.Username = po.Username",
+		"d.LastLogin = time.Unix(po.LastLogin, 0)",
+		"return d",
+	}
+	for _, want := range expected {
+		if !strings.Contains(funcCode, want) {
+			t.Errorf("Missing expected code: %s\ngot:\n%s", want, funcCode)
+		}
+	}
+	if !strings.Contains(fullCode, `"time"`) {
+		t.Errorf("Missing time import, got:\n%s", fullCode)
+	}
+	if len(report.TODOs) != 0 {
+		t.Errorf("Expected no TODOs, got:\n%s", report)
+	}
+}
+
+// TestGenerateReverseOneToMany 测试一对多和局部变量的反向映射
+func TestGenerateReverseOneToMany(t *testing.T) {
+	_, funcCode, _, err := automap.GenerateReverse("testdata/models.go", "CompanyPO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+	if !strings.Contains(funcCode, "d.Location.Country = po.Country") {
+		t.Errorf("Missing OneToMany assignment, got:\n%s", funcCode)
+	}
+
+	// name := d.Name 这类局部变量应展开为原始字段
+	_, funcCode, report, err := automap.GenerateReverse("testdata/models.go", "ProductPO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+	for _, want := range []string{"d.Name = po.Name", "d.Price = po.Price"} {
+		if !strings.Contains(funcCode, want) {
+			t.Errorf("Missing expected code: %s\ngot:\n%s", want, funcCode)
+		}
+	}
+	if len(report.TODOs) != 0 {
+		t.Errorf("Expected no TODOs, got:\n%s", report)
+	}
+}
+
+// TestGenerateReverseOneToManyPointer 测试源字段为指针的一对多映射先分配再赋值
+func TestGenerateReverseOneToManyPointer(t *testing.T) {
+	_, funcCode, report, err := automap.GenerateReverse("testdata/models.go", "ShipmentPO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+	alloc := "if d.Dest == nil {\n\t\td.Dest = &LocationInfo{}\n\t}\n"
+	idx := strings.Index(funcCode, alloc)
+	if idx < 0 {
+		t.Fatalf("Missing pointer allocation, got:\n%s", funcCode)
+	}
+	for _, want := range []string{"d.Dest.Country = po.Country", "d.Dest.City = po.City"} {
+		if i := strings.Index(funcCode, want); i < idx {
+			t.Errorf("Expected %s after the allocation, got:\n%s", want, funcCode)
+		}
+	}
+	if len(report.TODOs) != 0 {
+		t.Errorf("Expected no TODOs, got:\n%s", report)
+	}
+}
+
+// TestGenerateReverseConversions 测试已知类型转换的还原和无法识别表达式的 TODO
+func TestGenerateReverseConversions(t *testing.T) {
+	fullCode, funcCode, report, err := automap.GenerateReverse("testdata/models.go", "ReversePO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+
+	expected := []string{
+		"d.Amount = po.Amount.BigInt()",
+		"d.Price = decimal.NewFromInt(po.Price)",
+		"d.ExpireAt = time.UnixMilli(po.ExpireAt)",
+		"if po.Remark != nil {\n\t\td.Remark = *po.Remark\n\t}",
+		"// TODO(automap): d.Nickname <- po.Nickname",
+	}
+	for _, want := range expected {
+		if !strings.Contains(funcCode, want) {
+			t.Errorf("Missing expected code: %s\ngot:\n%s", want, funcCode)
+		}
+	}
+	if strings.Contains(funcCode, "d.Nickname =") {
+		t.Errorf("Nickname should not be guessed, got:\n%s", funcCode)
+	}
+	for _, imp := range []string{`"github.com/shopspring/decimal"`, `"time"`} {
+		if !strings.Contains(fullCode, imp) {
+			t.Errorf("Missing import %s, got:\n%s", imp, fullCode)
+		}
+	}
+
+	// IntPart()/BigInt() 的还原有损，生成赋值的同时记录 TODO
+	for _, want := range []string{
+		"// TODO(automap): d.Price <- po.Price: 有损转换",
+		"// TODO(automap): d.Total <- po.Total: 有损转换",
+		"d.Total = decimal.NewFromBigInt(po.Total, 0)",
+	} {
+		if !strings.Contains(funcCode, want) {
+			t.Errorf("Missing lossy conversion: %s\ngot:\n%s", want, funcCode)
+		}
+	}
+	if len(report.TODOs) != 3 {
+		t.Fatalf("Expected 3 TODOs, got:\n%s", report)
+	}
+	var todo automap.ReverseTODO
+	lossy := make(map[string]automap.ReverseTODO)
+	for _, item := range report.TODOs {
+		if item.Lossy {
+			lossy[item.TargetField] = item
+		} else {
+			todo = item
+		}
+	}
+	if lossy["Price"].ValueExpr != "d.Price.IntPart()" || lossy["Total"].ValueExpr != "d.Total.BigInt()" {
+		t.Errorf("Unexpected lossy TODOs: %+v", lossy)
+	}
+	for _, want := range []string{"[one_to_one, lossy] Price (price)", "[one_to_one, lossy] Total (total)"} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("Lossy TODO not marked in report: %s\n%s", want, report)
+		}
+	}
+	if todo.Type != automap.OneToOne || todo.TargetField != "Nickname" || todo.ValueExpr != "strings.ToLower(d.Nickname)" {
+		t.Errorf("Unexpected TODO: %+v", todo)
+	}
+	if len(todo.Columns) != 1 || todo.Columns[0] != "nickname" {
+		t.Errorf("Unexpected TODO columns: %v", todo.Columns)
+	}
+}
+
+// TestGenerateReversePointer 测试指针解引用的反向映射
+func TestGenerateReversePointer(t *testing.T) {
+	fullCode, funcCode, _, err := automap.GenerateReverse("testdata/models.go", "PointerPO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+
+	// ToPO 的参数名为 entity，反向映射沿用该变量名
+	for _, want := range []string{"entity := &PointerDomain{}", "entity.Name = lo.ToPtr(po.Name)", "entity.Score = po.Score"} {
+		if !strings.Contains(funcCode, want) {
+			t.Errorf("Missing expected code: %s\ngot:\n%s", want, funcCode)
+		}
+	}
+	if !strings.Contains(fullCode, `"github.com/samber/lo"`) {
+		t.Errorf("Missing lo import, got:\n%s", fullCode)
+	}
+}
+
+// TestGenerateReverseMethodCall 测试方法调用映射被记录为 TODO 而不是猜测
+func TestGenerateReverseMethodCall(t *testing.T) {
+	_, funcCode, report, err := automap.GenerateReverse("testdata/models.go", "CustomerPO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+
+	if strings.Contains(funcCode, "= po.Address") {
+		t.Errorf("MethodCall mapping should not be guessed, got:\n%s", funcCode)
+	}
+	if !strings.Contains(funcCode, "// TODO(automap): d.City, d.Country, d.Province, d.Street <- po.Address") {
+		t.Errorf("Missing TODO comment, got:\n%s", funcCode)
+	}
+
+	if len(report.TODOs) != 1 {
+		t.Fatalf("Expected 1 TODO, got:\n%s", report)
+	}
+	todo := report.TODOs[0]
+	if todo.Type != automap.MethodCall || todo.TargetField != "Address" {
+		t.Errorf("Unexpected TODO: %+v", todo)
+	}
+	if strings.Join(todo.SourcePaths, ",") != "City,Country,Province,Street" {
+		t.Errorf("Unexpected TODO source paths: %v", todo.SourcePaths)
+	}
+	if !strings.Contains(report.String(), "GetAddress()") {
+		t.Errorf("Report should mention the method, got:\n%s", report)
+	}
+}
+
+// TestGenerateReverseJSONSlice 测试 JSONSlice 的反向映射
+func TestGenerateReverseJSONSlice(t *testing.T) {
+	_, funcCode, report, err := automap.GenerateReverse("testdata/models.go", "JSONSlicePO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+
+	// datatypes.NewJSONSlice(entity.Tags) 可以直接还原
+	if !strings.Contains(funcCode, "entity.Tags = po.Tags") {
+		t.Errorf("Missing Tags assignment, got:\n%s", funcCode)
+	}
+	// lo.Map 转换无法还原
+	if strings.Contains(funcCode, "entity.ExchangeRules =") {
+		t.Errorf("lo.Map mapping should not be guessed, got:\n%s", funcCode)
+	}
+	if len(report.TODOs) != 1 || report.TODOs[0].SourcePaths[0] != "ExchangeRules" {
+		t.Errorf("Expected ExchangeRules TODO, got:\n%s", report)
+	}
+}
+
+// TestGenerateReverseConditional 测试条件赋值被记录为 TODO
+func TestGenerateReverseConditional(t *testing.T) {
+	_, funcCode, report, err := automap.GenerateReverse("testdata/models.go", "ImperativePO", "ToPO", "ToDomain")
+	if err != nil {
+		t.Fatalf("GenerateReverse failed: %v", err)
+	}
+
+	for _, want := range []string{"d.ID = po.Model.ID", "d.Name = po.Name", "d.Phone = field.Phone"} {
+		if !strings.Contains(funcCode, want) {
+			t.Errorf("Missing expected code: %s\ngot:\n%s", want, funcCode)
+		}
+	}
+	if strings.Contains(funcCode, "d.Addr.City =") {
+		t.Errorf("Conditional mapping should not be guessed, got:\n%s", funcCode)
+	}
+	if len(report.TODOs) != 6 {
+		t.Errorf("Expected 6 TODOs, got:\n%s", report)
+	}
+}
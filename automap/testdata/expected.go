@@ -44,6 +44,9 @@ type FieldMapping struct {
 
 	// JSONPath JSON内部路径（仅ManyToOne时有效），如 "author.name"
 	JSONPath string
+
+	// Condition 条件赋值的条件表达式，如 "d.Addr != nil"
+	Condition string
 }
 
 // MappingGroup 映射组（表示一组相关的映射）
@@ -453,3 +456,46 @@ var ExpectedJSONSliceMethodMapping = ParseResult{
 		},
 	},
 }
+
+// 场景27: 逐步构建 PO - ImperativePO.ToPO
+// po := &XxxPO{...} 后逐字段赋值，if 分支中的赋值带有条件
+var ExpectedImperativeMapping = ParseResult{
+	FuncName:     "ToPO",
+	ReceiverType: "ImperativePO",
+	SourceType:   "ImperativeDomain",
+	TargetType:   "ImperativePO",
+	Groups: []MappingGroup{
+		{
+			Type: OneToOne,
+			Mappings: []FieldMapping{
+				{SourcePath: "Name", TargetPath: "Name", ColumnName: "name"},
+				{SourcePath: "Nickname", TargetPath: "Nickname", ColumnName: "nickname", Condition: `d.Nickname != ""`},
+				{SourcePath: "Name", TargetPath: "Nickname", ColumnName: "nickname", Condition: `d.Nickname == ""`}, // else 分支
+				{SourcePath: "Level", TargetPath: "Level", ColumnName: "level", Condition: "d.Level > 0"},           // 局部变量被展开
+				{SourcePath: "Title", TargetPath: "Title", ColumnName: "title", Condition: "d.Vip"},                 // 条件引用其他字段
+			},
+		},
+		{
+			Type:        Embedded,
+			TargetField: "Model",
+			Mappings: []FieldMapping{
+				{SourcePath: "ID", TargetPath: "Model.ID", ColumnName: "id"},
+			},
+		},
+		{
+			Type:        OneToMany,
+			SourceField: "Addr",
+			Mappings: []FieldMapping{
+				{SourcePath: "Addr.City", TargetPath: "City", ColumnName: "city", Condition: "d.Addr != nil"},
+				{SourcePath: "Addr.Street", TargetPath: "Street", ColumnName: "street", Condition: "d.Addr != nil"},
+			},
+		},
+		{
+			Type:        ManyToOne,
+			TargetField: "Contact",
+			Mappings: []FieldMapping{
+				{SourcePath: "Phone", TargetPath: "Contact", ColumnName: "contact", JSONPath: "phone"},
+			},
+		},
+	},
+}
@@ -1,10 +1,13 @@
 package testdata
 
 import (
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/donutnomad/xchain/caip10"
 	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -587,7 +590,7 @@ func (p *ShippingPO) ToPO(d *ShippingDomain) *ShippingPO {
 
 // ============================================================================
 // 测试场景13: 缺失字段测试 (Missing Fields)
-// ToPO 函数没有映射所有 PO 字段，用于验证 Missing fields 注释生成
+// ToPO 函数没有映射所有 PO 字段，用于验证覆盖报告中的未映射列
 // ============================================================================
 
 // PartialUserDomain 部分用户领域模型
@@ -639,7 +642,7 @@ type GormUserPO struct {
 	gorm.Model           // 嵌入 gorm.io/gorm.Model（包含 ID, CreatedAt, UpdatedAt, DeletedAt）
 	Username   string    `gorm:"column:username"`
 	Email      string    `gorm:"column:email"`
-	LastLogin  time.Time `gorm:"column:last_login"` // 未在 ToPO 中映射，用于测试 Missing fields
+	LastLogin  time.Time `gorm:"column:last_login"` // 未在 ToPO 中映射，用于测试覆盖报告
 }
 
 // ToPO 使用 gorm.Model 的映射示例
@@ -1157,3 +1160,214 @@ func (p *ExternalNoPrefixPO) ToPO(entity *ExternalNoPrefixDomain) *ExternalNoPre
 		Account: entity.Account.ToColumnsCompact(), // 方法调用返回外部包类型
 	}
 }
+
+// ============================================================================
+// 测试场景26: 反向映射 (ToDomain)
+// 已知的类型转换可以被还原，无法识别的表达式应记录为 TODO
+// ============================================================================
+
+// ReverseDomain 反向映射领域模型
+type ReverseDomain struct {
+	ID       uint64
+	Amount   *big.Int
+	Price    decimal.Decimal
+	ExpireAt time.Time
+	Remark   string
+	Nickname string
+	Total    decimal.Decimal
+}
+
+// ReversePO 反向映射持久化模型
+type ReversePO struct {
+	ID       uint64          `gorm:"column:id;primaryKey"`
+	Amount   decimal.Decimal `gorm:"column:amount"`
+	Price    int64           `gorm:"column:price"`
+	ExpireAt int64           `gorm:"column:expire_at"`
+	Remark   *string         `gorm:"column:remark"`
+	Nickname string          `gorm:"column:nickname"`
+	Total    *big.Int        `gorm:"column:total"`
+}
+
+// ToPO 反向映射示例
+func (p *ReversePO) ToPO(d *ReverseDomain) *ReversePO {
+	if d == nil {
+		return nil
+	}
+	return &ReversePO{
+		ID:       d.ID,
+		Amount:   decimal.NewFromBigInt(d.Amount, 0),
+		Price:    d.Price.IntPart(),
+		ExpireAt: d.ExpireAt.UnixMilli(),
+		Remark:   &d.Remark,
+		Nickname: strings.ToLower(d.Nickname), // 无法还原
+		Total:    d.Total.BigInt(),
+	}
+}
+
+// ============================================================================
+// 测试场景27: 逐步构建 PO (Imperative)
+// 先创建空 PO，再逐个字段赋值，部分赋值位于 if 分支中
+// ============================================================================
+
+// ImperativeAddress 地址信息
+type ImperativeAddress struct {
+	City   string
+	Street string
+}
+
+// ImperativeDomain 领域模型
+type ImperativeDomain struct {
+	ID       uint64
+	Name     string
+	Nickname string
+	Level    int
+	Phone    string
+	Addr     *ImperativeAddress
+	Vip      bool
+	Title    string
+}
+
+// ImperativePO 持久化模型
+type ImperativePO struct {
+	Model
+	Name     string                          `gorm:"column:name"`
+	Nickname string                          `gorm:"column:nickname"`
+	Level    int                             `gorm:"column:level"`
+	City     string                          `gorm:"column:city"`
+	Street   string                          `gorm:"column:street"`
+	Contact  datatypes.JSONType[ContactInfo] `gorm:"column:contact;type:json"`
+	Title    string                          `gorm:"column:title"`
+}
+
+// ToPO 逐步构建 PO 的映射示例
+func (p *ImperativePO) ToPO(d *ImperativeDomain) *ImperativePO {
+	if d == nil {
+		return nil
+	}
+	po := &ImperativePO{Name: d.Name}
+	po.Model.ID = d.ID
+	if d.Addr != nil {
+		po.City = d.Addr.City
+		po.Street = d.Addr.Street
+	}
+	if d.Nickname != "" {
+		po.Nickname = d.Nickname
+	} else {
+		po.Nickname = d.Name
+	}
+	level := d.Level
+	if level > 0 {
+		po.Level = level
+	}
+	po.Contact = datatypes.NewJSONType(ContactInfo{
+		Phone: d.Phone,
+	})
+	if d.Vip {
+		po.Title = d.Title
+	}
+	return po
+}
+
+// ============================================================================
+// 测试场景28: 字段覆盖报告
+// 包含无法识别的表达式、循环中的赋值、条件无法解析的分支、未映射的列和未读取的 Domain 字段
+// ============================================================================
+
+// CoverageDomain 覆盖报告领域模型
+type CoverageDomain struct {
+	ID       uint64
+	Name     string
+	Title    string
+	Tags     []string
+	Internal string // ToPO 中未读取
+}
+
+// CoveragePO 覆盖报告持久化模型
+type CoveragePO struct {
+	ID      uint64 `gorm:"column:id;primaryKey"`
+	Name    string `gorm:"column:name"`
+	Summary string `gorm:"column:summary"`
+	Tags    string `gorm:"column:tags"`
+	Display string `gorm:"column:display"`
+	Remark  string `gorm:"column:remark"` // 未在 ToPO 中映射
+}
+
+// ToPO 覆盖报告示例
+func (p *CoveragePO) ToPO(d *CoverageDomain) *CoveragePO {
+	po := &CoveragePO{
+		ID:      d.ID,
+		Name:    d.Name,
+		Summary: d.Name + " " + d.Title,
+	}
+	for _, tag := range d.Tags {
+		po.Tags = po.Tags + tag + ","
+	}
+	if po.Summary != "" {
+		po.Display = d.Title
+	} else {
+		po.Display = d.Name
+	}
+	return po
+}
+
+// ============================================================================
+// 测试场景: OneToMany 的源字段为指针
+// ============================================================================
+
+// ShipmentDomain 发货单领域模型
+type ShipmentDomain struct {
+	ID   uint64
+	Dest *LocationInfo // 指针字段，ToDomain 需要先分配
+}
+
+// ShipmentPO 发货单持久化模型
+type ShipmentPO struct {
+	ID      uint64 `gorm:"column:id;primaryKey"`
+	Country string `gorm:"column:country"`
+	City    string `gorm:"column:city"`
+}
+
+// ToPO 指针字段的一对多映射示例
+func (p *ShipmentPO) ToPO(d *ShipmentDomain) *ShipmentPO {
+	return &ShipmentPO{
+		ID:      d.ID,
+		Country: d.Dest.Country,
+		City:    d.Dest.City,
+	}
+}
+
+// ============================================================================
+// 测试场景: 快照比较中有 Equal 方法的类型
+// ============================================================================
+
+// PricingDomain 定价领域模型
+type PricingDomain struct {
+	ID      uint64
+	Price   decimal.Decimal
+	StartAt time.Time
+	EndAt   *time.Time
+	Note    string
+}
+
+// PricingPO 定价持久化模型, 相等的 decimal 和 time 可能有不同的表示
+type PricingPO struct {
+	ID      uint64          `gorm:"column:id;primaryKey"`
+	Price   decimal.Decimal `gorm:"column:price"`
+	StartAt time.Time       `gorm:"column:start_at"`
+	EndAt   *time.Time      `gorm:"column:end_at"`
+	Note    string          `gorm:"column:note"`
+}
+
+// ToPO 直接复制字段
+func (p *PricingPO) ToPO(d *PricingDomain) *PricingPO {
+	if d == nil {
+		return nil
+	}
+	return &PricingPO{
+		ID:      d.ID,
+		Price:   d.Price,
+		StartAt: d.StartAt,
+		EndAt:   d.EndAt,
+		Note:    d.Note,
+	}
+}
@@ -27,6 +27,9 @@ var patch = flag.Bool("patch", false, "生成GORM Patch结构体和Build方法")
 var one = flag.Bool("one", false, "将query和patch代码生成到同一个文件中")
 var outputFile = flag.String("o", "", "指定输出文件名,所有内容输出到此文件(不按文件分组)")
 var patch2 = flag.Bool("patch2", false, "生成GORM Patch2")
+var reverse = flag.Bool("reverse", false, "配合-patch2使用,同时生成ToDomain反向映射方法")
+var diff = flag.Bool("diff", false, "配合-patch2使用,生成基于新旧快照比较的DiffPatch方法代替ToPatch,不依赖setterGen的ExportPatch")
+var strict = flag.Bool("strict", false, "配合-patch2使用,ToPO存在未映射的列、未读取的字段或无法识别的表达式时生成失败")
 var patchFull = flag.Bool("patch_full", false, "生成完整的ToMap方法,直接基于PO结构体,不依赖ExportPatch")
 var mapper = flag.String("mapper", "", "struct1.ToXXX,struct2.ToXXX2")
 
@@ -6,7 +6,6 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/donutnomad/gotoolkit/automap"
 	"github.com/donutnomad/gotoolkit/internal/gormparse"
 	"github.com/donutnomad/gotoolkit/internal/utils"
 	"github.com/samber/lo"
@@ -57,15 +56,7 @@ func genQueryAndPatch(filename string, models []*gormparse.GormModelInfo, mapper
 		sb.WriteString("\n// ============ Patch Structures ============\n\n")
 		g2.GenBody(&sb, models)
 	} else if *patch2 {
-		sb.WriteString("\n// ============ Patch ============\n\n")
-		for _, item := range mapperMethod {
-			_, code, err := automap.Generate2WithOptions(item[0], "ToPatch", automap.WithFileContext(item[1]))
-			if err != nil {
-				panic(fmt.Sprintf("生成patch.ToPatch代码失败:%v", err))
-			}
-			sb.WriteString(code)
-			sb.WriteString("\n")
-		}
+		genPatch2(&sb, mapperMethod)
 	}
 	if *patchFull {
 		sb.WriteString("\n// ============ Patch Full ============\n\n")
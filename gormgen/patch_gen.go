@@ -28,20 +28,58 @@ func GenPatch(filename string, gormModels []*GormModelInfo, mapperMethod [][2]st
 		g.GenImports(&sb, gormModels)
 		g.GenBody(&sb, gormModels)
 	} else if *patch2 {
-		sb.WriteString("\n// ============ Patch ============\n\n")
-		for _, item := range mapperMethod {
-			_, code, err := automap.Generate2WithOptions(item[0], "ToPatch", automap.WithFileContext(item[1]))
-			if err != nil {
-				panic(fmt.Sprintf("生成patch.ToPatch代码失败:%v", err))
-			}
-			sb.WriteString(code)
-			sb.WriteString("\n")
-		}
+		genPatch2(&sb, mapperMethod)
 	}
 
 	return utils.WriteFormat(filename, []byte(sb.String()))
 }
 
+// genPatch2 使用 automap 生成 ToPatch 方法（开启 -diff 时生成 DiffPatch），开启 -reverse 时同时生成 ToDomain 方法
+// ToPO 的字段覆盖报告不为空时打印报告，开启 -strict 时生成失败
+func genPatch2(sb *strings.Builder, mapperMethod [][2]string) {
+	sb.WriteString("\n// ============ Patch ============\n\n")
+	var uncovered []string
+	for _, item := range mapperMethod {
+		receiverType, funcName, _ := strings.Cut(item[0], ".")
+		result, err := automap.Parse(item[1], receiverType, funcName)
+		if err != nil {
+			panic(fmt.Sprintf("生成patch.ToPatch代码失败:%v", err))
+		}
+		if !result.Coverage.IsEmpty() {
+			fmt.Printf("[gormgen] %s 字段覆盖不完整:\n%s", item[0], result.Coverage.String())
+			uncovered = append(uncovered, item[0])
+		}
+		var code string
+		if *diff {
+			_, code = automap.NewDiffGenerator(result, "DiffPatch").Generate()
+		} else {
+			_, code = automap.NewGenerator2(result, "ToPatch").Generate()
+		}
+		sb.WriteString(code)
+		sb.WriteString("\n")
+	}
+	if *strict && len(uncovered) > 0 {
+		panic(fmt.Sprintf("[gormgen] -strict: %s 的字段覆盖不完整", strings.Join(uncovered, ", ")))
+	}
+	if !*reverse {
+		return
+	}
+
+	sb.WriteString("\n// ============ ToDomain ============\n\n")
+	for _, item := range mapperMethod {
+		receiverType, funcName, _ := strings.Cut(item[0], ".")
+		_, code, report, err := automap.GenerateReverse(item[1], receiverType, funcName, "ToDomain")
+		if err != nil {
+			panic(fmt.Sprintf("生成ToDomain代码失败:%v", err))
+		}
+		if len(report.TODOs) > 0 {
+			fmt.Printf("[gormgen] %s.%s", receiverType, report)
+		}
+		sb.WriteString(code)
+		sb.WriteString("\n")
+	}
+}
+
 type PatchGen struct {
 }
 
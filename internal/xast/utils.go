@@ -57,6 +57,11 @@ func GetFieldType(expr ast.Expr, getAlias func(*ast.SelectorExpr) string) string
 		return t.Name
 	case *ast.StarExpr:
 		return "*" + GetFieldType(t.X, getAlias)
+	case *ast.Ellipsis:
+		// 可变参数 ...T
+		return "..." + GetFieldType(t.Elt, getAlias)
+	case *ast.ParenExpr:
+		return GetFieldType(t.X, getAlias)
 	case *ast.SelectorExpr:
 		x := GetFieldType(t.X, getAlias)
 		if getAlias != nil {
@@ -0,0 +1,46 @@
+package approvegen
+
+import (
+	"encoding/json"
+)
+
+// CatalogEntry 一个审批方法的描述, 由 approveGen -catalog 生成, 供管理后台渲染表单和详情
+type CatalogEntry struct {
+	Method string            `json:"method"` // MethodName()
+	Note   string            `json:"note,omitempty"`
+	Notes  map[string]string `json:"notes,omitempty"` // args::note[locale], key: 语言
+	Struct string            `json:"struct"`          // 参数结构体, 例如 _UserServiceMethodCreate
+	Fields []CatalogField    `json:"fields"`
+	Schema json.RawMessage   `json:"schema"` // 参数结构体的 JSON Schema
+}
+
+// NoteFor 指定语言的 Note, 与生成的 NoteFor(locale) 相同: 依次尝试 locale 及去掉地区后的语言, 都没有时返回 Note
+func (e CatalogEntry) NoteFor(locale string) string {
+	for ; locale != ""; locale = parentLocale(locale) {
+		if note, ok := e.Notes[locale]; ok {
+			return note
+		}
+	}
+	return e.Note
+}
+
+// CatalogField 参数结构体的字段
+type CatalogField struct {
+	Name   string `json:"name"`             // 结构体字段名, 也是 JSON 中的 key
+	Alias  string `json:"alias,omitempty"`  // args::field 的 alias
+	Type   string `json:"type"`             // Go 类型
+	Redact string `json:"redact,omitempty"` // args::field 的 redact, 展示时已脱敏
+}
+
+// Catalog 所有审批方法的描述, 由生成的 Catalog() 返回
+type Catalog []CatalogEntry
+
+// Lookup 根据 MethodName() 查找
+func (c Catalog) Lookup(method string) (CatalogEntry, bool) {
+	for _, entry := range c {
+		if entry.Method == method {
+			return entry, true
+		}
+	}
+	return CatalogEntry{}, false
+}
@@ -0,0 +1,24 @@
+package approvegen
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog(t *testing.T) {
+	catalog := Catalog{
+		{Method: "UserService_Create", Struct: "_UserServiceMethodCreate", Fields: []CatalogField{{Name: "Name", Alias: "名字", Type: "string"}}, Schema: json.RawMessage(`{"type":"object"}`)},
+	}
+	entry, ok := catalog.Lookup("UserService_Create")
+	require.True(t, ok)
+	assert.Equal(t, "名字", entry.Fields[0].Alias)
+	_, ok = catalog.Lookup("UserService_Delete")
+	assert.False(t, ok)
+
+	bs, err := json.Marshal(catalog)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"method":"UserService_Create","struct":"_UserServiceMethodCreate","fields":[{"name":"Name","alias":"名字","type":"string"}],"schema":{"type":"object"}}]`, string(bs))
+}
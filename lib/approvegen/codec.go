@@ -0,0 +1,43 @@
+package approvegen
+
+import (
+	"encoding/json"
+
+	"github.com/ugorji/go/codec"
+)
+
+// Codec 参数结构体的序列化方式, 需要与 approveGen -codec 保持一致
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSON encoding/json, 也可以读取 -codec=sonic 生成的数据
+	JSON Codec = jsonCodec{}
+	// Msgpack 二进制格式, 适合参数较大的方法; 存储时需要使用二进制安全的列类型
+	Msgpack Codec = msgpackCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+var msgpackHandle = func() *codec.MsgpackHandle {
+	var h codec.MsgpackHandle
+	h.WriteExt = true // time.Time 等类型使用 ext 编码
+	return &h
+}()
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	var out []byte
+	err := codec.NewEncoderBytes(&out, msgpackHandle).Encode(v)
+	return out, err
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	return codec.NewDecoderBytes(data, msgpackHandle).Decode(v)
+}
@@ -0,0 +1,49 @@
+package approvegen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	type args struct {
+		Name string
+		Rows [][]string
+		At   time.Time
+		Ptr  *int64
+	}
+	n := int64(7)
+	in := args{Name: "a", Rows: [][]string{{"x", "y"}}, At: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Ptr: &n}
+
+	for name, codec := range map[string]Codec{"json": JSON, "msgpack": Msgpack} {
+		t.Run(name, func(t *testing.T) {
+			bs, err := codec.Marshal(&in)
+			require.NoError(t, err)
+			var out args
+			require.NoError(t, codec.Unmarshal(bs, &out))
+			assert.True(t, in.At.Equal(out.At))
+			out.At = in.At
+			assert.Equal(t, in, out)
+		})
+	}
+}
+
+func TestWorkflowWithCodec(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, caller := env.w, env.caller
+	caller.codec = Msgpack
+	w.WithCodec(Msgpack)
+
+	req, err := w.Submit(ctx, &createArgs{Name: "bob"}, SubmitOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, '{', req.Args[0])
+
+	ret, err := w.Approve(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", ret)
+}
@@ -0,0 +1,36 @@
+package approvegen
+
+import (
+	"context"
+	"time"
+)
+
+// Decision 审批结果, 由 Callers.CallDecision 传给生成的 CallDecision;
+// @Approve 方法声明了 Decision 类型的参数时, 审批通过/拒绝的回调会收到该值
+type Decision struct {
+	Approved  bool
+	Approver  string
+	Role      string // 审批人的角色, 用于 Policy.Roles
+	Comment   string
+	DecidedAt time.Time
+	RequestID string
+	System    bool // 由 Sweeper 等系统自动做出, 不是审批人的决定
+}
+
+// DecisionCaller 可选接口, 方法声明了 Decision 参数时生成的 ApprovalCaller 实现
+type DecisionCaller interface {
+	CallDecision(ctx context.Context, arg any, d Decision) (any, error)
+}
+
+type decisionKey struct{}
+
+// WithDecision 将审批结果保存到 ctx, 回调中可以通过 DecisionFromContext 获取
+func WithDecision(ctx context.Context, d Decision) context.Context {
+	return context.WithValue(ctx, decisionKey{}, d)
+}
+
+// DecisionFromContext 获取 Callers.CallDecision 保存的审批结果, 没有时返回零值
+func DecisionFromContext(ctx context.Context) Decision {
+	d, _ := ctx.Value(decisionKey{}).(Decision)
+	return d
+}
@@ -0,0 +1,57 @@
+package approvegen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decisionCaller 实现 DecisionCaller 的 fakeCaller
+type decisionCaller struct {
+	fakeCaller
+	got []Decision
+}
+
+func (f *decisionCaller) CallDecision(ctx context.Context, arg any, d Decision) (any, error) {
+	f.got = append(f.got, d)
+	return f.fakeCaller.Call(ctx, arg, d.Approved)
+}
+
+func TestWorkflowDecide(t *testing.T) {
+	ctx := context.Background()
+	caller := &decisionCaller{}
+	w := NewWorkflow(NewMemoryStore(), Callers{caller})
+	w.now = func() time.Time { return testNow }
+
+	req, err := w.Submit(ctx, &createArgs{Name: "bob"}, SubmitOptions{})
+	require.NoError(t, err)
+	_, err = w.Decide(ctx, req.ID, Decision{Approved: false, Approver: "carol", Comment: "over budget"})
+	require.NoError(t, err)
+
+	want := Decision{Approver: "carol", Comment: "over budget", DecidedAt: testNow, RequestID: req.ID}
+	assert.Equal(t, []Decision{want}, caller.got)
+	// 回调的 ctx 中同样可以获取
+	assert.Equal(t, []Decision{want}, caller.decisions)
+
+	got, err := w.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, got.Status)
+}
+
+func TestCallersCallDecisionFallback(t *testing.T) {
+	caller := &fakeCaller{}
+	d := Decision{Approved: true, Approver: "dave", RequestID: "r1"}
+	ret, err := Callers{caller}.CallDecision(context.Background(), "UserService_Create", `{"Name":"bob"}`, d)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", ret)
+	assert.Equal(t, []bool{true}, caller.calls)
+	assert.Equal(t, []Decision{d}, caller.decisions)
+
+	// bool 接口只传递是否通过
+	_, err = Callers{caller}.Call(context.Background(), "UserService_Create", `{"Name":"bob"}`, false)
+	require.NoError(t, err)
+	assert.Equal(t, Decision{}, caller.decisions[1])
+}
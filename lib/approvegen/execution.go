@@ -0,0 +1,142 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrExecutionNotFound = errors.New("ErrExecutionNotFound")
+
+// ErrLeaseExpired 回调执行超过租约, 记录已被其他 Runner 重新抢占或已经结束, 本次的结果没有写入
+var ErrLeaseExpired = errors.New("ErrLeaseExpired")
+
+// ExecStatus 回调的执行状态
+//
+//	pending ──> succeeded
+//	        └─> failed (不可重试或超过最大次数)
+type ExecStatus string
+
+const (
+	ExecPending   ExecStatus = "pending"
+	ExecSucceeded ExecStatus = "succeeded"
+	ExecFailed    ExecStatus = "failed"
+)
+
+// Execution 审批通过/拒绝后回调的执行记录(outbox), 保存了重新执行所需的全部信息
+type Execution struct {
+	RequestID   string
+	Method      string
+	Args        string
+	Approved    bool
+	Approver    string
+	Role        string
+	Comment     string
+	System      bool
+	DecidedAt   time.Time
+	Status      ExecStatus
+	Attempts    int // 已抢占的执行次数, 包括正在执行的一次
+	LastError   string
+	NextRetryAt time.Time // Status 为 pending 时下一次执行的时间, 执行中为租约到期时间
+	UpdatedAt   time.Time
+}
+
+// Decision 重新执行时使用的审批结果
+func (e *Execution) Decision() Decision {
+	return Decision{
+		Approved:  e.Approved,
+		Approver:  e.Approver,
+		Role:      e.Role,
+		Comment:   e.Comment,
+		DecidedAt: e.DecidedAt,
+		RequestID: e.RequestID,
+		System:    e.System,
+	}
+}
+
+func (e *Execution) clone() *Execution {
+	c := *e
+	return &c
+}
+
+// ExecutionStore 执行记录存储, MemoryStore 和 GormStore 都实现了该接口
+type ExecutionStore interface {
+	// SaveExecution 新建或覆盖执行记录
+	SaveExecution(ctx context.Context, e *Execution) error
+	// ClaimExecution 抢占一次执行: 仅当记录为 pending, 执行次数为 e.Attempts-1 且 NextRetryAt <= due 时
+	// 写入 e 的 Attempts, NextRetryAt(租约到期时间) 和 UpdatedAt, 返回是否抢占成功;
+	// 同一记录同时只有一个 Runner 能抢占成功, 租约到期前不会被 ListDueExecutions 返回
+	ClaimExecution(ctx context.Context, e *Execution, due time.Time) (bool, error)
+	// FinishExecution 写入一次执行的结果: 仅当记录为 pending 且执行次数仍为 e.Attempts 时写入 e, 返回是否写入;
+	// 租约到期后被其他 Runner 重新抢占或已经结束的记录不会被覆盖
+	FinishExecution(ctx context.Context, e *Execution) (bool, error)
+	// GetExecution 查询执行记录, 不存在时返回 ErrExecutionNotFound
+	GetExecution(ctx context.Context, requestID string) (*Execution, error)
+	// ListDueExecutions 按 NextRetryAt 升序返回 now 时刻需要执行的 pending 记录, limit <= 0 表示不限制
+	ListDueExecutions(ctx context.Context, now time.Time, limit int) ([]*Execution, error)
+}
+
+// Backoff 重试间隔: Initial * Multiplier^(attempt-1), 不超过 Max
+type Backoff struct {
+	Initial     time.Duration
+	Max         time.Duration
+	Multiplier  float64 // < 1 视为 1
+	MaxAttempts int     // 包括第一次执行, <= 0 表示不限制
+}
+
+// DefaultBackoff 1s, 2s, 4s ... 最长 10 分钟, 共执行 10 次
+var DefaultBackoff = Backoff{Initial: time.Second, Max: 10 * time.Minute, Multiplier: 2, MaxAttempts: 10}
+
+// Delay 第 attempt 次执行失败后到下一次执行的间隔, attempt 从 1 开始
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := float64(b.Initial) * math.Pow(max(b.Multiplier, 1), float64(max(attempt-1, 0)))
+	if b.Max > 0 && d > float64(b.Max) {
+		return b.Max
+	}
+	return time.Duration(d)
+}
+
+// Exhausted 执行 attempts 次后是否不再重试
+func (b Backoff) Exhausted(attempts int) bool {
+	return b.MaxAttempts > 0 && attempts >= b.MaxAttempts
+}
+
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string { return e.err.Error() }
+func (e *nonRetryableError) Unwrap() error { return e.err }
+
+// NonRetryable 回调返回该错误时不再重试
+func NonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &nonRetryableError{err: err}
+}
+
+// IsRetryable 错误是否可以重试
+func IsRetryable(err error) bool {
+	var e *nonRetryableError
+	return !errors.As(err, &e)
+}
+
+type idempotencyKey struct{}
+
+// IdempotencyKey 由请求 ID 生成的幂等键, 同一请求的每次执行都相同
+func IdempotencyKey(requestID string) string {
+	return "approval:" + requestID
+}
+
+// WithIdempotencyKey 将幂等键保存到 ctx
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext 回调中获取幂等键, 用于去重外部副作用; 没有时返回空字符串
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
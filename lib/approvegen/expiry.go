@@ -0,0 +1,42 @@
+package approvegen
+
+import (
+	"time"
+)
+
+// ExpireAction 审批请求过期时 Sweeper 的处理方式
+type ExpireAction string
+
+const (
+	ExpireOnly    ExpireAction = "expire"  // 只标记为 expired, 不调用回调
+	ExpireReject  ExpireAction = "reject"  // 以系统的名义拒绝, 调用 XxxHookRejected
+	ExpireApprove ExpireAction = "approve" // 以系统的名义通过, 调用 XxxHookApproved
+)
+
+// SystemApprover 系统自动做出决定时 Decision.Approver 的值
+const SystemApprover = "system"
+
+// Expiry 审批请求的有效期, 由 @Approve(policy::ttl="24h"; onExpire="reject") 生成
+type Expiry struct {
+	TTL      time.Duration // 提交时 SubmitOptions.TTL 为 0 则使用该值
+	OnExpire ExpireAction  // 为空视为 ExpireOnly
+}
+
+// Decision 过期时自动做出的决定, ExpireOnly 时返回 false
+func (e Expiry) Decision() (Decision, bool) {
+	switch e.OnExpire {
+	case ExpireReject:
+		return Decision{Approved: false, Approver: SystemApprover, Comment: "expired", System: true}, true
+	case ExpireApprove:
+		return Decision{Approved: true, Approver: SystemApprover, Comment: "expired", System: true}, true
+	}
+	return Decision{}, false
+}
+
+// expiryOf 参数结构体实现 Expiry() Expiry 时返回其有效期
+func expiryOf(arg any) (Expiry, bool) {
+	if p, ok := arg.(interface{ Expiry() Expiry }); ok {
+		return p.Expiry(), true
+	}
+	return Expiry{}, false
+}
@@ -0,0 +1,177 @@
+package approvegen
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type createArgs struct {
+	Name string
+}
+
+func (p *createArgs) MethodName() string {
+	return "UserService_Create"
+}
+
+// policyArgs 模拟参数结构体上生成的 Policy()
+type policyArgs struct {
+	Name string
+}
+
+func (p *policyArgs) MethodName() string {
+	return "UserService_Grant"
+}
+
+func (p *policyArgs) Policy() Policy {
+	return Policy{Quorum: 3}
+}
+
+type jobArgs struct {
+	Name    string
+	NoRetry bool
+}
+
+func (p *jobArgs) MethodName() string {
+	return "Job_Run"
+}
+
+// Retryable 模拟 exec::noRetry 生成的方法
+func (p *jobArgs) Retryable() bool {
+	return !p.NoRetry
+}
+
+// expiringArgs 模拟 policy::ttl="1h"; onExpire=Action 生成的 Expiry()
+type expiringArgs struct {
+	Name   string
+	Action ExpireAction
+}
+
+func (p *expiringArgs) MethodName() string {
+	return "UserService_Expire"
+}
+
+func (p *expiringArgs) Expiry() Expiry {
+	return Expiry{TTL: time.Hour, OnExpire: p.Action}
+}
+
+// fakeCaller 测试共用的 Caller, 支持以上所有参数结构体对应的方法;
+// 记录每次回调的审批结果和幂等键, errs 中的错误依次作为回调的返回值, 用完后返回参数的 Name
+type fakeCaller struct {
+	mu        sync.Mutex
+	calls     []bool
+	decisions []Decision // 从 ctx 中获取的审批结果
+	keys      []string   // 从 ctx 中获取的幂等键
+	errs      []error
+	codec     Codec   // 为 nil 时使用 JSON
+	policy    *Policy // 不为 nil 时模拟 policy::quorum 生成的 Policy(arg)
+	onCall    func()  // 每次回调时调用, 用于模拟执行中的并发操作
+}
+
+func (f *fakeCaller) Call(ctx context.Context, arg any, approved bool) (any, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, approved)
+	f.decisions = append(f.decisions, DecisionFromContext(ctx))
+	f.keys = append(f.keys, IdempotencyKeyFromContext(ctx))
+	var err error
+	if len(f.errs) > 0 {
+		err, f.errs = f.errs[0], f.errs[1:]
+	}
+	onCall := f.onCall
+	f.mu.Unlock()
+
+	if onCall != nil {
+		onCall()
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch p := arg.(type) {
+	case *createArgs:
+		return p.Name, nil
+	case *policyArgs:
+		return p.Name, nil
+	case *jobArgs:
+		return p.Name, nil
+	case *expiringArgs:
+		return p.Name, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeCaller) Format(ctx context.Context, arg any) (any, error) {
+	return nil, nil
+}
+
+func (f *fakeCaller) Methods() []string {
+	return []string{"Job_Run", "UserService_Create", "UserService_Expire", "UserService_Grant"}
+}
+
+func (f *fakeCaller) Policy(arg any) (Policy, bool) {
+	if f.policy == nil {
+		return Policy{}, false
+	}
+	return *f.policy, true
+}
+
+func (f *fakeCaller) UnmarshalMethodArgs(method string, content string) (any, error) {
+	var arg any
+	switch method {
+	case "UserService_Create":
+		arg = &createArgs{}
+	case "UserService_Grant":
+		arg = &policyArgs{}
+	case "Job_Run":
+		arg = &jobArgs{}
+	case "UserService_Expire":
+		arg = &expiringArgs{}
+	default:
+		return nil, nil
+	}
+	codec := f.codec
+	if codec == nil {
+		codec = JSON
+	}
+	if err := codec.Unmarshal([]byte(content), arg); err != nil {
+		return nil, err
+	}
+	return arg, nil
+}
+
+// testStore 测试中同时需要查询请求和执行记录
+type testStore interface {
+	Store
+	ExecutionStore
+}
+
+// testEnv 共用 fakeCaller 和时钟的 Workflow, Runner 与 Sweeper
+type testEnv struct {
+	w      *Workflow
+	runner *Runner
+	store  testStore
+	caller *fakeCaller
+	now    *time.Time
+}
+
+// newTestEnv 创建测试环境, store 为 nil 时使用 MemoryStore; Workflow 默认直接调用回调, withRunner 后通过 Runner 执行
+func newTestEnv(store testStore) *testEnv {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	env := &testEnv{store: store, caller: &fakeCaller{}, now: new(time.Time)}
+	*env.now = testNow
+	clock := func() time.Time { return *env.now }
+
+	env.runner = NewRunner(store, Callers{env.caller}).WithBackoff(Backoff{Initial: time.Minute, Max: 3 * time.Minute, Multiplier: 2, MaxAttempts: 4})
+	env.runner.now = clock
+	env.w = NewWorkflow(store, Callers{env.caller})
+	env.w.now = clock
+	return env
+}
+
+func (env *testEnv) withRunner() *testEnv {
+	env.w.WithRunner(env.runner)
+	return env
+}
@@ -0,0 +1,49 @@
+package approvegen
+
+import (
+	"context"
+	"strings"
+)
+
+type localeKey struct{}
+
+// WithLocale 保存审批人使用的语言, Formatter 中通过 LocaleFromContext 获取
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext 获取 WithLocale 保存的语言, 没有时返回空字符串(默认语言)
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// LocalizedNote 参数结构体指定语言的 Note(); 没有 args::note[locale] 时使用 Note(), 都没有时返回空字符串
+func LocalizedNote(arg any, locale string) string {
+	switch p := arg.(type) {
+	case interface{ NoteFor(string) string }:
+		return p.NoteFor(locale)
+	case interface{ Note() string }:
+		return p.Note()
+	}
+	return ""
+}
+
+// LocalizedString 参数结构体指定语言的 String(); 没有 args::string[locale] 时使用 String(), 都没有时返回空字符串
+func LocalizedString(arg any, locale string) string {
+	switch p := arg.(type) {
+	case interface{ StringFor(string) string }:
+		return p.StringFor(locale)
+	case interface{ String() string }:
+		return p.String()
+	}
+	return ""
+}
+
+// parentLocale 去掉最后一段, zh-Hant-TW ==> zh-Hant ==> zh ==> ""
+func parentLocale(locale string) string {
+	if i := strings.LastIndexAny(locale, "-_"); i > 0 {
+		return locale[:i]
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package approvegen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type localizedArg struct{}
+
+func (localizedArg) Note() string   { return "退款" }
+func (localizedArg) String() string { return "金额=1" }
+
+func (a localizedArg) NoteFor(locale string) string {
+	if locale == "en" {
+		return "Refund"
+	}
+	return a.Note()
+}
+
+type plainArg struct{}
+
+func (plainArg) Note() string { return "取消" }
+
+func TestLocalized(t *testing.T) {
+	ctx := WithLocale(context.Background(), "en")
+	assert.Equal(t, "en", LocaleFromContext(ctx))
+	assert.Equal(t, "", LocaleFromContext(context.Background()))
+
+	assert.Equal(t, "Refund", LocalizedNote(localizedArg{}, LocaleFromContext(ctx)))
+	assert.Equal(t, "退款", LocalizedNote(localizedArg{}, "zh"))
+	assert.Equal(t, "金额=1", LocalizedString(localizedArg{}, "en"))
+	assert.Equal(t, "取消", LocalizedNote(plainArg{}, "en"))
+	assert.Equal(t, "", LocalizedString(plainArg{}, "en"))
+}
+
+func TestCatalogEntryNoteFor(t *testing.T) {
+	e := CatalogEntry{Note: "退款", Notes: map[string]string{"en": "Refund", "zh-TW": "退款申請"}}
+	assert.Equal(t, "Refund", e.NoteFor("en-US"))
+	assert.Equal(t, "退款申請", e.NoteFor("zh-TW-x"))
+	assert.Equal(t, "退款", e.NoteFor("zh-CN"))
+	assert.Equal(t, "退款", e.NoteFor(""))
+}
@@ -7,6 +7,8 @@ import (
 )
 
 var ErrUnknownMethod = errors.New("ErrUnknownMethod")
+
+// ErrUnsupportedArgType 参数没有对应的格式化方法, 导入了 lib/approvegen 的生成代码会返回该错误, 通过 errors.Is 判断
 var ErrUnsupportedArgType = errors.New("UnsupportedArgType")
 
 type Caller interface {
@@ -17,12 +19,27 @@ type Caller interface {
 
 type Callers []Caller
 
+// Call 只传递是否通过, 等同于 CallDecision(ctx, method, content, Decision{Approved: approved})
 func (c Callers) Call(ctx context.Context, method, content string, approved bool) (any, error) {
+	return c.CallDecision(ctx, method, content, Decision{Approved: approved})
+}
+
+// CallDecision 调用审批回调, d 同时保存到 ctx 中;
+// caller 没有实现 DecisionCaller 时退化为 Caller.Call(ctx, arg, d.Approved)
+func (c Callers) CallDecision(ctx context.Context, method, content string, d Decision) (any, error) {
 	arg, caller, err := c.UnmarshalMethodArgs(method, content)
 	if err != nil {
 		return nil, err
 	}
-	return caller.Call(ctx, arg, approved)
+	return callDecision(ctx, caller, arg, d)
+}
+
+func callDecision(ctx context.Context, caller Caller, arg any, d Decision) (any, error) {
+	ctx = WithDecision(ctx, d)
+	if dc, ok := caller.(DecisionCaller); ok {
+		return dc.CallDecision(ctx, arg, d)
+	}
+	return caller.Call(ctx, arg, d.Approved)
 }
 
 func (c Callers) Format(ctx context.Context, method, content string) (any, error) {
@@ -30,14 +47,7 @@ func (c Callers) Format(ctx context.Context, method, content string) (any, error
 	if err != nil {
 		return nil, err
 	}
-	ret, err := caller.Format(ctx, arg)
-	if err != nil {
-		if err.Error() == ErrUnsupportedArgType.Error() {
-			return nil, ErrUnsupportedArgType
-		}
-		return nil, err
-	}
-	return ret, nil
+	return caller.Format(ctx, arg)
 }
 
 func (c Callers) UnmarshalMethodArgs(method string, content string) (arg any, _ Caller, err error) {
@@ -51,5 +61,5 @@ func (c Callers) UnmarshalMethodArgs(method string, content string) (arg any, _
 		}
 		return arg, group, nil
 	}
-	return nil, nil, errors.Join(ErrUnknownMethod, fmt.Errorf("unable to unmarshal method: %s", method))
+	return nil, nil, fmt.Errorf("%w: unable to unmarshal method: %s", ErrUnknownMethod, method)
 }
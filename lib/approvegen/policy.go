@@ -0,0 +1,85 @@
+package approvegen
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrRoleNotAllowed 审批人的角色不在 Policy.Roles 中
+var ErrRoleNotAllowed = errors.New("ErrRoleNotAllowed")
+
+// ErrQuorumNotReached 审批已记录, 但还没有达到 Policy.Quorum, 请求保持 pending
+var ErrQuorumNotReached = errors.New("ErrQuorumNotReached")
+
+// Policy 审批策略, 由 @Approve(policy::quorum=2; roles="finance,admin"; distinct=true) 生成
+type Policy struct {
+	Quorum   int      // 需要的审批通过次数, <= 0 视为 1
+	Roles    []string // 允许审批的角色, 为空表示不限制
+	Distinct bool     // 同一审批人只计一次
+}
+
+// DefaultPolicy 未声明策略的方法: 任意一人审批即可
+var DefaultPolicy = Policy{Quorum: 1}
+
+// Approval 一次审批通过的记录
+type Approval struct {
+	Approver string
+	Role     string
+	At       time.Time
+}
+
+// PolicyCaller 可选接口, 生成的 ApprovalCaller 在声明了 policy::quorum 时实现
+type PolicyCaller interface {
+	Policy(arg any) (Policy, bool)
+}
+
+// AllowsRole 角色是否可以参与审批
+func (p Policy) AllowsRole(role string) bool {
+	return len(p.Roles) == 0 || slices.Contains(p.Roles, role)
+}
+
+// Count 计算有效的审批次数
+func (p Policy) Count(approvals []Approval) int {
+	seen := make(map[string]struct{}, len(approvals))
+	count := 0
+	for _, a := range approvals {
+		if !p.AllowsRole(a.Role) {
+			continue
+		}
+		if p.Distinct {
+			if _, ok := seen[a.Approver]; ok {
+				continue
+			}
+			seen[a.Approver] = struct{}{}
+		}
+		count++
+	}
+	return count
+}
+
+// Satisfied 审批次数是否已经达到 Quorum
+func (p Policy) Satisfied(approvals []Approval) bool {
+	return p.Count(approvals) >= max(p.Quorum, 1)
+}
+
+// Policy 返回方法的审批策略, 没有声明时返回 DefaultPolicy
+func (c Callers) Policy(method, content string) (Policy, error) {
+	arg, caller, err := c.UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return Policy{}, err
+	}
+	return policyOf(caller, arg), nil
+}
+
+func policyOf(caller Caller, arg any) Policy {
+	if pc, ok := caller.(PolicyCaller); ok {
+		if policy, ok := pc.Policy(arg); ok {
+			return policy
+		}
+	}
+	if p, ok := arg.(interface{ Policy() Policy }); ok {
+		return p.Policy()
+	}
+	return DefaultPolicy
+}
@@ -0,0 +1,35 @@
+package approvegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicySatisfied(t *testing.T) {
+	p := Policy{Quorum: 2, Roles: []string{"finance", "admin"}, Distinct: true}
+	assert.False(t, p.Satisfied([]Approval{{Approver: "a", Role: "finance"}, {Approver: "a", Role: "finance"}}))
+	assert.False(t, p.Satisfied([]Approval{{Approver: "a", Role: "finance"}, {Approver: "b", Role: "sales"}}))
+	assert.True(t, p.Satisfied([]Approval{{Approver: "a", Role: "finance"}, {Approver: "b", Role: "admin"}}))
+
+	p.Distinct = false
+	assert.True(t, p.Satisfied([]Approval{{Approver: "a", Role: "finance"}, {Approver: "a", Role: "finance"}}))
+
+	assert.True(t, Policy{}.Satisfied([]Approval{{Approver: "a"}}))
+	assert.False(t, DefaultPolicy.Satisfied(nil))
+}
+
+func TestCallersPolicy(t *testing.T) {
+	policy, err := Callers{&fakeCaller{}}.Policy("UserService_Create", `{"Name":"bob"}`)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPolicy, policy)
+
+	// 参数结构体上的 Policy()
+	policy, err = Callers{&fakeCaller{}}.Policy("UserService_Grant", `{"Name":"bob"}`)
+	require.NoError(t, err)
+	assert.Equal(t, 3, policy.Quorum)
+
+	_, err = Callers{&fakeCaller{}}.Policy("Unknown", `{}`)
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+}
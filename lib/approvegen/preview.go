@@ -0,0 +1,71 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoPreview 方法没有 func::preview 注释, 或者 target 没有实现 XxxPreview
+var ErrNoPreview = errors.New("ErrNoPreview")
+
+// Change 审批通过后一个值的变化, 例如余额从 100 变为 80
+type Change struct {
+	Field  string `json:"field"`
+	Label  string `json:"label,omitempty"` // 展示名称, 为空时使用 Field
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// Preview XxxPreview 返回的审批效果预览, 审批人在通过前查看
+type Preview struct {
+	Summary string   `json:"summary,omitempty"`
+	Changes []Change `json:"changes"`
+}
+
+// Previewer 可选接口, 有 func::preview 方法时生成的 ApprovalCaller 实现
+type Previewer interface {
+	Preview(ctx context.Context, arg any) (*Preview, error)
+}
+
+// String 与 args::string 的输出格式相同, 例如: 提现; 余额: 100 -> 80
+func (p *Preview) String() string {
+	ss := make([]string, 0, len(p.Changes))
+	for _, c := range p.Changes {
+		label := c.Label
+		if label == "" {
+			label = c.Field
+		}
+		ss = append(ss, fmt.Sprintf("%s: %v -> %v", label, c.Before, c.After))
+	}
+	if p.Summary == "" {
+		return strings.Join(ss, ", ")
+	}
+	if len(ss) == 0 {
+		return p.Summary
+	}
+	return p.Summary + "; " + strings.Join(ss, ", ")
+}
+
+// Json 与 args::json 相同, 由调用方序列化
+func (p *Preview) Json() (any, error) {
+	return p, nil
+}
+
+// Preview 调用方法的 XxxPreview; caller 没有实现 Previewer 时返回 ErrNoPreview
+func (c Callers) Preview(ctx context.Context, method, content string) (*Preview, error) {
+	arg, caller, err := c.UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return nil, err
+	}
+	return previewOf(ctx, caller, arg)
+}
+
+func previewOf(ctx context.Context, caller Caller, arg any) (*Preview, error) {
+	p, ok := caller.(Previewer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not implement Previewer", ErrNoPreview, caller)
+	}
+	return p.Preview(ctx, arg)
+}
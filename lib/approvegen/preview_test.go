@@ -0,0 +1,76 @@
+package approvegen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// previewCaller 在 fakeCaller 的基础上实现 Previewer
+type previewCaller struct {
+	fakeCaller
+}
+
+func (p *previewCaller) Preview(ctx context.Context, arg any) (*Preview, error) {
+	return &Preview{
+		Summary: "创建用户",
+		Changes: []Change{{Field: "name", Label: "名称", Before: "", After: arg.(*createArgs).Name}},
+	}, nil
+}
+
+func TestPreviewString(t *testing.T) {
+	p := &Preview{
+		Summary: "退款",
+		Changes: []Change{
+			{Field: "balance", Label: "余额", Before: 100, After: 80},
+			{Field: "status", Before: "paid", After: "refunded"},
+		},
+	}
+	assert.Equal(t, "退款; 余额: 100 -> 80, status: paid -> refunded", p.String())
+	assert.Equal(t, "退款", (&Preview{Summary: "退款"}).String())
+	assert.Equal(t, "status: paid -> refunded", (&Preview{Changes: p.Changes[1:]}).String())
+
+	v, err := p.Json()
+	require.NoError(t, err)
+	data, err := JSON.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"summary":"退款","changes":[{"field":"balance","label":"余额","before":100,"after":80},{"field":"status","before":"paid","after":"refunded"}]}`, string(data))
+}
+
+func TestCallersPreview(t *testing.T) {
+	ctx := context.Background()
+	callers := Callers{&previewCaller{}, deleteCaller{}}
+
+	p, err := callers.Preview(ctx, "UserService_Create", `{"Name":"alice"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "创建用户; 名称:  -> alice", p.String())
+
+	_, err = callers.Preview(ctx, "UserService_Delete", `{"ID":1}`)
+	assert.ErrorIs(t, err, ErrNoPreview)
+	assert.ErrorContains(t, err, "approvegen.deleteCaller does not implement Previewer")
+
+	_, err = callers.Preview(ctx, "UserService_Unknown", `{}`)
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+}
+
+func TestRegistryPreview(t *testing.T) {
+	ctx := context.Background()
+	r := NewRegistry()
+	require.NoError(t, r.Register(&previewCaller{}, deleteCaller{}))
+
+	var ops []Op
+	r.Use(func(ctx context.Context, inv *Invocation, next Handler) (any, error) {
+		ops = append(ops, inv.Op)
+		return next(ctx, inv)
+	})
+
+	p, err := r.Preview(ctx, "UserService_Create", `{"Name":"alice"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Field: "name", Label: "名称", Before: "", After: "alice"}}, p.Changes)
+
+	_, err = r.Preview(ctx, "UserService_Delete", `{"ID":1}`)
+	assert.ErrorIs(t, err, ErrNoPreview)
+	assert.Equal(t, []Op{OpPreview, OpPreview}, ops)
+}
@@ -0,0 +1,116 @@
+package approvegen
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// RedactMode 敏感字段的脱敏方式, 对应 @Approve(args::field="x"; redact="mask")
+type RedactMode string
+
+const (
+	RedactMask RedactMode = "mask" // 只保留末尾几位, 例如 ************7890
+	RedactHash RedactMode = "hash" // HMAC-SHA256 摘要, 可以比较两个值是否相同但无法还原, 密钥由 SetRedactKey 设置
+	RedactOmit RedactMode = "omit" // 不输出
+)
+
+// Valid 是否为支持的脱敏方式
+func (m RedactMode) Valid() bool {
+	switch m {
+	case RedactMask, RedactHash, RedactOmit:
+		return true
+	}
+	return false
+}
+
+// redactKey RedactHash 使用的 HMAC 密钥, 默认为进程启动时生成的随机密钥
+var redactKey atomic.Pointer[[]byte]
+
+func init() {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	redactKey.Store(&key)
+}
+
+// SetRedactKey 设置 RedactHash 使用的 HMAC 密钥
+// 没有设置时使用随机密钥, 摘要只在当前进程内可以比较; 多个实例或重启后需要比较摘要时, 在启动时设置相同的密钥
+func SetRedactKey(key []byte) {
+	if len(key) == 0 {
+		panic("approvegen: empty redact key")
+	}
+	key = slices.Clone(key)
+	redactKey.Store(&key)
+}
+
+// Redact 对字符串脱敏, 生成的 Redacted() 使用; 存储和执行使用的参数不受影响
+func Redact(s string, mode RedactMode) string {
+	switch mode {
+	case RedactHash:
+		if s == "" {
+			return ""
+		}
+		// 使用带密钥的 HMAC, 低熵的值(密码、验证码等)无法通过穷举还原
+		h := hmac.New(sha256.New, *redactKey.Load())
+		h.Write([]byte(s))
+		return "hmac:" + hex.EncodeToString(h.Sum(nil)[:8])
+	case RedactOmit:
+		return ""
+	default:
+		return mask(s)
+	}
+}
+
+// mask 长度大于 8 时保留末尾 4 个字符, 否则全部替换
+func mask(s string) string {
+	n := utf8.RuneCountInString(s)
+	keep := 0
+	if n > 8 {
+		keep = 4
+	}
+	runes := []rune(s)
+	return strings.Repeat("*", n-keep) + string(runes[n-keep:])
+}
+
+// OmitKeys 删除 JSON 对象中的顶层字段并保持其余字段的顺序, 生成的 Json() 用于去掉 redact="omit" 的字段
+func OmitKeys(data []byte, keys ...string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, errors.New("approvegen: OmitKeys requires a JSON object")
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		if slices.Contains(keys, key) {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,48 @@
+package approvegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "************7890", Redact("6222021234567890", RedactMask))
+	assert.Equal(t, "******", Redact("secret", RedactMask))
+	assert.Equal(t, "*********四五六七", Redact("甲乙丙丁戊己庚辛壬四五六七", RedactMask))
+	assert.Equal(t, "", Redact("", RedactMask))
+
+	h := Redact("sk-123", RedactHash)
+	assert.Len(t, h, len("hmac:")+16)
+	assert.Equal(t, h, Redact("sk-123", RedactHash))
+	assert.NotEqual(t, h, Redact("sk-124", RedactHash))
+	assert.Equal(t, "", Redact("", RedactHash))
+
+	// 摘要取决于密钥, 不知道密钥时无法通过穷举还原
+	old := *redactKey.Load()
+	t.Cleanup(func() { SetRedactKey(old) })
+	SetRedactKey([]byte("key-1"))
+	h1 := Redact("sk-123", RedactHash)
+	assert.NotEqual(t, h, h1)
+	SetRedactKey([]byte("key-2"))
+	assert.NotEqual(t, h1, Redact("sk-123", RedactHash))
+	SetRedactKey([]byte("key-1"))
+	assert.Equal(t, h1, Redact("sk-123", RedactHash))
+
+	assert.Equal(t, "", Redact("sk-123", RedactOmit))
+	assert.True(t, RedactMask.Valid())
+	assert.False(t, RedactMode("plain").Valid())
+}
+
+func TestOmitKeys(t *testing.T) {
+	bs, err := OmitKeys([]byte(`{"Owner":"tom","Password":"","Memo":{"a":1}}`), "Password")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"Owner":"tom","Memo":{"a":1}}`, string(bs))
+
+	bs, err = OmitKeys([]byte(`{"Password":"x"}`), "Password")
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(bs))
+
+	_, err = OmitKeys([]byte(`[1]`), "Password")
+	assert.Error(t, err)
+}
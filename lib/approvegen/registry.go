@@ -0,0 +1,191 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+)
+
+var ErrDuplicateMethod = errors.New("ErrDuplicateMethod")
+var ErrUnindexedCaller = errors.New("ErrUnindexedCaller")
+var ErrCallerPanic = errors.New("ErrCallerPanic")
+
+// MethodLister 生成的 ApprovalCaller 实现, 返回支持的 MethodName()
+type MethodLister interface {
+	Methods() []string
+}
+
+// Op 拦截的操作
+type Op string
+
+const (
+	OpCall    Op = "call"
+	OpFormat  Op = "format"
+	OpPreview Op = "preview"
+)
+
+// Invocation 一次 Call, Format 或 Preview
+type Invocation struct {
+	Op       Op
+	Method   string
+	Arg      any
+	Caller   Caller
+	Decision Decision // 只有 OpCall 有值
+}
+
+// Handler 执行 Invocation
+type Handler func(ctx context.Context, inv *Invocation) (any, error)
+
+// Interceptor 包装 Call, Format 和 Preview, 用于日志、指标、链路追踪等; 调用 next 继续执行
+type Interceptor func(ctx context.Context, inv *Invocation, next Handler) (any, error)
+
+// Registry 在注册时按方法名索引 Caller, 同一方法名只能属于一个 Caller;
+// 与 Callers 的区别: 查找不需要逐个尝试 UnmarshalMethodArgs, 并支持 Interceptor
+type Registry struct {
+	callers      map[string]Caller
+	interceptors []Interceptor
+}
+
+func NewRegistry() *Registry {
+	return &Registry{callers: make(map[string]Caller)}
+}
+
+// Register 注册 Caller, Caller 需要实现 MethodLister; 方法名重复时返回 ErrDuplicateMethod, 不会注册任何方法
+func (r *Registry) Register(callers ...Caller) error {
+	added := make(map[string]Caller)
+	for _, caller := range callers {
+		lister, ok := caller.(MethodLister)
+		if !ok {
+			return fmt.Errorf("%w: %T does not implement Methods()", ErrUnindexedCaller, caller)
+		}
+		for _, method := range lister.Methods() {
+			if _, ok := r.callers[method]; ok {
+				return fmt.Errorf("%w: %s", ErrDuplicateMethod, method)
+			}
+			if _, ok := added[method]; ok {
+				return fmt.Errorf("%w: %s", ErrDuplicateMethod, method)
+			}
+			added[method] = caller
+		}
+	}
+	for method, caller := range added {
+		r.callers[method] = caller
+	}
+	return nil
+}
+
+// Use 追加 Interceptor, 先添加的在外层
+func (r *Registry) Use(interceptors ...Interceptor) *Registry {
+	r.interceptors = append(r.interceptors, interceptors...)
+	return r
+}
+
+// Lookup 查找方法所属的 Caller
+func (r *Registry) Lookup(method string) (Caller, bool) {
+	caller, ok := r.callers[method]
+	return caller, ok
+}
+
+// Methods 所有已注册的方法名, 按字母排序
+func (r *Registry) Methods() []string {
+	ret := make([]string, 0, len(r.callers))
+	for method := range r.callers {
+		ret = append(ret, method)
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+func (r *Registry) UnmarshalMethodArgs(method string, content string) (any, Caller, error) {
+	caller, ok := r.callers[method]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownMethod, method)
+	}
+	arg, err := caller.UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if arg == nil {
+		return nil, nil, fmt.Errorf("%w: %T does not unmarshal %s", ErrUnknownMethod, caller, method)
+	}
+	return arg, caller, nil
+}
+
+// Call 只传递是否通过, 等同于 CallDecision(ctx, method, content, Decision{Approved: approved})
+func (r *Registry) Call(ctx context.Context, method, content string, approved bool) (any, error) {
+	return r.CallDecision(ctx, method, content, Decision{Approved: approved})
+}
+
+// CallDecision 与 Callers.CallDecision 相同, 经过 Interceptor
+func (r *Registry) CallDecision(ctx context.Context, method, content string, d Decision) (any, error) {
+	arg, caller, err := r.UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return nil, err
+	}
+	inv := &Invocation{Op: OpCall, Method: method, Arg: arg, Caller: caller, Decision: d}
+	return r.invoke(ctx, inv, func(ctx context.Context, inv *Invocation) (any, error) {
+		return callDecision(ctx, inv.Caller, inv.Arg, inv.Decision)
+	})
+}
+
+// Format 与 Callers.Format 相同, 经过 Interceptor
+func (r *Registry) Format(ctx context.Context, method, content string) (any, error) {
+	arg, caller, err := r.UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return nil, err
+	}
+	inv := &Invocation{Op: OpFormat, Method: method, Arg: arg, Caller: caller}
+	return r.invoke(ctx, inv, func(ctx context.Context, inv *Invocation) (any, error) {
+		return inv.Caller.Format(ctx, inv.Arg)
+	})
+}
+
+// Preview 与 Callers.Preview 相同, 经过 Interceptor
+func (r *Registry) Preview(ctx context.Context, method, content string) (*Preview, error) {
+	arg, caller, err := r.UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return nil, err
+	}
+	inv := &Invocation{Op: OpPreview, Method: method, Arg: arg, Caller: caller}
+	ret, err := r.invoke(ctx, inv, func(ctx context.Context, inv *Invocation) (any, error) {
+		return previewOf(ctx, inv.Caller, inv.Arg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	p, _ := ret.(*Preview)
+	return p, nil
+}
+
+// Policy 与 Callers.Policy 相同
+func (r *Registry) Policy(method, content string) (Policy, error) {
+	arg, caller, err := r.UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return Policy{}, err
+	}
+	return policyOf(caller, arg), nil
+}
+
+func (r *Registry) invoke(ctx context.Context, inv *Invocation, h Handler) (any, error) {
+	for i := len(r.interceptors) - 1; i >= 0; i-- {
+		next, interceptor := h, r.interceptors[i]
+		h = func(ctx context.Context, inv *Invocation) (any, error) {
+			return interceptor(ctx, inv, next)
+		}
+	}
+	return h(ctx, inv)
+}
+
+// Recover 将 Call/Format/Preview 中的 panic 转换为 ErrCallerPanic
+func Recover() Interceptor {
+	return func(ctx context.Context, inv *Invocation, next Handler) (ret any, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				ret, err = nil, fmt.Errorf("%w: %s %s: %v\n%s", ErrCallerPanic, inv.Op, inv.Method, v, debug.Stack())
+			}
+		}()
+		return next(ctx, inv)
+	}
+}
@@ -0,0 +1,122 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deleteArgs struct {
+	ID int64
+}
+
+func (p *deleteArgs) MethodName() string {
+	return "UserService_Delete"
+}
+
+// deleteCaller Format 不支持任何参数, Call 时 panic
+type deleteCaller struct{}
+
+func (deleteCaller) Call(ctx context.Context, arg any, approved bool) (any, error) {
+	panic("boom")
+}
+
+func (deleteCaller) Format(ctx context.Context, arg any) (any, error) {
+	return nil, fmt.Errorf("%w: unsupported arg type %T", ErrUnsupportedArgType, arg)
+}
+
+func (deleteCaller) UnmarshalMethodArgs(method string, content string) (any, error) {
+	if method != "UserService_Delete" {
+		return nil, nil
+	}
+	var p deleteArgs
+	if err := JSON.Unmarshal([]byte(content), &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (deleteCaller) Methods() []string {
+	return []string{"UserService_Delete"}
+}
+
+// unindexedCaller 没有实现 MethodLister
+type unindexedCaller struct{ Caller }
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(&fakeCaller{}, deleteCaller{}))
+	assert.Equal(t, []string{"Job_Run", "UserService_Create", "UserService_Delete", "UserService_Expire", "UserService_Grant"}, r.Methods())
+
+	caller, ok := r.Lookup("UserService_Delete")
+	assert.True(t, ok)
+	assert.Equal(t, deleteCaller{}, caller)
+
+	err := r.Register(&fakeCaller{})
+	assert.ErrorIs(t, err, ErrDuplicateMethod)
+	assert.ErrorContains(t, err, "Job_Run")
+
+	// 同一次注册中的重复方法, 不会注册任何方法
+	r = NewRegistry()
+	assert.ErrorIs(t, r.Register(deleteCaller{}, deleteCaller{}), ErrDuplicateMethod)
+	assert.Empty(t, r.Methods())
+
+	assert.ErrorIs(t, r.Register(unindexedCaller{deleteCaller{}}), ErrUnindexedCaller)
+}
+
+func TestRegistryDispatch(t *testing.T) {
+	ctx := context.Background()
+	caller := &fakeCaller{}
+	r := NewRegistry()
+	require.NoError(t, r.Register(caller, deleteCaller{}))
+
+	var trace []string
+	r.Use(func(ctx context.Context, inv *Invocation, next Handler) (any, error) {
+		trace = append(trace, "outer:"+string(inv.Op)+":"+inv.Method)
+		return next(ctx, inv)
+	}, func(ctx context.Context, inv *Invocation, next Handler) (any, error) {
+		trace = append(trace, "inner")
+		return next(ctx, inv)
+	}, Recover())
+
+	ret, err := r.CallDecision(ctx, "UserService_Create", `{"Name":"bob"}`, Decision{Approved: true, Approver: "amy"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", ret)
+	assert.Equal(t, "amy", caller.decisions[0].Approver)
+	assert.Equal(t, []string{"outer:call:UserService_Create", "inner"}, trace)
+
+	_, err = r.Format(ctx, "UserService_Delete", `{"ID":1}`)
+	assert.ErrorIs(t, err, ErrUnsupportedArgType)
+
+	_, err = r.Call(ctx, "UserService_Delete", `{"ID":1}`, true)
+	assert.ErrorIs(t, err, ErrCallerPanic)
+	assert.ErrorContains(t, err, "boom")
+
+	_, err = r.Call(ctx, "UserService_Update", `{}`, true)
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+
+	policy, err := r.Policy("UserService_Create", `{}`)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPolicy, policy)
+}
+
+func TestWorkflowWithRegistry(t *testing.T) {
+	ctx := context.Background()
+	caller := &fakeCaller{}
+	r := NewRegistry()
+	require.NoError(t, r.Register(caller))
+	w := NewWorkflow(NewMemoryStore(), r)
+
+	_, err := w.Submit(ctx, &deleteArgs{ID: 1}, SubmitOptions{})
+	assert.True(t, errors.Is(err, ErrUnknownMethod))
+
+	req, err := w.Submit(ctx, &createArgs{Name: "bob"}, SubmitOptions{})
+	require.NoError(t, err)
+	ret, err := w.Approve(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", ret)
+}
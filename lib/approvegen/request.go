@@ -0,0 +1,62 @@
+package approvegen
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrRequestNotFound = errors.New("ErrRequestNotFound")
+var ErrRequestExpired = errors.New("ErrRequestExpired")
+var ErrInvalidTransition = errors.New("ErrInvalidTransition")
+
+// Status 审批请求的状态
+//
+//	pending ──> approved
+//	        ├─> rejected
+//	        ├─> expired
+//	        └─> cancelled
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusApproved  Status = "approved"
+	StatusRejected  Status = "rejected"
+	StatusExpired   Status = "expired"
+	StatusCancelled Status = "cancelled"
+)
+
+// IsFinal 是否为终态, 终态不能再转换
+func (s Status) IsFinal() bool {
+	switch s {
+	case StatusApproved, StatusRejected, StatusExpired, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo 状态机: 只有 pending 可以转换到任意终态
+func (s Status) CanTransitionTo(to Status) bool {
+	return s == StatusPending && to.IsFinal()
+}
+
+// Request 待审批请求
+type Request struct {
+	ID        string
+	Method    string // 生成代码中的 MethodName()
+	Args      string // 序列化后的方法参数, 格式由 Workflow 的 Codec 决定, 可能是二进制
+	Requester string
+	Status    Status
+	CreatedAt time.Time
+	ExpiresAt time.Time // 零值表示永不过期
+	DecidedAt time.Time // 进入终态的时间
+}
+
+// ExpiredAt 在 now 时刻是否已过期
+func (r *Request) ExpiredAt(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+func (r *Request) clone() *Request {
+	c := *r
+	return &c
+}
@@ -0,0 +1,183 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultLease 默认的执行租约, 回调执行超过租约后记录可以被其他 Runner 重新执行
+const DefaultLease = 5 * time.Minute
+
+// Runner 执行审批通过/拒绝后的回调: 执行前写入 Execution, 失败时按 Backoff 安排重试;
+// 每次执行前先抢占记录并将 NextRetryAt 推迟一个租约, 多个 Runner 并发时同一记录只会被执行一次;
+// 回调的 ctx 中带有 IdempotencyKey, 参数实现 Retryable() bool 且返回 false 时(exec::noRetry)失败后不再重试
+type Runner struct {
+	store   ExecutionStore
+	callers Dispatcher
+	backoff Backoff
+	lease   time.Duration
+	now     func() time.Time
+}
+
+func NewRunner(store ExecutionStore, callers Dispatcher) *Runner {
+	return &Runner{store: store, callers: callers, backoff: DefaultBackoff, lease: DefaultLease, now: time.Now}
+}
+
+// WithBackoff 设置重试间隔, 默认为 DefaultBackoff
+func (r *Runner) WithBackoff(b Backoff) *Runner {
+	r.backoff = b
+	return r
+}
+
+// WithLease 设置执行租约, 需要大于回调的最长执行时间, 默认为 DefaultLease
+func (r *Runner) WithLease(d time.Duration) *Runner {
+	r.lease = d
+	return r
+}
+
+// Run 记录并第一次执行 req 的回调, 返回回调的结果
+func (r *Runner) Run(ctx context.Context, req *Request, d Decision) (any, error) {
+	e := r.newExecution(req, d)
+	if err := r.store.SaveExecution(ctx, e); err != nil {
+		return nil, err
+	}
+	return r.execute(ctx, e)
+}
+
+// newExecution 创建已抢占第一次执行的记录, NextRetryAt 为租约到期时间:
+// 第一次执行期间不会被 RetryDue 执行, 执行前进程退出时租约到期后由 RetryDue 重新执行
+func (r *Runner) newExecution(req *Request, d Decision) *Execution {
+	now := r.now()
+	return &Execution{
+		RequestID:   req.ID,
+		Method:      req.Method,
+		Args:        req.Args,
+		Approved:    d.Approved,
+		Approver:    d.Approver,
+		Role:        d.Role,
+		Comment:     d.Comment,
+		System:      d.System,
+		DecidedAt:   d.DecidedAt,
+		Status:      ExecPending,
+		Attempts:    1,
+		NextRetryAt: now.Add(r.lease),
+		UpdatedAt:   now,
+	}
+}
+
+// Retry 立即重新执行一个 pending 的记录, 不等待 NextRetryAt; 记录被其他 Runner 抢先执行时返回 ErrInvalidTransition
+func (r *Runner) Retry(ctx context.Context, requestID string) (any, error) {
+	e, err := r.store.GetExecution(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status != ExecPending {
+		return nil, fmt.Errorf("%w: execution %s is %s", ErrInvalidTransition, requestID, e.Status)
+	}
+	ok, err := r.claim(ctx, e, e.NextRetryAt)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: execution %s was claimed by another runner", ErrInvalidTransition, requestID)
+	}
+	return r.execute(ctx, e)
+}
+
+// RetryDue 执行所有到期的记录, 返回执行的数量; 被其他 Runner 抢先执行的记录会跳过,
+// 单个回调的错误记录在 Execution 中, 不会中断
+func (r *Runner) RetryDue(ctx context.Context, limit int) (int, error) {
+	now := r.now()
+	due, err := r.store.ListDueExecutions(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, e := range due {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		ok, err := r.claim(ctx, e, now)
+		if err != nil {
+			return count, err
+		}
+		if !ok {
+			continue
+		}
+		if _, err := r.execute(ctx, e); err != nil && !isCallError(err) && !errors.Is(err, ErrLeaseExpired) {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// callError 回调返回的错误, 与存储错误区分
+type callError struct {
+	error
+}
+
+func (e callError) Unwrap() error { return e.error }
+
+func isCallError(err error) bool {
+	_, ok := err.(callError)
+	return ok
+}
+
+// claim 抢占 e 的下一次执行, 成功时更新 e
+func (r *Runner) claim(ctx context.Context, e *Execution, due time.Time) (bool, error) {
+	now := r.now()
+	claimed := e.clone()
+	claimed.Attempts++
+	claimed.NextRetryAt = now.Add(r.lease)
+	claimed.UpdatedAt = now
+	ok, err := r.store.ClaimExecution(ctx, claimed, due)
+	if err != nil || !ok {
+		return false, err
+	}
+	*e = *claimed
+	return true, nil
+}
+
+// execute 执行已抢占的 e 并写入结果; 超过租约后记录已被重新抢占时不覆盖, 返回 ErrLeaseExpired
+func (r *Runner) execute(ctx context.Context, e *Execution) (any, error) {
+	callCtx := WithIdempotencyKey(ctx, IdempotencyKey(e.RequestID))
+	ret, callErr := r.callers.CallDecision(callCtx, e.Method, e.Args, e.Decision())
+
+	now := r.now()
+	e.UpdatedAt = now
+	if callErr == nil {
+		e.Status, e.LastError, e.NextRetryAt = ExecSucceeded, "", time.Time{}
+	} else {
+		e.LastError = callErr.Error()
+		e.NextRetryAt = now.Add(r.backoff.Delay(e.Attempts))
+		if !IsRetryable(callErr) || !r.retryable(e) || r.backoff.Exhausted(e.Attempts) {
+			e.Status, e.NextRetryAt = ExecFailed, time.Time{}
+		}
+	}
+	ok, err := r.store.FinishExecution(ctx, e)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return ret, fmt.Errorf("%w: execution %s attempt %d", ErrLeaseExpired, e.RequestID, e.Attempts)
+	}
+	if callErr != nil {
+		return ret, callError{callErr}
+	}
+	return ret, nil
+}
+
+// retryable 参数结构体实现 Retryable() bool 时由其决定
+func (r *Runner) retryable(e *Execution) bool {
+	arg, _, err := r.callers.UnmarshalMethodArgs(e.Method, e.Args)
+	if err != nil {
+		return false
+	}
+	if p, ok := arg.(interface{ Retryable() bool }); ok {
+		return p.Retryable()
+	}
+	return true
+}
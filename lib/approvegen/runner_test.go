@@ -0,0 +1,255 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, b.Delay(1))
+	assert.Equal(t, 2*time.Second, b.Delay(2))
+	assert.Equal(t, 4*time.Second, b.Delay(3))
+	assert.Equal(t, 5*time.Second, b.Delay(4))
+	assert.False(t, b.Exhausted(100))
+	assert.True(t, Backoff{MaxAttempts: 3}.Exhausted(3))
+}
+
+func TestRunnerRetry(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil).withRunner()
+	w, r, store, caller, now := env.w, env.runner, env.store, env.caller, env.now
+	caller.errs = []error{errors.New("db down"), errors.New("db down")}
+
+	req, err := w.Submit(ctx, &jobArgs{Name: "sync"}, SubmitOptions{})
+	require.NoError(t, err)
+	_, err = w.Decide(ctx, req.ID, Decision{Approved: true, Approver: "amy"})
+	assert.EqualError(t, err, "db down")
+
+	// 审批结果已记录, 回调等待重试
+	got, err := w.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, got.Status)
+	e, err := store.GetExecution(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ExecPending, e.Status)
+	assert.Equal(t, 1, e.Attempts)
+	assert.Equal(t, "db down", e.LastError)
+	assert.Equal(t, now.Add(time.Minute), e.NextRetryAt)
+	assert.Equal(t, "amy", e.Approver)
+
+	// 未到期不执行
+	n, err := r.RetryDue(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	*now = now.Add(time.Minute)
+	n, err = r.RetryDue(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	e, _ = store.GetExecution(ctx, req.ID)
+	assert.Equal(t, 2, e.Attempts)
+	assert.Equal(t, now.Add(2*time.Minute), e.NextRetryAt)
+
+	ret, err := r.Retry(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "sync", ret)
+	e, _ = store.GetExecution(ctx, req.ID)
+	assert.Equal(t, ExecSucceeded, e.Status)
+	assert.Equal(t, 3, e.Attempts)
+	assert.Empty(t, e.LastError)
+	assert.True(t, e.NextRetryAt.IsZero())
+
+	// 每次执行使用相同的幂等键
+	key := IdempotencyKey(req.ID)
+	assert.Equal(t, []string{key, key, key}, caller.keys)
+
+	_, err = r.Retry(ctx, req.ID)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+}
+
+func TestRunnerNonRetryable(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		arg  *jobArgs
+		errs []error
+		want int // 失败时的执行次数
+	}{
+		{name: "exec::noRetry", arg: &jobArgs{NoRetry: true}, errs: []error{errors.New("x")}, want: 1},
+		{name: "NonRetryable", arg: &jobArgs{}, errs: []error{NonRetryable(errors.New("x"))}, want: 1},
+		{name: "MaxAttempts", arg: &jobArgs{}, errs: []error{errors.New("x"), errors.New("x"), errors.New("x"), errors.New("x")}, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(nil).withRunner()
+			w, r, store, now := env.w, env.runner, env.store, env.now
+			env.caller.errs = tt.errs
+			req, err := w.Submit(ctx, tt.arg, SubmitOptions{})
+			require.NoError(t, err)
+			_, err = w.Approve(ctx, req.ID)
+			require.Error(t, err)
+			for range 5 {
+				*now = now.Add(time.Hour)
+				_, err := r.RetryDue(ctx, 0)
+				require.NoError(t, err)
+			}
+			e, err := store.GetExecution(ctx, req.ID)
+			require.NoError(t, err)
+			assert.Equal(t, ExecFailed, e.Status)
+			assert.Equal(t, tt.want, e.Attempts)
+		})
+	}
+}
+
+func TestWorkflowIdempotencyKeyWithoutRunner(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, caller := env.w, env.caller
+	req, err := w.Submit(ctx, &jobArgs{}, SubmitOptions{})
+	require.NoError(t, err)
+	_, err = w.Approve(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{IdempotencyKey(req.ID)}, caller.keys)
+}
+
+func TestWorkflowExecutionWrittenWithTransition(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil).withRunner()
+	w, r, store, caller, now := env.w, env.runner, env.store, env.caller, env.now
+
+	// 模拟第一次执行时进程退出
+	caller.onCall = func() { panic("crash") }
+	req, err := w.Submit(ctx, &jobArgs{Name: "sync"}, SubmitOptions{})
+	require.NoError(t, err)
+	assert.PanicsWithValue(t, "crash", func() { _, _ = w.Approve(ctx, req.ID) })
+	caller.onCall = nil
+
+	// 审批结果和已抢占第一次执行的记录一起写入
+	got, err := w.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, got.Status)
+	e, err := store.GetExecution(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ExecPending, e.Status)
+	assert.Equal(t, 1, e.Attempts)
+	assert.Equal(t, now.Add(DefaultLease), e.NextRetryAt)
+
+	// 租约到期前不会重复执行
+	n, err := r.RetryDue(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	*now = now.Add(DefaultLease)
+	n, err = r.RetryDue(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	e, _ = store.GetExecution(ctx, req.ID)
+	assert.Equal(t, ExecSucceeded, e.Status)
+	assert.Equal(t, 2, e.Attempts)
+	key := IdempotencyKey(req.ID)
+	assert.Equal(t, []string{key, key}, caller.keys)
+}
+
+// barrierStore 所有 ListDueExecutions 都返回后才继续, 模拟多个 Runner 同时取到同一条到期记录
+type barrierStore struct {
+	*MemoryStore
+	listed sync.WaitGroup
+}
+
+func (s *barrierStore) ListDueExecutions(ctx context.Context, now time.Time, limit int) ([]*Execution, error) {
+	ret, err := s.MemoryStore.ListDueExecutions(ctx, now, limit)
+	s.listed.Done()
+	s.listed.Wait()
+	return ret, err
+}
+
+func TestRunnerRetryDueConcurrent(t *testing.T) {
+	ctx := context.Background()
+	store := &barrierStore{MemoryStore: NewMemoryStore()}
+	env := newTestEnv(store).withRunner()
+	w, r, caller, now := env.w, env.runner, env.caller, env.now
+	caller.errs = []error{errors.New("db down")}
+
+	req, err := w.Submit(ctx, &jobArgs{Name: "sync"}, SubmitOptions{})
+	require.NoError(t, err)
+	_, err = w.Approve(ctx, req.ID)
+	require.Error(t, err)
+	*now = now.Add(time.Minute)
+
+	// 两个 RetryDue 都取到了这条记录, 只有一个能抢占成功
+	store.listed.Add(2)
+	counts := make([]int, 2)
+	var wg sync.WaitGroup
+	for i := range counts {
+		wg.Go(func() {
+			n, err := r.RetryDue(ctx, 0)
+			assert.NoError(t, err)
+			counts[i] = n
+		})
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, counts[0]+counts[1])
+	assert.Equal(t, []bool{true, true}, caller.calls)
+	e, err := store.GetExecution(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ExecSucceeded, e.Status)
+	assert.Equal(t, 2, e.Attempts)
+}
+
+func TestRunnerLease(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil).withRunner()
+	w, r, store, caller, now := env.w, env.runner, env.store, env.caller, env.now
+
+	// 第一次执行期间记录已被抢占, RetryDue 不会重复执行
+	caller.onCall = func() {
+		caller.onCall = nil
+		n, err := r.RetryDue(ctx, 0)
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	}
+	req, err := w.Submit(ctx, &jobArgs{Name: "a"}, SubmitOptions{})
+	require.NoError(t, err)
+	_, err = w.Approve(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Len(t, caller.calls, 1)
+
+	// 第一次执行超过租约并失败: 租约到期后被重新执行且成功, 第一次的结果不会覆盖
+	caller.errs = []error{errors.New("slow")}
+	caller.onCall = func() {
+		caller.onCall = nil
+		*now = now.Add(DefaultLease)
+		n, err := r.RetryDue(ctx, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	req, err = w.Submit(ctx, &jobArgs{Name: "b"}, SubmitOptions{})
+	require.NoError(t, err)
+	_, err = w.Approve(ctx, req.ID)
+	assert.ErrorIs(t, err, ErrLeaseExpired)
+	assert.Len(t, caller.calls, 3)
+
+	e, err := store.GetExecution(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ExecSucceeded, e.Status)
+	assert.Equal(t, 2, e.Attempts)
+	assert.Empty(t, e.LastError)
+}
+
+func TestMemoryStoreTransitionWithExecutionConflict(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	require.NoError(t, store.Create(ctx, &Request{ID: "r1", Status: StatusRejected}))
+	err := store.TransitionWithExecution(ctx, "r1", StatusPending, StatusApproved, time.Now(), &Execution{RequestID: "r1"})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	_, err = store.GetExecution(ctx, "r1")
+	assert.ErrorIs(t, err, ErrExecutionNotFound)
+}
@@ -0,0 +1,40 @@
+package approvegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaKey 参数 JSON 中记录结构体版本的字段, 由 approveGen -schema 生成的 MarshalJSON 写入
+const SchemaKey = "__schema"
+
+// MarshalWithSchema 序列化 v 并在 JSON 对象的开头写入 SchemaKey
+func MarshalWithSchema(v any, hash string) ([]byte, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(bs) < 2 || bs[0] != '{' {
+		return nil, fmt.Errorf("approvegen: %T is not encoded as a JSON object", v)
+	}
+	key, _ := json.Marshal(hash)
+	var buf bytes.Buffer
+	buf.Grow(len(bs) + len(SchemaKey) + len(key) + 4)
+	buf.WriteString(`{"` + SchemaKey + `":`)
+	buf.Write(key)
+	if rest := bytes.TrimSpace(bs[1:]); len(rest) > 0 && rest[0] != '}' {
+		buf.WriteByte(',')
+	}
+	buf.Write(bs[1:])
+	return buf.Bytes(), nil
+}
+
+// SchemaOf 读取参数 JSON 中记录的结构体版本, 旧数据没有记录时返回空字符串
+func SchemaOf(content string) string {
+	var v struct {
+		Schema string `json:"__schema"`
+	}
+	_ = json.Unmarshal([]byte(content), &v)
+	return v.Schema
+}
@@ -0,0 +1,24 @@
+package approvegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalWithSchema(t *testing.T) {
+	bs, err := MarshalWithSchema(struct{ Name string }{Name: "a"}, "v1:abc")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"__schema":"v1:abc","Name":"a"}`, string(bs))
+	assert.Equal(t, "v1:abc", SchemaOf(string(bs)))
+
+	bs, err = MarshalWithSchema(struct{}{}, "v1:abc")
+	require.NoError(t, err)
+	assert.Equal(t, `{"__schema":"v1:abc"}`, string(bs))
+
+	_, err = MarshalWithSchema([]int{1}, "v1:abc")
+	assert.Error(t, err)
+
+	assert.Equal(t, "", SchemaOf(`{"Name":"a"}`))
+}
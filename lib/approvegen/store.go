@@ -0,0 +1,213 @@
+package approvegen
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store 审批请求存储
+type Store interface {
+	// Create 保存一个新的请求
+	Create(ctx context.Context, req *Request) error
+	// Get 查询请求, 不存在时返回 ErrRequestNotFound
+	Get(ctx context.Context, id string) (*Request, error)
+	// Transition 仅当当前状态为 from 时将状态更新为 to, 用于防止并发重复审批;
+	// 当前状态不是 from 时返回 ErrInvalidTransition
+	Transition(ctx context.Context, id string, from, to Status, at time.Time) error
+	// TransitionWithExecution 与 Transition 相同, 并在同一事务中写入回调的执行记录 e,
+	// 保证审批结果和待执行的回调同时生效, 不会出现已审批却没有执行记录的请求
+	TransitionWithExecution(ctx context.Context, id string, from, to Status, at time.Time, e *Execution) error
+	// AddApproval 仅当请求为 pending 时记录一次审批通过, 返回该请求目前所有的审批记录(按记录顺序);
+	// 请求不是 pending 时返回 ErrInvalidTransition
+	AddApproval(ctx context.Context, id string, a Approval) ([]Approval, error)
+	// ListByStatus 按创建时间升序返回指定状态的请求, limit <= 0 表示不限制
+	ListByStatus(ctx context.Context, status Status, limit int) ([]*Request, error)
+	// ListExpired 按过期时间升序返回 ExpiresAt <= now 的 pending 请求, limit <= 0 表示不限制
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*Request, error)
+}
+
+// MemoryStore 进程内存储, 适用于测试和单实例部署
+type MemoryStore struct {
+	mu         sync.RWMutex
+	requests   map[string]*Request
+	approvals  map[string][]Approval
+	executions map[string]*Execution
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		requests:   make(map[string]*Request),
+		approvals:  make(map[string][]Approval),
+		executions: make(map[string]*Execution),
+	}
+}
+
+func (s *MemoryStore) Create(ctx context.Context, req *Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.requests[req.ID]; ok {
+		return fmt.Errorf("approval request %s already exists", req.ID)
+	}
+	s.requests[req.ID] = req.clone()
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Request, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	req, ok := s.requests[id]
+	if !ok {
+		return nil, ErrRequestNotFound
+	}
+	return req.clone(), nil
+}
+
+func (s *MemoryStore) Transition(ctx context.Context, id string, from, to Status, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.transition(id, from, to, at)
+}
+
+func (s *MemoryStore) TransitionWithExecution(ctx context.Context, id string, from, to Status, at time.Time, e *Execution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.transition(id, from, to, at); err != nil {
+		return err
+	}
+	s.executions[e.RequestID] = e.clone()
+	return nil
+}
+
+// transition 调用方需持有写锁
+func (s *MemoryStore) transition(id string, from, to Status, at time.Time) error {
+	req, ok := s.requests[id]
+	if !ok {
+		return ErrRequestNotFound
+	}
+	if req.Status != from {
+		return fmt.Errorf("%w: %s is %s, not %s", ErrInvalidTransition, id, req.Status, from)
+	}
+	req.Status = to
+	req.DecidedAt = at
+	return nil
+}
+
+func (s *MemoryStore) AddApproval(ctx context.Context, id string, a Approval) ([]Approval, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req, ok := s.requests[id]
+	if !ok {
+		return nil, ErrRequestNotFound
+	}
+	if req.Status != StatusPending {
+		return nil, fmt.Errorf("%w: %s is %s", ErrInvalidTransition, id, req.Status)
+	}
+	s.approvals[id] = append(s.approvals[id], a)
+	return slices.Clone(s.approvals[id]), nil
+}
+
+func (s *MemoryStore) ListByStatus(ctx context.Context, status Status, limit int) ([]*Request, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ret []*Request
+	for _, req := range s.requests {
+		if req.Status == status {
+			ret = append(ret, req.clone())
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].CreatedAt.Equal(ret[j].CreatedAt) {
+			return ret[i].ID < ret[j].ID
+		}
+		return ret[i].CreatedAt.Before(ret[j].CreatedAt)
+	})
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
+
+func (s *MemoryStore) ListExpired(ctx context.Context, now time.Time, limit int) ([]*Request, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ret []*Request
+	for _, req := range s.requests {
+		if req.Status == StatusPending && req.ExpiredAt(now) {
+			ret = append(ret, req.clone())
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].ExpiresAt.Equal(ret[j].ExpiresAt) {
+			return ret[i].ID < ret[j].ID
+		}
+		return ret[i].ExpiresAt.Before(ret[j].ExpiresAt)
+	})
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
+
+func (s *MemoryStore) SaveExecution(ctx context.Context, e *Execution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executions[e.RequestID] = e.clone()
+	return nil
+}
+
+func (s *MemoryStore) ClaimExecution(ctx context.Context, e *Execution, due time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, ok := s.executions[e.RequestID]
+	if !ok || cur.Status != ExecPending || cur.Attempts != e.Attempts-1 || cur.NextRetryAt.After(due) {
+		return false, nil
+	}
+	cur.Attempts, cur.NextRetryAt, cur.UpdatedAt = e.Attempts, e.NextRetryAt, e.UpdatedAt
+	return true, nil
+}
+
+func (s *MemoryStore) FinishExecution(ctx context.Context, e *Execution) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, ok := s.executions[e.RequestID]
+	if !ok || cur.Status != ExecPending || cur.Attempts != e.Attempts {
+		return false, nil
+	}
+	s.executions[e.RequestID] = e.clone()
+	return true, nil
+}
+
+func (s *MemoryStore) GetExecution(ctx context.Context, requestID string) (*Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.executions[requestID]
+	if !ok {
+		return nil, ErrExecutionNotFound
+	}
+	return e.clone(), nil
+}
+
+func (s *MemoryStore) ListDueExecutions(ctx context.Context, now time.Time, limit int) ([]*Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ret []*Execution
+	for _, e := range s.executions {
+		if e.Status == ExecPending && !e.NextRetryAt.After(now) {
+			ret = append(ret, e.clone())
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].NextRetryAt.Equal(ret[j].NextRetryAt) {
+			return ret[i].RequestID < ret[j].RequestID
+		}
+		return ret[i].NextRetryAt.Before(ret[j].NextRetryAt)
+	})
+	if limit > 0 && len(ret) > limit {
+		ret = ret[:limit]
+	}
+	return ret, nil
+}
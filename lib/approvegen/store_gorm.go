@@ -0,0 +1,333 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RequestPO 审批请求的数据库模型
+type RequestPO struct {
+	ID        string     `gorm:"primaryKey;size:64"`
+	Method    string     `gorm:"size:255;index"`
+	Args      []byte     // 二进制安全的列类型(bytea/longblob), 可以保存 msgpack 等编码的参数
+	Requester string     `gorm:"size:255;index"`
+	Status    string     `gorm:"size:16;index:idx_approval_status_created"`
+	CreatedAt time.Time  `gorm:"index:idx_approval_status_created"`
+	ExpiresAt *time.Time `gorm:"index"`
+	DecidedAt *time.Time
+}
+
+func (RequestPO) TableName() string {
+	return "approval_requests"
+}
+
+func (po *RequestPO) ToDomain() *Request {
+	return &Request{
+		ID:        po.ID,
+		Method:    po.Method,
+		Args:      string(po.Args),
+		Requester: po.Requester,
+		Status:    Status(po.Status),
+		CreatedAt: po.CreatedAt,
+		ExpiresAt: fromTimePtr(po.ExpiresAt),
+		DecidedAt: fromTimePtr(po.DecidedAt),
+	}
+}
+
+func (po *RequestPO) FromDomain(req *Request) *RequestPO {
+	return &RequestPO{
+		ID:        req.ID,
+		Method:    req.Method,
+		Args:      []byte(req.Args),
+		Requester: req.Requester,
+		Status:    string(req.Status),
+		CreatedAt: req.CreatedAt,
+		ExpiresAt: toTimePtr(req.ExpiresAt),
+		DecidedAt: toTimePtr(req.DecidedAt),
+	}
+}
+
+// ApprovalPO 审批通过记录的数据库模型, 用于计算 Policy.Quorum
+type ApprovalPO struct {
+	ID        uint64 `gorm:"primaryKey;autoIncrement"`
+	RequestID string `gorm:"size:64;index"`
+	Approver  string `gorm:"size:255"`
+	Role      string `gorm:"size:255"`
+	At        time.Time
+}
+
+func (ApprovalPO) TableName() string {
+	return "approval_approvals"
+}
+
+func (po *ApprovalPO) ToDomain() Approval {
+	return Approval{Approver: po.Approver, Role: po.Role, At: po.At}
+}
+
+func (po *ApprovalPO) FromDomain(requestID string, a Approval) *ApprovalPO {
+	return &ApprovalPO{RequestID: requestID, Approver: a.Approver, Role: a.Role, At: a.At}
+}
+
+// ExecutionPO 回调执行记录的数据库模型
+type ExecutionPO struct {
+	RequestID   string `gorm:"primaryKey;size:64"`
+	Method      string `gorm:"size:255"`
+	Args        []byte
+	Approved    bool
+	Approver    string `gorm:"size:255"`
+	Role        string `gorm:"size:255"`
+	Comment     string `gorm:"type:text"`
+	System      bool
+	DecidedAt   *time.Time
+	Status      string `gorm:"size:16;index:idx_approval_exec_due"`
+	Attempts    int
+	LastError   string     `gorm:"type:text"`
+	NextRetryAt *time.Time `gorm:"index:idx_approval_exec_due"`
+	UpdatedAt   time.Time
+}
+
+func (ExecutionPO) TableName() string {
+	return "approval_executions"
+}
+
+func (po *ExecutionPO) ToDomain() *Execution {
+	return &Execution{
+		RequestID:   po.RequestID,
+		Method:      po.Method,
+		Args:        string(po.Args),
+		Approved:    po.Approved,
+		Approver:    po.Approver,
+		Role:        po.Role,
+		Comment:     po.Comment,
+		System:      po.System,
+		DecidedAt:   fromTimePtr(po.DecidedAt),
+		Status:      ExecStatus(po.Status),
+		Attempts:    po.Attempts,
+		LastError:   po.LastError,
+		NextRetryAt: fromTimePtr(po.NextRetryAt),
+		UpdatedAt:   po.UpdatedAt,
+	}
+}
+
+func (po *ExecutionPO) FromDomain(e *Execution) *ExecutionPO {
+	return &ExecutionPO{
+		RequestID:   e.RequestID,
+		Method:      e.Method,
+		Args:        []byte(e.Args),
+		Approved:    e.Approved,
+		Approver:    e.Approver,
+		Role:        e.Role,
+		Comment:     e.Comment,
+		System:      e.System,
+		DecidedAt:   toTimePtr(e.DecidedAt),
+		Status:      string(e.Status),
+		Attempts:    e.Attempts,
+		LastError:   e.LastError,
+		NextRetryAt: toTimePtr(e.NextRetryAt),
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
+
+// GormStore 基于 GORM 的存储, 表结构见 RequestPO, ApprovalPO 和 ExecutionPO
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// AutoMigrate 创建或更新 approval_requests, approval_approvals 和 approval_executions 表
+func (s *GormStore) AutoMigrate(ctx context.Context) error {
+	return s.db.WithContext(ctx).AutoMigrate(&RequestPO{}, &ApprovalPO{}, &ExecutionPO{})
+}
+
+func (s *GormStore) Create(ctx context.Context, req *Request) error {
+	return s.db.WithContext(ctx).Create(new(RequestPO).FromDomain(req)).Error
+}
+
+func (s *GormStore) Get(ctx context.Context, id string) (*Request, error) {
+	var po RequestPO
+	err := s.db.WithContext(ctx).Where("id = ?", id).Take(&po).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return po.ToDomain(), nil
+}
+
+func (s *GormStore) Transition(ctx context.Context, id string, from, to Status, at time.Time) error {
+	return s.transition(s.db.WithContext(ctx), id, from, to, at)
+}
+
+func (s *GormStore) TransitionWithExecution(ctx context.Context, id string, from, to Status, at time.Time, e *Execution) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.transition(tx, id, from, to, at); err != nil {
+			return err
+		}
+		return tx.Save(new(ExecutionPO).FromDomain(e)).Error
+	})
+}
+
+func (s *GormStore) transition(db *gorm.DB, id string, from, to Status, at time.Time) error {
+	ret := db.Model(&RequestPO{}).
+		Where("id = ? AND status = ?", id, string(from)).
+		Updates(map[string]any{"status": string(to), "decided_at": at})
+	if ret.Error != nil {
+		return ret.Error
+	}
+	if ret.RowsAffected > 0 {
+		return nil
+	}
+	var po RequestPO
+	err := db.Where("id = ?", id).Take(&po).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRequestNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s is %s, not %s", ErrInvalidTransition, id, po.Status, from)
+}
+
+// AddApproval 在事务中锁定请求行, 并发的审批按顺序写入
+func (s *GormStore) AddApproval(ctx context.Context, id string, a Approval) ([]Approval, error) {
+	var ret []Approval
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var po RequestPO
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(&po).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRequestNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if Status(po.Status) != StatusPending {
+			return fmt.Errorf("%w: %s is %s", ErrInvalidTransition, id, po.Status)
+		}
+		if err := tx.Create(new(ApprovalPO).FromDomain(id, a)).Error; err != nil {
+			return err
+		}
+		var pos []ApprovalPO
+		if err := tx.Where("request_id = ?", id).Order("id").Find(&pos).Error; err != nil {
+			return err
+		}
+		ret = make([]Approval, 0, len(pos))
+		for i := range pos {
+			ret = append(ret, pos[i].ToDomain())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (s *GormStore) ListByStatus(ctx context.Context, status Status, limit int) ([]*Request, error) {
+	var pos []RequestPO
+	query := s.db.WithContext(ctx).Where("status = ?", string(status)).Order("created_at, id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&pos).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*Request, 0, len(pos))
+	for i := range pos {
+		ret = append(ret, pos[i].ToDomain())
+	}
+	return ret, nil
+}
+
+// ListExpired 使用 expires_at 索引, expires_at 为 NULL 的请求永不过期
+func (s *GormStore) ListExpired(ctx context.Context, now time.Time, limit int) ([]*Request, error) {
+	var pos []RequestPO
+	query := s.db.WithContext(ctx).Where("status = ? AND expires_at <= ?", string(StatusPending), now).Order("expires_at, id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&pos).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*Request, 0, len(pos))
+	for i := range pos {
+		ret = append(ret, pos[i].ToDomain())
+	}
+	return ret, nil
+}
+
+func (s *GormStore) SaveExecution(ctx context.Context, e *Execution) error {
+	return s.db.WithContext(ctx).Save(new(ExecutionPO).FromDomain(e)).Error
+}
+
+// ClaimExecution 条件更新 attempts 和 next_retry_at, 并发抢占同一记录时只有一个 UPDATE 影响到行
+func (s *GormStore) ClaimExecution(ctx context.Context, e *Execution, due time.Time) (bool, error) {
+	ret := s.db.WithContext(ctx).Model(&ExecutionPO{}).
+		Where("request_id = ? AND status = ? AND attempts = ? AND next_retry_at <= ?", e.RequestID, string(ExecPending), e.Attempts-1, due).
+		Updates(map[string]any{"attempts": e.Attempts, "next_retry_at": toTimePtr(e.NextRetryAt), "updated_at": e.UpdatedAt})
+	return ret.RowsAffected > 0, ret.Error
+}
+
+// FinishExecution 以抢占时的 attempts 为条件更新, 不会覆盖已被重新抢占或已经结束的记录
+func (s *GormStore) FinishExecution(ctx context.Context, e *Execution) (bool, error) {
+	po := new(ExecutionPO).FromDomain(e)
+	ret := s.db.WithContext(ctx).Model(&ExecutionPO{}).
+		Where("request_id = ? AND status = ? AND attempts = ?", e.RequestID, string(ExecPending), e.Attempts).
+		Updates(map[string]any{
+			"status":        po.Status,
+			"last_error":    po.LastError,
+			"next_retry_at": po.NextRetryAt,
+			"updated_at":    po.UpdatedAt,
+		})
+	return ret.RowsAffected > 0, ret.Error
+}
+
+func (s *GormStore) GetExecution(ctx context.Context, requestID string) (*Execution, error) {
+	var po ExecutionPO
+	err := s.db.WithContext(ctx).Where("request_id = ?", requestID).Take(&po).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrExecutionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return po.ToDomain(), nil
+}
+
+func (s *GormStore) ListDueExecutions(ctx context.Context, now time.Time, limit int) ([]*Execution, error) {
+	var pos []ExecutionPO
+	query := s.db.WithContext(ctx).Where("status = ? AND next_retry_at <= ?", string(ExecPending), now).Order("next_retry_at, request_id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&pos).Error; err != nil {
+		return nil, err
+	}
+	ret := make([]*Execution, 0, len(pos))
+	for i := range pos {
+		ret = append(ret, pos[i].ToDomain())
+	}
+	return ret, nil
+}
+
+func toTimePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func fromTimePtr(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,334 @@
+package approvegen
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestGormStore 每个测试使用独立的内存数据库, 单连接保证并发的语句按顺序执行
+func newTestGormStore(t *testing.T) *GormStore {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	s := NewGormStore(db)
+	require.NoError(t, s.AutoMigrate(context.Background()))
+	return s
+}
+
+func gormRequest(id string, createdAt time.Time) *Request {
+	return &Request{ID: id, Method: "UserService_Create", Args: `{"Name":"bob"}`, Requester: "alice", Status: StatusPending, CreatedAt: createdAt}
+}
+
+func requestIDs(reqs []*Request) []string {
+	ids := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		ids = append(ids, req.ID)
+	}
+	return ids
+}
+
+func TestGormStoreGet(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+
+	// msgpack 等二进制编码的参数包含 0x00 和非法的 UTF-8, 必须原样读回
+	in := gormRequest("r1", testNow)
+	bs, err := Msgpack.Marshal(map[string]any{"Name": "bob", "Raw": []byte{0x00, 0xff, 0xc0}})
+	require.NoError(t, err)
+	in.Args = string(bs)
+	in.ExpiresAt = testNow.Add(time.Hour)
+	require.NoError(t, s.Create(ctx, in))
+
+	got, err := s.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(in.Args), []byte(got.Args))
+	assert.Equal(t, StatusPending, got.Status)
+	assert.True(t, in.CreatedAt.Equal(got.CreatedAt))
+	assert.True(t, in.ExpiresAt.Equal(got.ExpiresAt))
+	assert.True(t, got.DecidedAt.IsZero())
+
+	_, err = s.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrRequestNotFound)
+}
+
+func TestGormStoreTransition(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+	require.NoError(t, s.Create(ctx, gormRequest("r1", testNow)))
+
+	// 并发审批同一个请求, 只有一个能成功, 其余返回 ErrInvalidTransition
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			to := StatusApproved
+			if i%2 == 1 {
+				to = StatusRejected
+			}
+			errs[i] = s.Transition(ctx, "r1", StatusPending, to, testNow.Add(time.Minute))
+		}()
+	}
+	wg.Wait()
+	var won int
+	for _, err := range errs {
+		if err == nil {
+			won++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrInvalidTransition)
+	}
+	assert.Equal(t, 1, won)
+
+	got, err := s.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.True(t, got.Status.IsFinal())
+	assert.True(t, testNow.Add(time.Minute).Equal(got.DecidedAt))
+
+	assert.ErrorIs(t, s.Transition(ctx, "missing", StatusPending, StatusApproved, testNow), ErrRequestNotFound)
+}
+
+func TestGormStoreListByStatus(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+	// 创建时间相同时按 ID 排序
+	require.NoError(t, s.Create(ctx, gormRequest("r3", testNow.Add(2*time.Minute))))
+	require.NoError(t, s.Create(ctx, gormRequest("r2", testNow)))
+	require.NoError(t, s.Create(ctx, gormRequest("r1", testNow)))
+	require.NoError(t, s.Create(ctx, gormRequest("r4", testNow.Add(time.Minute))))
+	require.NoError(t, s.Transition(ctx, "r4", StatusPending, StatusApproved, testNow))
+
+	reqs, err := s.ListByStatus(ctx, StatusPending, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2", "r3"}, requestIDs(reqs))
+
+	reqs, err = s.ListByStatus(ctx, StatusPending, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2"}, requestIDs(reqs))
+
+	reqs, err = s.ListByStatus(ctx, StatusApproved, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r4"}, requestIDs(reqs))
+}
+
+func TestGormStoreListExpired(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+	add := func(id string, ttl time.Duration) {
+		req := gormRequest(id, testNow)
+		if ttl != 0 {
+			req.ExpiresAt = testNow.Add(ttl)
+		}
+		require.NoError(t, s.Create(ctx, req))
+	}
+	add("late", 2*time.Hour)
+	add("never", 0)
+	add("b", time.Hour)
+	add("a", time.Hour)
+	add("first", time.Minute)
+	add("done", time.Minute)
+	require.NoError(t, s.Transition(ctx, "done", StatusPending, StatusCancelled, testNow))
+
+	// 只返回已过期的 pending 请求, 按过期时间和 ID 排序
+	reqs, err := s.ListExpired(ctx, testNow.Add(time.Hour), 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "a", "b"}, requestIDs(reqs))
+
+	reqs, err = s.ListExpired(ctx, testNow.Add(time.Hour), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "a"}, requestIDs(reqs))
+}
+
+func TestGormStoreAddApproval(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+	require.NoError(t, s.Create(ctx, gormRequest("r1", testNow)))
+
+	got, err := s.AddApproval(ctx, "r1", Approval{Approver: "bob", Role: "finance", At: testNow})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	got, err = s.AddApproval(ctx, "r1", Approval{Approver: "carol", Role: "admin", At: testNow.Add(time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "bob", got[0].Approver)
+	assert.Equal(t, "finance", got[0].Role)
+	assert.Equal(t, "carol", got[1].Approver)
+	assert.True(t, testNow.Add(time.Minute).Equal(got[1].At))
+
+	// 请求进入终态后不能再记录审批
+	require.NoError(t, s.Transition(ctx, "r1", StatusPending, StatusApproved, testNow))
+	_, err = s.AddApproval(ctx, "r1", Approval{Approver: "dave"})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	_, err = s.AddApproval(ctx, "missing", Approval{Approver: "dave"})
+	assert.ErrorIs(t, err, ErrRequestNotFound)
+}
+
+func TestGormStoreSaveExecution(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+
+	bs, err := Msgpack.Marshal(map[string]any{"Raw": []byte{0x00, 0xff}})
+	require.NoError(t, err)
+	e := &Execution{RequestID: "r1", Method: "UserService_Create", Args: string(bs), Approved: true, Approver: "bob", Role: "finance",
+		DecidedAt: testNow, Status: ExecPending, Attempts: 1, LastError: "boom", NextRetryAt: testNow.Add(time.Second), UpdatedAt: testNow}
+	require.NoError(t, s.SaveExecution(ctx, e))
+
+	got, err := s.GetExecution(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte(e.Args), []byte(got.Args))
+	assert.Equal(t, "finance", got.Role)
+	assert.Equal(t, ExecPending, got.Status)
+	assert.Equal(t, 1, got.Attempts)
+
+	// 同一个请求再次保存时更新原记录
+	e.Status, e.Attempts, e.LastError, e.NextRetryAt = ExecSucceeded, 2, "", time.Time{}
+	require.NoError(t, s.SaveExecution(ctx, e))
+	got, err = s.GetExecution(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, ExecSucceeded, got.Status)
+	assert.Equal(t, 2, got.Attempts)
+	assert.Empty(t, got.LastError)
+	assert.True(t, got.NextRetryAt.IsZero())
+	var count int64
+	require.NoError(t, s.db.Model(&ExecutionPO{}).Count(&count).Error)
+	assert.EqualValues(t, 1, count)
+
+	_, err = s.GetExecution(ctx, "missing")
+	assert.ErrorIs(t, err, ErrExecutionNotFound)
+}
+
+func TestGormStoreListDueExecutions(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+	save := func(id string, status ExecStatus, delay time.Duration) {
+		require.NoError(t, s.SaveExecution(ctx, &Execution{RequestID: id, Status: status, NextRetryAt: testNow.Add(delay), UpdatedAt: testNow}))
+	}
+	save("later", ExecPending, time.Hour)
+	save("b", ExecPending, time.Minute)
+	save("a", ExecPending, time.Minute)
+	save("first", ExecPending, time.Second)
+	save("done", ExecSucceeded, time.Second)
+	save("failed", ExecFailed, time.Second)
+
+	due, err := s.ListDueExecutions(ctx, testNow.Add(time.Minute), 0)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(due))
+	for _, e := range due {
+		ids = append(ids, e.RequestID)
+	}
+	assert.Equal(t, []string{"first", "a", "b"}, ids)
+
+	due, err = s.ListDueExecutions(ctx, testNow.Add(time.Minute), 1)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "first", due[0].RequestID)
+}
+
+func TestGormStoreClaimExecution(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+	require.NoError(t, s.SaveExecution(ctx, &Execution{RequestID: "r1", Status: ExecPending, Attempts: 1, NextRetryAt: testNow, UpdatedAt: testNow}))
+
+	lease := testNow.Add(time.Minute)
+	claim := &Execution{RequestID: "r1", Status: ExecPending, Attempts: 2, NextRetryAt: lease, UpdatedAt: testNow}
+
+	// 未到期和执行次数不一致时不能抢占
+	ok, err := s.ClaimExecution(ctx, claim, testNow.Add(-time.Second))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = s.ClaimExecution(ctx, &Execution{RequestID: "r1", Attempts: 3, NextRetryAt: lease}, testNow)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 同一次执行只能被抢占一次, 租约内不再到期
+	ok, err = s.ClaimExecution(ctx, claim, testNow)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.ClaimExecution(ctx, claim, testNow)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	due, err := s.ListDueExecutions(ctx, testNow, 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	got, err := s.GetExecution(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Attempts)
+	assert.Equal(t, lease, got.NextRetryAt)
+
+	// 只有抢占的那次执行可以写入结果, 结束后不会被覆盖
+	stale := &Execution{RequestID: "r1", Status: ExecFailed, Attempts: 1, LastError: "stale", UpdatedAt: testNow}
+	ok, err = s.FinishExecution(ctx, stale)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	done := &Execution{RequestID: "r1", Status: ExecSucceeded, Attempts: 2, UpdatedAt: testNow}
+	ok, err = s.FinishExecution(ctx, done)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.FinishExecution(ctx, done)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	got, err = s.GetExecution(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, ExecSucceeded, got.Status)
+	assert.True(t, got.NextRetryAt.IsZero())
+	assert.Empty(t, got.LastError)
+}
+
+func TestGormStoreTransitionWithExecution(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+	require.NoError(t, s.Create(ctx, gormRequest("r1", testNow)))
+
+	e := &Execution{RequestID: "r1", Method: "UserService_Create", Approved: true, Status: ExecPending, NextRetryAt: testNow, UpdatedAt: testNow}
+	require.NoError(t, s.TransitionWithExecution(ctx, "r1", StatusPending, StatusApproved, testNow, e))
+	got, err := s.Get(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, got.Status)
+	_, err = s.GetExecution(ctx, "r1")
+	require.NoError(t, err)
+
+	// 状态转换失败时不写入执行记录
+	require.NoError(t, s.Create(ctx, gormRequest("r2", testNow)))
+	require.NoError(t, s.Transition(ctx, "r2", StatusPending, StatusCancelled, testNow))
+	e2 := &Execution{RequestID: "r2", Status: ExecPending, UpdatedAt: testNow}
+	assert.ErrorIs(t, s.TransitionWithExecution(ctx, "r2", StatusPending, StatusApproved, testNow, e2), ErrInvalidTransition)
+	_, err = s.GetExecution(ctx, "r2")
+	assert.ErrorIs(t, err, ErrExecutionNotFound)
+}
+
+func TestWorkflowWithGormStore(t *testing.T) {
+	ctx := context.Background()
+	s := newTestGormStore(t)
+	env := newTestEnv(s)
+	w, caller := env.w, env.caller
+	caller.codec = Msgpack
+	w.WithCodec(Msgpack)
+
+	req, err := w.Submit(ctx, &createArgs{Name: "bob"}, SubmitOptions{Requester: "alice"})
+	require.NoError(t, err)
+	stored, err := s.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, req.Args, stored.Args)
+
+	_, err = w.Decide(ctx, req.ID, Decision{Approved: true, Approver: "carol"})
+	require.NoError(t, err)
+	stored, err = s.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, stored.Status)
+	assert.Equal(t, []bool{true}, caller.calls)
+}
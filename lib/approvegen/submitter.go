@@ -0,0 +1,62 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPending 方法调用已转为待审批请求, 尚未执行
+var ErrPending = errors.New("ErrPending")
+
+// Submitter 提交审批请求, 返回待审批请求的 ID; 生成的 XxxApprovalProxy 使用
+type Submitter interface {
+	Submit(ctx context.Context, arg MethodArg) (string, error)
+}
+
+type SubmitterFunc func(ctx context.Context, arg MethodArg) (string, error)
+
+func (f SubmitterFunc) Submit(ctx context.Context, arg MethodArg) (string, error) {
+	return f(ctx, arg)
+}
+
+// PendingError 代理方法提交审批成功后返回的错误, 携带待审批请求的 ID;
+// 代理需要保持与原方法相同的签名, 所以通过 error 返回 ID
+type PendingError struct {
+	ID string
+}
+
+func NewPendingError(id string) error {
+	return &PendingError{ID: id}
+}
+
+func (e *PendingError) Error() string {
+	return "approval pending: " + e.ID
+}
+
+func (e *PendingError) Is(target error) bool {
+	return target == ErrPending
+}
+
+// PendingID 从代理方法返回的错误中取出待审批请求的 ID
+func PendingID(err error) (string, bool) {
+	var pe *PendingError
+	if errors.As(err, &pe) {
+		return pe.ID, true
+	}
+	return "", false
+}
+
+// Submitter 将 Workflow 包装为 Submitter, opts 从 ctx 中获取申请人等信息, 可以为 nil
+func (w *Workflow) Submitter(opts func(ctx context.Context) SubmitOptions) Submitter {
+	return SubmitterFunc(func(ctx context.Context, arg MethodArg) (string, error) {
+		var o SubmitOptions
+		if opts != nil {
+			o = opts(ctx)
+		}
+		req, err := w.Submit(ctx, arg, o)
+		if err != nil {
+			return "", err
+		}
+		return req.ID, nil
+	})
+}
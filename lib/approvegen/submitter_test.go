@@ -0,0 +1,38 @@
+package approvegen
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingError(t *testing.T) {
+	err := fmt.Errorf("create user: %w", NewPendingError("req-1"))
+	assert.ErrorIs(t, err, ErrPending)
+
+	id, ok := PendingID(err)
+	assert.True(t, ok)
+	assert.Equal(t, "req-1", id)
+
+	_, ok = PendingID(ErrUnknownMethod)
+	assert.False(t, ok)
+}
+
+func TestWorkflowSubmitter(t *testing.T) {
+	ctx := context.Background()
+	w := newTestEnv(nil).w
+	submitter := w.Submitter(func(ctx context.Context) SubmitOptions {
+		return SubmitOptions{Requester: "alice"}
+	})
+
+	id, err := submitter.Submit(ctx, &createArgs{Name: "bob"})
+	require.NoError(t, err)
+
+	req, err := w.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", req.Requester)
+	assert.Equal(t, StatusPending, req.Status)
+}
@@ -0,0 +1,46 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+)
+
+// Sweeper 处理已过期的 pending 请求: 按参数的 Expiry().OnExpire 以系统的名义通过/拒绝并调用回调,
+// 没有声明或为 ExpireOnly 时只标记为 expired; 与 Workflow 共用 Store 和 Dispatcher
+type Sweeper struct {
+	workflow *Workflow
+}
+
+func NewSweeper(w *Workflow) *Sweeper {
+	return &Sweeper{workflow: w}
+}
+
+// Sweep 处理最多 limit 个已过期的请求(limit <= 0 表示不限制), 返回处理的数量, 由调用方定时执行;
+// 被其他审批抢先处理的请求会跳过, 回调的错误不会中断, 合并后返回
+func (s *Sweeper) Sweep(ctx context.Context, limit int) (int, error) {
+	w := s.workflow
+	now := w.now()
+	expired, err := w.store.ListExpired(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	var callErrs []error
+	count := 0
+	for _, req := range expired {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		_, err := w.expire(ctx, req, now)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrInvalidTransition):
+			continue
+		case isCallError(err):
+			callErrs = append(callErrs, err)
+		default:
+			return count, err
+		}
+		count++
+	}
+	return count, errors.Join(callErrs...)
+}
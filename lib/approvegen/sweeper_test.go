@@ -0,0 +1,177 @@
+package approvegen
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowSubmitExpiryTTL(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, now := env.w, env.now
+
+	req, err := w.Submit(ctx, &expiringArgs{Name: "bob"}, SubmitOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), req.ExpiresAt)
+
+	// SubmitOptions.TTL 优先, < 0 表示永不过期
+	req, err = w.Submit(ctx, &expiringArgs{Name: "bob"}, SubmitOptions{TTL: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), req.ExpiresAt)
+	req, err = w.Submit(ctx, &expiringArgs{Name: "bob"}, SubmitOptions{TTL: -1})
+	require.NoError(t, err)
+	assert.True(t, req.ExpiresAt.IsZero())
+}
+
+func TestSweeperSweep(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, s, caller, now := env.w, NewSweeper(env.w), env.caller, env.now
+
+	rejected, err := w.Submit(ctx, &expiringArgs{Name: "a", Action: ExpireReject}, SubmitOptions{})
+	require.NoError(t, err)
+	approved, err := w.Submit(ctx, &expiringArgs{Name: "b", Action: ExpireApprove}, SubmitOptions{})
+	require.NoError(t, err)
+	expired, err := w.Submit(ctx, &expiringArgs{Name: "c"}, SubmitOptions{})
+	require.NoError(t, err)
+	plain, err := w.Submit(ctx, &createArgs{Name: "d"}, SubmitOptions{TTL: time.Minute})
+	require.NoError(t, err)
+	open, err := w.Submit(ctx, &expiringArgs{Name: "e", Action: ExpireReject}, SubmitOptions{TTL: 2 * time.Hour})
+	require.NoError(t, err)
+
+	n, err := s.Sweep(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	*now = now.Add(time.Hour)
+	n, err = s.Sweep(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	for id, status := range map[string]Status{
+		rejected.ID: StatusRejected,
+		approved.ID: StatusApproved,
+		expired.ID:  StatusExpired,
+		plain.ID:    StatusExpired,
+		open.ID:     StatusPending,
+	} {
+		got, err := w.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, status, got.Status, id)
+	}
+
+	// 创建时间相同的请求按 ID 排序, 处理顺序不固定
+	require.Len(t, caller.decisions, 2)
+	decisions := make(map[string]Decision)
+	for _, d := range caller.decisions {
+		decisions[d.RequestID] = d
+	}
+	assert.Equal(t, Decision{Approved: false, Approver: SystemApprover, Comment: "expired", DecidedAt: *now, RequestID: rejected.ID, System: true}, decisions[rejected.ID])
+	assert.True(t, decisions[approved.ID].Approved)
+	assert.True(t, decisions[approved.ID].System)
+
+	// 已处理的请求不会重复调用
+	n, err = s.Sweep(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Len(t, caller.decisions, 2)
+}
+
+func TestSweeperLimitAndCallError(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, s, caller, now := env.w, NewSweeper(env.w), env.caller, env.now
+	caller.errs = []error{errors.New("boom")}
+
+	for _, name := range []string{"fail", "ok", "later"} {
+		_, err := w.Submit(ctx, &expiringArgs{Name: name, Action: ExpireApprove}, SubmitOptions{})
+		require.NoError(t, err)
+		*now = now.Add(time.Second)
+	}
+	*now = now.Add(time.Hour)
+
+	// 回调的错误不会中断
+	n, err := s.Sweep(ctx, 2)
+	assert.ErrorContains(t, err, "boom")
+	assert.Equal(t, 2, n)
+	assert.Len(t, caller.decisions, 2)
+
+	n, err = s.Sweep(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestSweeperRunnerRecordsSystemDecision(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil).withRunner()
+	w, s, store, caller, now := env.w, NewSweeper(env.w), env.store, env.caller, env.now
+
+	req, err := w.Submit(ctx, &expiringArgs{Name: "bob", Action: ExpireReject}, SubmitOptions{})
+	require.NoError(t, err)
+	*now = now.Add(time.Hour)
+	_, err = s.Sweep(ctx, 0)
+	require.NoError(t, err)
+
+	e, err := store.GetExecution(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ExecSucceeded, e.Status)
+	assert.True(t, e.System)
+	assert.Equal(t, SystemApprover, e.Approver)
+	assert.True(t, caller.decisions[0].System)
+}
+
+func TestWorkflowExpireAppliesOnExpire(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, caller, now := env.w, env.caller, env.now
+
+	req, err := w.Submit(ctx, &expiringArgs{Name: "bob", Action: ExpireApprove}, SubmitOptions{})
+	require.NoError(t, err)
+	*now = now.Add(time.Hour)
+
+	// 审批时发现过期不会改变状态, OnExpire 的回调仍然会执行
+	_, err = w.Reject(ctx, req.ID)
+	assert.ErrorIs(t, err, ErrRequestExpired)
+	require.NoError(t, w.Expire(ctx, req.ID))
+
+	got, err := w.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusApproved, got.Status)
+	require.Len(t, caller.decisions, 1)
+	assert.True(t, caller.decisions[0].System)
+}
+
+func TestMemoryStoreListExpired(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := testNow
+	for _, req := range []*Request{
+		{ID: "late", Status: StatusPending, ExpiresAt: now.Add(-time.Minute)},
+		{ID: "early", Status: StatusPending, ExpiresAt: now.Add(-time.Hour)},
+		{ID: "due", Status: StatusPending, ExpiresAt: now},
+		{ID: "open", Status: StatusPending, ExpiresAt: now.Add(time.Minute)},
+		{ID: "never", Status: StatusPending},
+		{ID: "done", Status: StatusApproved, ExpiresAt: now.Add(-time.Hour)},
+	} {
+		require.NoError(t, store.Create(ctx, req))
+	}
+
+	ids := func(reqs []*Request) []string {
+		var ret []string
+		for _, req := range reqs {
+			ret = append(ret, req.ID)
+		}
+		return ret
+	}
+	got, err := store.ListExpired(ctx, now, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"early", "late", "due"}, ids(got))
+	got, err = store.ListExpired(ctx, now, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"early", "late"}, ids(got))
+}
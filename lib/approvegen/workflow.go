@@ -0,0 +1,240 @@
+package approvegen
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// MethodArg 生成的方法参数结构体, 例如 *_UserServiceMethodCreate
+type MethodArg interface {
+	MethodName() string
+}
+
+// CtxCapturer 带有 ctx::capture 注释的参数结构体, 提交时保存 ctx 中的值, 执行时由生成的 Call 写回
+type CtxCapturer interface {
+	CaptureCtx(ctx context.Context)
+}
+
+// SubmitOptions 提交审批请求的可选参数
+type SubmitOptions struct {
+	Requester string
+	TTL       time.Duration // 为 0 时使用方法的 policy::ttl, 没有声明或 < 0 表示永不过期
+}
+
+// Dispatcher 按方法名解析参数并调用审批回调, Callers 和 *Registry 都实现了该接口
+type Dispatcher interface {
+	UnmarshalMethodArgs(method string, content string) (any, Caller, error)
+	CallDecision(ctx context.Context, method, content string, d Decision) (any, error)
+}
+
+// Workflow 审批流程: 提交请求, 审批通过/拒绝时调用 Dispatcher.CallDecision
+type Workflow struct {
+	store   Store
+	callers Dispatcher
+	codec   Codec
+	runner  *Runner
+	now     func() time.Time
+	newID   func() string
+}
+
+func NewWorkflow(store Store, callers Dispatcher) *Workflow {
+	return &Workflow{
+		store:   store,
+		callers: callers,
+		codec:   JSON,
+		now:     time.Now,
+		newID:   newRequestID,
+	}
+}
+
+// WithCodec 设置 Submit 序列化参数的方式, 需要与 approveGen -codec 一致, 默认为 JSON
+func (w *Workflow) WithCodec(codec Codec) *Workflow {
+	w.codec = codec
+	return w
+}
+
+// WithRunner 审批后通过 Runner 执行回调: 记录执行结果, 失败时可以重试, 默认直接调用;
+// 执行记录通过 Store.TransitionWithExecution 与审批结果一起写入, r 需要使用同一个存储
+func (w *Workflow) WithRunner(r *Runner) *Workflow {
+	w.runner = r
+	return w
+}
+
+// Submit 提交一个待审批的方法调用
+func (w *Workflow) Submit(ctx context.Context, arg MethodArg, opts SubmitOptions) (*Request, error) {
+	if c, ok := arg.(CtxCapturer); ok {
+		c.CaptureCtx(ctx)
+	}
+	content, err := w.codec.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+	return w.SubmitRaw(ctx, arg.MethodName(), string(content), opts)
+}
+
+// SubmitRaw 提交一个待审批的方法调用, content 为参数结构体序列化后的内容
+func (w *Workflow) SubmitRaw(ctx context.Context, method, content string, opts SubmitOptions) (*Request, error) {
+	// 提交时校验方法和参数, 避免审批通过后才发现无法调用
+	arg, _, err := w.callers.UnmarshalMethodArgs(method, content)
+	if err != nil {
+		return nil, err
+	}
+	if expiry, ok := expiryOf(arg); ok && opts.TTL == 0 {
+		opts.TTL = expiry.TTL
+	}
+	now := w.now()
+	req := &Request{
+		ID:        w.newID(),
+		Method:    method,
+		Args:      content,
+		Requester: opts.Requester,
+		Status:    StatusPending,
+		CreatedAt: now,
+	}
+	if opts.TTL > 0 {
+		req.ExpiresAt = now.Add(opts.TTL)
+	}
+	if err := w.store.Create(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Get 查询请求
+func (w *Workflow) Get(ctx context.Context, id string) (*Request, error) {
+	return w.store.Get(ctx, id)
+}
+
+// Approve 审批通过并调用 XxxHookApproved
+func (w *Workflow) Approve(ctx context.Context, id string) (any, error) {
+	return w.Decide(ctx, id, Decision{Approved: true})
+}
+
+// Reject 审批拒绝并调用 XxxHookRejected
+func (w *Workflow) Reject(ctx context.Context, id string) (any, error) {
+	return w.Decide(ctx, id, Decision{Approved: false})
+}
+
+// Cancel 由申请人撤销请求, 不会调用任何方法
+func (w *Workflow) Cancel(ctx context.Context, id string) error {
+	req, err := w.pending(ctx, id)
+	if err != nil {
+		return err
+	}
+	return w.store.Transition(ctx, req.ID, StatusPending, StatusCancelled, w.now())
+}
+
+// Expire 立即处理已过期的请求, 与 Sweeper 相同: 按参数的 Expiry().OnExpire 以系统的名义通过/拒绝并调用回调,
+// 没有声明时只标记为 expired; 未过期时返回 ErrInvalidTransition
+func (w *Workflow) Expire(ctx context.Context, id string) error {
+	req, err := w.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if req.Status != StatusPending {
+		return fmt.Errorf("%w: %s is %s", ErrInvalidTransition, id, req.Status)
+	}
+	now := w.now()
+	if !req.ExpiredAt(now) {
+		return fmt.Errorf("%w: %s has not expired", ErrInvalidTransition, id)
+	}
+	_, err = w.expire(ctx, req, now)
+	return err
+}
+
+// Decide 根据 d.Approved 审批通过或拒绝, d.Approver/d.Comment 传给回调, RequestID 和 DecidedAt 由 Workflow 填写;
+// d.Role 不在方法 Policy.Roles 中时返回 ErrRoleNotAllowed. 每次通过都会记录 Approval,
+// 达到 Policy.Quorum 后才调用回调, 之前返回 ErrQuorumNotReached; 一次拒绝即结束审批.
+// 先通过 Store.Transition 抢占状态, 保证同一请求只会被调用一次
+func (w *Workflow) Decide(ctx context.Context, id string, d Decision) (any, error) {
+	req, err := w.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	arg, caller, err := w.callers.UnmarshalMethodArgs(req.Method, req.Args)
+	if err != nil {
+		return nil, err
+	}
+	policy := policyOf(caller, arg)
+	if !policy.AllowsRole(d.Role) {
+		return nil, fmt.Errorf("%w: %q cannot decide %s", ErrRoleNotAllowed, d.Role, id)
+	}
+	d.DecidedAt = w.now()
+	if d.Approved {
+		approvals, err := w.store.AddApproval(ctx, id, Approval{Approver: d.Approver, Role: d.Role, At: d.DecidedAt})
+		if err != nil {
+			return nil, err
+		}
+		if !policy.Satisfied(approvals) {
+			return nil, fmt.Errorf("%w: %s has %d of %d approvals", ErrQuorumNotReached, id, policy.Count(approvals), max(policy.Quorum, 1))
+		}
+	}
+	return w.execute(ctx, req, d)
+}
+
+// execute 将 pending 的请求转换为 d 对应的状态后调用回调, Decide 和 Sweeper 共用
+func (w *Workflow) execute(ctx context.Context, req *Request, d Decision) (any, error) {
+	to := StatusRejected
+	if d.Approved {
+		to = StatusApproved
+	}
+	d.RequestID = req.ID
+	if w.runner != nil {
+		// 状态和已抢占第一次执行的记录在同一事务中写入, 执行期间 RetryDue 不会重复执行;
+		// 回调失败或进程退出后由 Runner.RetryDue 重新执行
+		e := w.runner.newExecution(req, d)
+		if err := w.store.TransitionWithExecution(ctx, req.ID, StatusPending, to, d.DecidedAt, e); err != nil {
+			return nil, err
+		}
+		return w.runner.execute(ctx, e)
+	}
+	if err := w.store.Transition(ctx, req.ID, StatusPending, to, d.DecidedAt); err != nil {
+		return nil, err
+	}
+	ret, err := w.callers.CallDecision(WithIdempotencyKey(ctx, IdempotencyKey(req.ID)), req.Method, req.Args, d)
+	if err != nil {
+		return ret, callError{err}
+	}
+	return ret, nil
+}
+
+// expire 按 Expiry().OnExpire 处理已过期的 pending 请求, Expire 和 Sweeper 共用;
+// 参数无法解析时(例如方法已经删除)只标记为 expired
+func (w *Workflow) expire(ctx context.Context, req *Request, now time.Time) (any, error) {
+	arg, _, err := w.callers.UnmarshalMethodArgs(req.Method, req.Args)
+	if err != nil {
+		return nil, w.store.Transition(ctx, req.ID, StatusPending, StatusExpired, now)
+	}
+	expiry, _ := expiryOf(arg)
+	d, ok := expiry.Decision()
+	if !ok {
+		return nil, w.store.Transition(ctx, req.ID, StatusPending, StatusExpired, now)
+	}
+	d.DecidedAt = now
+	return w.execute(ctx, req, d)
+}
+
+// pending 查询待审批的请求, 已过期时返回 ErrRequestExpired;
+// 状态保持 pending, 由 Sweeper 或 Expire 按 OnExpire 处理
+func (w *Workflow) pending(ctx context.Context, id string) (*Request, error) {
+	req, err := w.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != StatusPending {
+		return nil, fmt.Errorf("%w: %s is %s", ErrInvalidTransition, id, req.Status)
+	}
+	if req.ExpiredAt(w.now()) {
+		return nil, fmt.Errorf("%w: %s", ErrRequestExpired, id)
+	}
+	return req, nil
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,178 @@
+package approvegen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowApproveReject(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, caller := env.w, env.caller
+
+	req, err := w.Submit(ctx, &createArgs{Name: "bob"}, SubmitOptions{Requester: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, req.Status)
+	assert.Equal(t, "UserService_Create", req.Method)
+	assert.JSONEq(t, `{"Name":"bob"}`, req.Args)
+
+	ret, err := w.Approve(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", ret)
+
+	// 终态不能再次审批
+	_, err = w.Reject(ctx, req.ID)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+
+	req2, err := w.Submit(ctx, &createArgs{Name: "tom"}, SubmitOptions{})
+	require.NoError(t, err)
+	_, err = w.Reject(ctx, req2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, caller.calls)
+
+	got, err := w.Get(ctx, req2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, got.Status)
+	assert.False(t, got.DecidedAt.IsZero())
+}
+
+func TestWorkflowExpireCancel(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, caller, now := env.w, env.caller, env.now
+
+	req, err := w.Submit(ctx, &createArgs{Name: "bob"}, SubmitOptions{TTL: time.Hour})
+	require.NoError(t, err)
+	assert.ErrorIs(t, w.Expire(ctx, req.ID), ErrInvalidTransition)
+
+	// 过期后不能审批, 状态保持 pending, 由 Expire/Sweeper 按 OnExpire 处理
+	*now = now.Add(time.Hour)
+	_, err = w.Approve(ctx, req.ID)
+	assert.ErrorIs(t, err, ErrRequestExpired)
+	assert.ErrorIs(t, w.Cancel(ctx, req.ID), ErrRequestExpired)
+	got, err := w.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, got.Status)
+	require.NoError(t, w.Expire(ctx, req.ID))
+	got, err = w.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, got.Status)
+	assert.ErrorIs(t, w.Expire(ctx, req.ID), ErrInvalidTransition)
+
+	req2, err := w.Submit(ctx, &createArgs{Name: "tom"}, SubmitOptions{})
+	require.NoError(t, err)
+	require.NoError(t, w.Cancel(ctx, req2.ID))
+	_, err = w.Approve(ctx, req2.ID)
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+	assert.Empty(t, caller.calls)
+}
+
+func TestWorkflowSubmitUnknownMethod(t *testing.T) {
+	w := newTestEnv(nil).w
+	_, err := w.SubmitRaw(context.Background(), "Unknown", "{}", SubmitOptions{})
+	assert.ErrorIs(t, err, ErrUnknownMethod)
+
+	_, err = w.Approve(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrRequestNotFound)
+}
+
+type tenantKey struct{}
+
+// captureArgs 模拟 ctx::capture 生成的 CaptureCtx
+type captureArgs struct {
+	createArgs
+	Ctx struct {
+		Tenant string `json:"tenant"`
+	} `json:"__ctx"`
+}
+
+func (p *captureArgs) CaptureCtx(ctx context.Context) {
+	p.Ctx.Tenant, _ = ctx.Value(tenantKey{}).(string)
+}
+
+func TestWorkflowSubmitCapturesCtx(t *testing.T) {
+	w := newTestEnv(nil).w
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	req, err := w.Submit(ctx, &captureArgs{createArgs: createArgs{Name: "tom"}}, SubmitOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Name":"tom","__ctx":{"tenant":"acme"}}`, req.Args)
+}
+
+func TestMemoryStoreListByStatus(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	base := testNow
+	for i, id := range []string{"c", "a", "b"} {
+		require.NoError(t, s.Create(ctx, &Request{ID: id, Status: StatusPending, CreatedAt: base.Add(time.Duration(i) * time.Minute)}))
+	}
+	require.NoError(t, s.Transition(ctx, "a", StatusPending, StatusApproved, base))
+
+	list, err := s.ListByStatus(ctx, StatusPending, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "c", list[0].ID)
+	assert.Equal(t, "b", list[1].ID)
+
+	list, err = s.ListByStatus(ctx, StatusPending, 1)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestWorkflowQuorum(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, caller := env.w, env.caller
+	caller.policy = &Policy{Quorum: 2, Roles: []string{"finance", "admin"}, Distinct: true}
+
+	req, err := w.Submit(ctx, &createArgs{Name: "bob"}, SubmitOptions{})
+	require.NoError(t, err)
+
+	_, err = w.Decide(ctx, req.ID, Decision{Approved: true, Approver: "eve", Role: "sales"})
+	assert.ErrorIs(t, err, ErrRoleNotAllowed)
+
+	// quorum=2 时第一次通过只记录, 不执行
+	_, err = w.Decide(ctx, req.ID, Decision{Approved: true, Approver: "alice", Role: "finance"})
+	assert.ErrorIs(t, err, ErrQuorumNotReached)
+	assert.Empty(t, caller.calls)
+	got, err := w.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, got.Status)
+
+	// distinct: 同一审批人只计一次
+	_, err = w.Decide(ctx, req.ID, Decision{Approved: true, Approver: "alice", Role: "finance"})
+	assert.ErrorIs(t, err, ErrQuorumNotReached)
+	assert.Empty(t, caller.calls)
+
+	ret, err := w.Decide(ctx, req.ID, Decision{Approved: true, Approver: "bob", Role: "admin"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", ret)
+	assert.Equal(t, []bool{true}, caller.calls)
+	assert.Equal(t, "bob", caller.decisions[0].Approver)
+
+	_, err = w.Decide(ctx, req.ID, Decision{Approved: true, Approver: "carol", Role: "admin"})
+	assert.ErrorIs(t, err, ErrInvalidTransition)
+}
+
+func TestWorkflowQuorumReject(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(nil)
+	w, caller := env.w, env.caller
+	caller.policy = &Policy{Quorum: 2}
+
+	req, err := w.Submit(ctx, &createArgs{Name: "bob"}, SubmitOptions{})
+	require.NoError(t, err)
+	_, err = w.Approve(ctx, req.ID)
+	assert.ErrorIs(t, err, ErrQuorumNotReached)
+
+	// 一次拒绝即结束审批
+	_, err = w.Reject(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false}, caller.calls)
+	got, err := w.Get(ctx, req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRejected, got.Status)
+}
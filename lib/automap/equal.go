@@ -0,0 +1,26 @@
+package automap
+
+import "reflect"
+
+// Equal automap 生成的 DiffPatch 比较新旧快照中的一列
+// 类型有 Equal(T) bool 方法时使用该方法(time.Time 的时区和单调时钟、decimal.Decimal 的 1.0 与 1.00 不影响结果),
+// 指针比较指向的值, 其他类型使用 reflect.DeepEqual
+func Equal[T any](a, b T) bool {
+	return equal(reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem())
+}
+
+func equal(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Pointer {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return equal(a.Elem(), b.Elem())
+	}
+	if m := a.MethodByName("Equal"); m.IsValid() {
+		t := m.Type()
+		if t.NumIn() == 1 && t.In(0) == a.Type() && t.NumOut() == 1 && t.Out(0).Kind() == reflect.Bool {
+			return m.Call([]reflect.Value{b})[0].Bool()
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
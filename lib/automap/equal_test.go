@@ -0,0 +1,26 @@
+package automap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEqual(t *testing.T) {
+	at := time.Date(2025, 1, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	assert.True(t, Equal(at, at.UTC()))
+	assert.False(t, Equal(at, at.Add(time.Second)))
+	utc := at.UTC()
+	assert.True(t, Equal(&at, &utc))
+	assert.False(t, Equal(&at, nil))
+	assert.True(t, Equal[*time.Time](nil, nil))
+
+	assert.True(t, Equal(decimal.RequireFromString("1.0"), decimal.RequireFromString("1.00")))
+	assert.False(t, Equal(decimal.RequireFromString("1.0"), decimal.RequireFromString("1.01")))
+
+	assert.True(t, Equal([]string{"a"}, []string{"a"}))
+	assert.False(t, Equal(map[string]int{"a": 1}, map[string]int{"a": 2}))
+	assert.True(t, Equal(1, 1))
+}
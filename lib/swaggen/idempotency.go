@@ -0,0 +1,138 @@
+package swaggen
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyHeader @IDEMPOTENT 未指定 header 时使用的请求头
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+// DefaultIdempotencyTTL @IDEMPOTENT 未指定 ttl 时使用的有效期
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// Idempotency 描述 @IDEMPOTENT(header=Idempotency-Key; ttl=24h) 注释的幂等策略
+type Idempotency struct {
+	Header string        // 携带幂等键的请求头
+	TTL    time.Duration // 幂等键有效期
+}
+
+// IdempotencyStore 幂等键存储，可替换为 redis 等分布式实现
+type IdempotencyStore interface {
+	// Reserve 占用 key，key 已存在且未过期时返回 false
+	Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release 释放 key，用于请求失败后允许客户端重试
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyKeyFunc 生成幂等键存储中使用的 key, value 为请求头中的幂等键
+type IdempotencyKeyFunc func(req *http.Request, clientIP, route, value string) string
+
+// DefaultIdempotencyKey 默认的幂等键: 路由 + 调用方 + 幂等键, 不同调用方使用相同的幂等键互不影响
+func DefaultIdempotencyKey(req *http.Request, clientIP, route, value string) string {
+	return route + "|" + CallerOf(req, clientIP) + "|" + value
+}
+
+// CallerOf 返回请求的调用方标识
+// 优先使用 Authorization 请求头(只保留摘要，避免凭证落入存储)，没有时回退到 clientIP
+func CallerOf(req *http.Request, clientIP string) string {
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		return "auth:" + hex.EncodeToString(sum[:16])
+	}
+	if clientIP == "" {
+		clientIP, _, _ = net.SplitHostPort(req.RemoteAddr)
+	}
+	return "ip:" + clientIP
+}
+
+// ParseIdempotency 解析幂等配置, ttl 为 time.Duration 格式，额外支持 d 作为天
+func ParseIdempotency(header, ttl string) (Idempotency, error) {
+	ret := Idempotency{
+		Header: strings.TrimSpace(header),
+		TTL:    DefaultIdempotencyTTL,
+	}
+	if ret.Header == "" {
+		ret.Header = DefaultIdempotencyHeader
+	}
+	if ttl = strings.TrimSpace(ttl); ttl != "" {
+		d, err := parseTTL(ttl)
+		if err != nil || d <= 0 {
+			return Idempotency{}, fmt.Errorf("invalid idempotency ttl %q", ttl)
+		}
+		ret.TTL = d
+	}
+	return ret, nil
+}
+
+// MustParseIdempotency 同 ParseIdempotency，出错时 panic
+func MustParseIdempotency(header, ttl string) Idempotency {
+	ret, err := ParseIdempotency(header, ttl)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+func parseTTL(ttl string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(ttl, "d"); ok {
+		d, err := time.ParseDuration(days + "h")
+		return d * 24, err
+	}
+	return time.ParseDuration(ttl)
+}
+
+// MemoryIdempotencyStore 进程内幂等键存储
+type MemoryIdempotencyStore struct {
+	mu     sync.Mutex
+	keys   map[string]time.Time
+	lastGC time.Time
+	now    func() time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		keys: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.gc(now)
+	if expireAt, ok := s.keys[key]; ok && now.Before(expireAt) {
+		return false, nil
+	}
+	s.keys[key] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+	return nil
+}
+
+// gc 清理已过期的 key；最多每分钟执行一次
+func (s *MemoryIdempotencyStore) gc(now time.Time) {
+	if now.Sub(s.lastGC) < time.Minute {
+		return
+	}
+	s.lastGC = now
+	for k, expireAt := range s.keys {
+		if !now.Before(expireAt) {
+			delete(s.keys, k)
+		}
+	}
+}
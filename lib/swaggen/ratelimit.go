@@ -0,0 +1,177 @@
+package swaggen
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit 描述 @RATELIMIT(100/min; key=header:X-Api-Key) 注释的限流策略
+type RateLimit struct {
+	Limit  int           // 窗口内允许的请求数
+	Window time.Duration // 窗口长度
+	Key    string        // 限流键来源: ip, header:<Name>, query:<Name>
+}
+
+// RateLimitResult 一次限流判定的结果
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	ResetAt   time.Time
+}
+
+// RateLimitStore 限流计数存储，可替换为 redis 等分布式实现
+type RateLimitStore interface {
+	// Allow 对 key 计数一次，并返回当前窗口内是否放行
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error)
+}
+
+var rateUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "second": time.Second,
+	"m": time.Minute, "min": time.Minute, "minute": time.Minute,
+	"h": time.Hour, "hour": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour,
+}
+
+// ParseRateLimit 解析限流表达式
+// rate 格式: <次数>/<单位>，单位支持 s|sec|second|m|min|minute|h|hour|d|day 或 time.Duration 格式(如 30s)
+// key 格式: ip(默认) | header:<Name> | query:<Name>
+func ParseRateLimit(rate, key string) (RateLimit, error) {
+	count, unit, ok := strings.Cut(strings.TrimSpace(rate), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate %q, expected <count>/<unit>", rate)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || limit <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate %q, count must be a positive integer", rate)
+	}
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	window, ok := rateUnits[unit]
+	if !ok {
+		window, err = time.ParseDuration(unit)
+		if err != nil || window <= 0 {
+			return RateLimit{}, fmt.Errorf("invalid rate %q, unknown unit %q", rate, unit)
+		}
+	}
+	key = strings.TrimSpace(key)
+	if err := validateKey(key); err != nil {
+		return RateLimit{}, err
+	}
+	return RateLimit{Limit: limit, Window: window, Key: key}, nil
+}
+
+// MustParseRateLimit 同 ParseRateLimit，出错时 panic
+func MustParseRateLimit(rate, key string) RateLimit {
+	ret, err := ParseRateLimit(rate, key)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+func validateKey(key string) error {
+	if key == "" || key == "ip" {
+		return nil
+	}
+	source, name, ok := strings.Cut(key, ":")
+	if !ok || strings.TrimSpace(name) == "" || (source != "header" && source != "query") {
+		return fmt.Errorf("invalid rate limit key %q, expected ip, header:<Name> or query:<Name>", key)
+	}
+	return nil
+}
+
+// HeaderName 如果限流键来源于请求头，返回请求头名称
+func (r RateLimit) HeaderName() string {
+	if name, ok := strings.CutPrefix(r.Key, "header:"); ok {
+		return strings.TrimSpace(name)
+	}
+	return ""
+}
+
+// KeyOf 从请求中提取限流键，取不到时回退到 clientIP
+func (r RateLimit) KeyOf(req *http.Request, clientIP string) string {
+	source, name, _ := strings.Cut(r.Key, ":")
+	name = strings.TrimSpace(name)
+	var value string
+	switch source {
+	case "header":
+		value = req.Header.Get(name)
+	case "query":
+		value = req.URL.Query().Get(name)
+	}
+	if value != "" {
+		return source + ":" + value
+	}
+	if clientIP == "" {
+		clientIP, _, _ = net.SplitHostPort(req.RemoteAddr)
+	}
+	return "ip:" + clientIP
+}
+
+// WriteHeaders 写入 X-RateLimit-* 以及被拒绝时的 Retry-After 响应头
+func (r RateLimit) WriteHeaders(w http.ResponseWriter, res RateLimitResult) {
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(r.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(res.ResetAt.Unix(), 10))
+	if !res.Allowed {
+		seconds := int(time.Until(res.ResetAt).Seconds() + 0.999)
+		h.Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+	}
+}
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// MemoryRateLimitStore 基于固定窗口的进程内限流存储
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+	lastGC  time.Time
+	now     func() time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		windows: make(map[string]*rateWindow),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		s.gc(now)
+		w = &rateWindow{resetAt: now.Add(window)}
+		s.windows[key] = w
+	}
+	if w.count >= limit {
+		return RateLimitResult{Allowed: false, Remaining: 0, ResetAt: w.resetAt}, nil
+	}
+	w.count++
+	return RateLimitResult{Allowed: true, Remaining: limit - w.count, ResetAt: w.resetAt}, nil
+}
+
+// gc 清理已过期的窗口，避免 key 无限增长；最多每分钟执行一次
+func (s *MemoryRateLimitStore) gc(now time.Time) {
+	if now.Sub(s.lastGC) < time.Minute {
+		return
+	}
+	s.lastGC = now
+	for k, w := range s.windows {
+		if !now.Before(w.resetAt) {
+			delete(s.windows, k)
+		}
+	}
+}
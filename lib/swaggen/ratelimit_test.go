@@ -0,0 +1,126 @@
+package swaggen
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		rate, key string
+		limit     int
+		window    time.Duration
+		wantErr   bool
+	}{
+		{rate: "100/min", key: "header:X-Api-Key", limit: 100, window: time.Minute},
+		{rate: "10/s", limit: 10, window: time.Second},
+		{rate: "5 / hour", key: "ip", limit: 5, window: time.Hour},
+		{rate: "3/30s", key: "query:token", limit: 3, window: 30 * time.Second},
+		{rate: "100", wantErr: true},
+		{rate: "0/min", wantErr: true},
+		{rate: "10/week", wantErr: true},
+		{rate: "10/min", key: "cookie:sid", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRateLimit(tt.rate, tt.key)
+		if tt.wantErr {
+			assert.Error(t, err, tt.rate)
+			continue
+		}
+		require.NoError(t, err, tt.rate)
+		assert.Equal(t, tt.limit, got.Limit)
+		assert.Equal(t, tt.window, got.Window)
+	}
+}
+
+func TestRateLimitKeyOf(t *testing.T) {
+	limit := MustParseRateLimit("1/min", "header:X-Api-Key")
+	req := httptest.NewRequest("GET", "/?token=abc", nil)
+	assert.Equal(t, "ip:1.2.3.4", limit.KeyOf(req, "1.2.3.4"))
+	req.Header.Set("X-Api-Key", "k1")
+	assert.Equal(t, "header:k1", limit.KeyOf(req, "1.2.3.4"))
+	assert.Equal(t, "X-Api-Key", limit.HeaderName())
+
+	limit = MustParseRateLimit("1/min", "query:token")
+	assert.Equal(t, "query:abc", limit.KeyOf(req, ""))
+	assert.Equal(t, "", limit.HeaderName())
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		res, err := store.Allow(ctx, "k", 2, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 1-i, res.Remaining)
+	}
+	res, _ := store.Allow(ctx, "k", 2, time.Minute)
+	assert.False(t, res.Allowed)
+	res, _ = store.Allow(ctx, "other", 2, time.Minute)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(time.Minute)
+	res, _ = store.Allow(ctx, "k", 2, time.Minute)
+	assert.True(t, res.Allowed)
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	ok, _ := store.Reserve(ctx, "k", time.Hour)
+	assert.True(t, ok)
+	ok, _ = store.Reserve(ctx, "k", time.Hour)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Release(ctx, "k"))
+	ok, _ = store.Reserve(ctx, "k", time.Hour)
+	assert.True(t, ok)
+
+	now = now.Add(time.Hour)
+	ok, _ = store.Reserve(ctx, "k", time.Hour)
+	assert.True(t, ok)
+}
+
+func TestParseIdempotency(t *testing.T) {
+	got, err := ParseIdempotency("", "")
+	require.NoError(t, err)
+	assert.Equal(t, Idempotency{Header: DefaultIdempotencyHeader, TTL: DefaultIdempotencyTTL}, got)
+
+	got, err = ParseIdempotency("X-Request-Id", "2d")
+	require.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, got.TTL)
+
+	_, err = ParseIdempotency("", "abc")
+	assert.Error(t, err)
+}
+
+func TestDefaultIdempotencyKey(t *testing.T) {
+	alice := httptest.NewRequest("POST", "/order", nil)
+	bob := httptest.NewRequest("POST", "/order", nil)
+
+	// 不同调用方使用相同的幂等键不会相互冲突
+	assert.NotEqual(t,
+		DefaultIdempotencyKey(alice, "1.1.1.1", "IOrderAPI.CreateOrder", "k1"),
+		DefaultIdempotencyKey(bob, "2.2.2.2", "IOrderAPI.CreateOrder", "k1"))
+	assert.Equal(t, "IOrderAPI.CreateOrder|ip:1.1.1.1|k1", DefaultIdempotencyKey(alice, "1.1.1.1", "IOrderAPI.CreateOrder", "k1"))
+
+	// 同一出口 IP 下以 Authorization 区分调用方，且不保存原始凭证
+	alice.Header.Set("Authorization", "Bearer alice")
+	bob.Header.Set("Authorization", "Bearer bob")
+	aliceKey := DefaultIdempotencyKey(alice, "1.1.1.1", "IOrderAPI.CreateOrder", "k1")
+	assert.NotEqual(t, aliceKey, DefaultIdempotencyKey(bob, "1.1.1.1", "IOrderAPI.CreateOrder", "k1"))
+	assert.Equal(t, aliceKey, DefaultIdempotencyKey(alice, "3.3.3.3", "IOrderAPI.CreateOrder", "k1"))
+	assert.NotContains(t, aliceKey, "alice")
+}
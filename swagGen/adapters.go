@@ -140,6 +140,8 @@ func newTagParserSafe() (*parsers.Parser, error) {
 		parsers.Security{},
 		parsers.Header{},
 		parsers.MiddleWare{},
+		parsers.RateLimit{},
+		parsers.Idempotent{},
 
 		parsers.JsonReq{},
 		parsers.FormReq{},
@@ -55,6 +55,12 @@ func (g *GinGenerator) GenerateGinCode(comments map[string]string) (constructCod
 		parts = append(parts, bindMethodCode)
 		parts = append(parts, "")
 
+		// 生成限流/幂等中间件
+		if trafficCode := g.generateTrafficMethods(iface); trafficCode != "" {
+			parts = append(parts, trafficCode)
+			parts = append(parts, "")
+		}
+
 		// 为每个方法生成处理器方法
 		for _, method := range iface.Methods {
 			// 添加注释 - 使用接口名+方法名作为键
@@ -148,7 +154,10 @@ type {{.WrapperName}} struct {
 func {{.ConstructorName}}(inner {{.InterfaceName}}, handler {{.HandlerName}}) *{{.WrapperName}} {
     return &{{.WrapperName}}{
         inner: inner,
-        handler: handler,
+        handler: handler,{{if .RateLimit}}
+        rateLimitStore: swaggen.NewMemoryRateLimitStore(),{{end}}{{if .Idempotent}}
+        idempotencyStore: swaggen.NewMemoryIdempotencyStore(),
+        idempotencyKey: swaggen.DefaultIdempotencyKey,{{end}}
     }
 }
 `
@@ -158,6 +167,8 @@ func {{.ConstructorName}}(inner {{.InterfaceName}}, handler {{.HandlerName}}) *{
 		"WrapperName":     wrapperName,
 		"InterfaceName":   iface.Name,
 		"HandlerName":     handlerItfName,
+		"RateLimit":       iface.HasRateLimit(),
+		"Idempotent":      iface.HasIdempotent(),
 	}
 
 	constructorResult := utils.MustExecuteTemplate(data1, template1)
@@ -165,13 +176,18 @@ func {{.ConstructorName}}(inner {{.InterfaceName}}, handler {{.HandlerName}}) *{
 	template := `
 type {{.WrapperName}} struct {
     inner {{.InterfaceName}}
-    handler {{.HandlerName}}
+    handler {{.HandlerName}}{{if .RateLimit}}
+    rateLimitStore swaggen.RateLimitStore{{end}}{{if .Idempotent}}
+    idempotencyStore swaggen.IdempotencyStore
+    idempotencyKey swaggen.IdempotencyKeyFunc{{end}}
 }
 `
 	data := map[string]interface{}{
 		"WrapperName":   wrapperName,
 		"InterfaceName": iface.Name,
 		"HandlerName":   handlerItfName,
+		"RateLimit":     iface.HasRateLimit(),
+		"Idempotent":    iface.HasIdempotent(),
 	}
 
 	result := utils.MustExecuteTemplate(data, template)
@@ -274,6 +290,8 @@ func (a *{{.WrapperName}}) {{.BindMethodName}}(router gin.IRoutes, preHandlers .
 		{{range $.Handlers}}handlers = append(handlers, a.handler.{{.}}()...)
 		{{end -}}
 	}
+	{{range $.Traffic}}handlers = append(handlers, {{.}})
+	{{end -}}
 	a.bind(router, "{{$.HTTPMethod}}", "{{.}}", preHandlers, handlers, a.{{$.HandlerMethodName}}){{end}}
 }
 `
@@ -284,6 +302,7 @@ func (a *{{.WrapperName}}) {{.BindMethodName}}(router gin.IRoutes, preHandlers .
 		"Handlers": lo.Uniq(lo.Flatten(lo.Map(middlewares, func(item *parsers.MiddleWare, index int) []string {
 			return item.Value
 		}))),
+		"Traffic":           g.trafficHandlers(iface, method),
 		"HTTPMethod":        method.GetHTTPMethod(),
 		"GinPath":           ginPaths,
 		"HandlerMethodName": handlerMethodName,
@@ -307,6 +326,100 @@ func (a *{{.WrapperName}}) {{.BindMethodName}}(router gin.IRoutes, preHandlers .
 	//	}
 }
 
+// trafficHandlers 生成方法的限流/幂等中间件调用，限流先于幂等执行，避免被限流的请求占用幂等键
+func (g *GinGenerator) trafficHandlers(iface SwaggerInterface, method SwaggerMethod) []string {
+	var ret []string
+	route := fmt.Sprintf("%s.%s", iface.Name, method.Name)
+	if v := method.GetRateLimit(iface); v != nil {
+		ret = append(ret, fmt.Sprintf("a.rateLimit(%q, %q, %q)", route, v.Value, v.Key))
+	}
+	if v := method.GetIdempotent(iface); v != nil {
+		ret = append(ret, fmt.Sprintf("a.idempotent(%q, %q, %q)", route, v.Header, v.TTL))
+	}
+	return ret
+}
+
+// generateTrafficMethods 生成限流/幂等中间件以及存储的设置方法
+func (g *GinGenerator) generateTrafficMethods(iface SwaggerInterface) string {
+	var parts []string
+	data := map[string]interface{}{
+		"WrapperName": iface.GetWrapperName(),
+	}
+
+	if iface.HasRateLimit() {
+		template := `
+// SetRateLimitStore 替换默认的进程内限流存储
+func (a *{{.WrapperName}}) SetRateLimitStore(store swaggen.RateLimitStore) *{{.WrapperName}} {
+    a.rateLimitStore = store
+    return a
+}
+
+func (a *{{.WrapperName}}) rateLimit(route, rate, key string) gin.HandlerFunc {
+    limit := swaggen.MustParseRateLimit(rate, key)
+    return func(ctx *gin.Context) {
+        res, err := a.rateLimitStore.Allow(ctx.Request.Context(), route+"|"+limit.KeyOf(ctx.Request, ctx.ClientIP()), limit.Limit, limit.Window)
+        if err != nil {
+            ctx.AbortWithStatusJSON(500, gin.H{"error": err.Error()})
+            return
+        }
+        limit.WriteHeaders(ctx.Writer, res)
+        if !res.Allowed {
+            ctx.AbortWithStatusJSON(429, gin.H{"error": "too many requests"})
+            return
+        }
+        ctx.Next()
+    }
+}
+`
+		parts = append(parts, strings.TrimSpace(utils.MustExecuteTemplate(data, template)))
+	}
+
+	if iface.HasIdempotent() {
+		template := `
+// SetIdempotencyStore 替换默认的进程内幂等存储
+func (a *{{.WrapperName}}) SetIdempotencyStore(store swaggen.IdempotencyStore) *{{.WrapperName}} {
+    a.idempotencyStore = store
+    return a
+}
+
+// SetIdempotencyKey 替换默认的幂等键生成方式，默认按调用方隔离幂等键
+func (a *{{.WrapperName}}) SetIdempotencyKey(fn swaggen.IdempotencyKeyFunc) *{{.WrapperName}} {
+    a.idempotencyKey = fn
+    return a
+}
+
+func (a *{{.WrapperName}}) idempotent(route, header, ttl string) gin.HandlerFunc {
+    policy := swaggen.MustParseIdempotency(header, ttl)
+    return func(ctx *gin.Context) {
+        value := ctx.GetHeader(policy.Header)
+        if value == "" {
+            ctx.AbortWithStatusJSON(400, gin.H{"error": "missing header " + policy.Header})
+            return
+        }
+        key := a.idempotencyKey(ctx.Request, ctx.ClientIP(), route, value)
+        ok, err := a.idempotencyStore.Reserve(ctx.Request.Context(), key, policy.TTL)
+        if err != nil {
+            ctx.AbortWithStatusJSON(500, gin.H{"error": err.Error()})
+            return
+        }
+        if !ok {
+            ctx.AbortWithStatusJSON(409, gin.H{"error": "duplicate request"})
+            return
+        }
+        ctx.Next()
+        // 服务端错误时释放幂等键，允许客户端重试
+        if ctx.Writer.Status() >= 500 {
+            _ = a.idempotencyStore.Release(ctx.Request.Context(), key)
+        }
+    }
+}
+`
+		parts = append(parts, strings.TrimSpace(utils.MustExecuteTemplate(data, template)))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
 // generateParameterBinding 生成参数绑定代码
 func (g *GinGenerator) generateParameterBinding(iface SwaggerInterface, method SwaggerMethod) string {
 	var lines []string
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseTestInterfaces(t *testing.T, filename string) *InterfaceCollection {
+	t.Helper()
+	collection, err := NewInterfaceParser(NewEnhancedImportManager("")).ParseFile(filename)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	return collection
+}
+
+func assertContains(t *testing.T, code string, expected ...string) {
+	t.Helper()
+	for _, want := range expected {
+		if !strings.Contains(code, want) {
+			t.Errorf("Missing expected code: %s\ngot:\n%s", want, code)
+		}
+	}
+}
+
+// TestGenerateTrafficMethods 测试 @RATELIMIT/@IDEMPOTENT 生成的中间件以及在 Bind 中的挂载
+func TestGenerateTrafficMethods(t *testing.T) {
+	collection := parseTestInterfaces(t, "testdata/traffic/api.go")
+	constructCode, code := NewGinGenerator(collection).GenerateGinCode(nil)
+
+	// 默认使用进程内存储
+	assertContains(t, constructCode,
+		"rateLimitStore: swaggen.NewMemoryRateLimitStore(),",
+		"idempotencyStore: swaggen.NewMemoryIdempotencyStore(),",
+		"idempotencyKey: swaggen.DefaultIdempotencyKey,",
+	)
+	order, user, ok := strings.Cut(code, "type UserAPIWrap struct")
+	if !ok {
+		t.Fatalf("Missing UserAPIWrap, got:\n%s", code)
+	}
+	assertContains(t, order,
+		"rateLimitStore swaggen.RateLimitStore",
+		"idempotencyStore swaggen.IdempotencyStore",
+		"idempotencyKey swaggen.IdempotencyKeyFunc",
+		"func (a *OrderAPIWrap) SetIdempotencyKey(fn swaggen.IdempotencyKeyFunc) *OrderAPIWrap {",
+		// 幂等键按调用方隔离
+		"key := a.idempotencyKey(ctx.Request, ctx.ClientIP(), route, value)",
+		"func (a *OrderAPIWrap) SetRateLimitStore(store swaggen.RateLimitStore) *OrderAPIWrap {",
+		"func (a *OrderAPIWrap) SetIdempotencyStore(store swaggen.IdempotencyStore) *OrderAPIWrap {",
+		"func (a *OrderAPIWrap) rateLimit(route, rate, key string) gin.HandlerFunc {",
+		"func (a *OrderAPIWrap) idempotent(route, header, ttl string) gin.HandlerFunc {",
+		`ctx.AbortWithStatusJSON(400, gin.H{"error": "missing header " + policy.Header})`,
+		// 接口级别的限流对所有方法生效, 在 PreHandlers 之后、handler 之前执行
+		"handlers = append(handlers, a.handler.PreHandlers()...)\n\t\t}\n"+
+			"\thandlers = append(handlers, a.rateLimit(\"IOrderAPI.GetOrder\", \"100/min\", \"header:X-Api-Key\"))\n"+
+			"\ta.bind(router, \"GET\", \"/api/v1/order/:id\", preHandlers, handlers, a.GetOrder)",
+		// 限流先于幂等执行, 被限流的请求不占用幂等键
+		"\thandlers = append(handlers, a.rateLimit(\"IOrderAPI.CreateOrder\", \"100/min\", \"header:X-Api-Key\"))\n"+
+			"\thandlers = append(handlers, a.idempotent(\"IOrderAPI.CreateOrder\", \"Idempotency-Key\", \"24h\"))\n"+
+			"\ta.bind(router, \"POST\", \"/api/v1/order\", preHandlers, handlers, a.CreateOrder)",
+	)
+	if strings.Contains(order, `a.idempotent("IOrderAPI.GetOrder"`) {
+		t.Errorf("GetOrder should not be idempotent, got:\n%s", order)
+	}
+
+	// 没有流量控制注释的接口不生成存储和中间件
+	for _, unexpected := range []string{"rateLimitStore", "idempotencyStore", "idempotencyKey", "a.rateLimit(", "a.idempotent("} {
+		if strings.Contains(user, unexpected) {
+			t.Errorf("Unexpected %s for IUserAPI, got:\n%s", unexpected, user)
+		}
+	}
+}
+
+// TestGenerateTrafficSwagger 测试流量控制在 Swagger 注释中补充的请求头和失败响应
+func TestGenerateTrafficSwagger(t *testing.T) {
+	collection := parseTestInterfaces(t, "testdata/traffic/api.go")
+	comments := NewSwaggerGenerator(collection).GenerateSwaggerComments()
+
+	assertContains(t, comments["IOrderAPI.CreateOrder"],
+		`// @Param Idempotency-Key header string true`,
+		`// @Failure 400 {string} string "missing header Idempotency-Key"`,
+		`// @Failure 409 {string} string "duplicate request"`,
+		`// @Failure 429 {string} string "too many requests, limit 100/min"`,
+	)
+	get := comments["IOrderAPI.GetOrder"]
+	assertContains(t, get, `// @Failure 429 {string} string "too many requests, limit 100/min"`)
+	if strings.Contains(get, "@Failure 400") || strings.Contains(get, "@Failure 409") {
+		t.Errorf("GetOrder should not document idempotency failures, got:\n%s", get)
+	}
+}
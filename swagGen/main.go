@@ -126,6 +126,10 @@ func printUsage() {
   中间件注释:
     @MID(auth,log)             - 为方法添加中间件
 
+  流量控制注释（可用于方法或接口，方法级别优先）:
+    @RATELIMIT(100/min; key=header:X-Api-Key)      - 限流，超限返回 429，key 支持 ip|header:<Name>|query:<Name>
+    @IDEMPOTENT(header=Idempotency-Key; ttl=24h)   - 幂等，重复请求返回 409
+
   控制注释:
     @Removed                   - 移除方法（不生成代码）
     @ExcludeFromBindAll        - 排除在 BindAll 方法之外
@@ -28,6 +28,11 @@ type Definition interface {
 	Mode() ParseMode // 新增方法，返回该定义的解析模式
 }
 
+// Validator 可选接口，定义在字段填充完成后做语义校验
+type Validator interface {
+	Validate() error
+}
+
 // definitionInfo 存储了注册的结构体类型及其解析模式
 type definitionInfo struct {
 	Type reflect.Type
@@ -128,6 +133,11 @@ func (p *Parser) Parse(line string) (any, error) {
 	if err := p.validateStruct(newStructElem); err != nil {
 		return nil, fmt.Errorf("validation failed (tag: %s): %w", tagName, err)
 	}
+	if v, ok := newStructPtr.Interface().(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("validation failed (tag: %s): %w", tagName, err)
+		}
+	}
 
 	return newStructPtr.Interface(), nil
 }
@@ -1,5 +1,7 @@
 package parsers
 
+import "github.com/donutnomad/gotoolkit/lib/swaggen"
+
 type Tag struct {
 	Value string `sg:"required,delimiter=,"`
 }
@@ -173,3 +175,31 @@ type Prefix struct {
 
 func (s Prefix) Name() string    { return "PREFIX" }
 func (s Prefix) Mode() ParseMode { return ModePositional }
+
+/////////////////////// 流量控制 ///////////////////////
+
+// RateLimit 限流，例如 @RATELIMIT(100/min; key=header:X-Api-Key)
+type RateLimit struct {
+	Value string `sg:"required"` // <次数>/<单位>
+	Key   string // ip(默认) | header:<Name> | query:<Name>
+}
+
+func (s RateLimit) Name() string    { return "RATELIMIT" }
+func (s RateLimit) Mode() ParseMode { return ModeNamed }
+func (s *RateLimit) Validate() error {
+	_, err := swaggen.ParseRateLimit(s.Value, s.Key)
+	return err
+}
+
+// Idempotent 幂等，例如 @IDEMPOTENT(header=Idempotency-Key; ttl=24h)
+type Idempotent struct {
+	Header string // 默认 Idempotency-Key
+	TTL    string // 默认 24h
+}
+
+func (s Idempotent) Name() string    { return "IDEMPOTENT" }
+func (s Idempotent) Mode() ParseMode { return ModeNamed }
+func (s *Idempotent) Validate() error {
+	_, err := swaggen.ParseIdempotency(s.Header, s.TTL)
+	return err
+}
@@ -7,7 +7,7 @@ import (
 
 func TestTag(t *testing.T) {
 	parser := NewParser()
-	err := parser.Register(Tag{}, Security{}, GET{}, Header{}, FormReq{}, Removed{})
+	err := parser.Register(Tag{}, Security{}, GET{}, Header{}, FormReq{}, Removed{}, RateLimit{}, Idempotent{})
 	if err != nil {
 		panic(err)
 	}
@@ -38,6 +38,12 @@ func TestTag(t *testing.T) {
 		"// @FORM-REQ", // 成功: 类型=*parsers.FormReq, 值=&parsers.FormReq{}
 		"// @SECURITY", // 验证失败 (标签: SECURITY): 字段 'Value' 是必须的，但值为空
 		"@Removed",
+
+		// --- 流量控制 ---
+		"// @RATELIMIT(100/min; key=header:X-Api-Key)",
+		"// @RATELIMIT(10/week)", // 验证失败: 未知单位
+		"// @IDEMPOTENT(header=Idempotency-Key; ttl=24h)",
+		"// @IDEMPOTENT",
 	}
 
 	for _, tc := range testCases {
@@ -50,3 +56,36 @@ func TestTag(t *testing.T) {
 		}
 	}
 }
+
+func TestTrafficTags(t *testing.T) {
+	parser := NewParser()
+	if err := parser.Register(RateLimit{}, Idempotent{}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := parser.Parse("// @RATELIMIT(100/min; key=header:X-Api-Key)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rl := result.(*RateLimit); rl.Value != "100/min" || rl.Key != "header:X-Api-Key" {
+		t.Fatalf("unexpected result: %#v", rl)
+	}
+
+	result, err = parser.Parse("// @IDEMPOTENT(header=X-Request-Id; ttl=1h)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idem := result.(*Idempotent); idem.Header != "X-Request-Id" || idem.TTL != "1h" {
+		t.Fatalf("unexpected result: %#v", idem)
+	}
+
+	for _, line := range []string{
+		"// @RATELIMIT(100)",
+		"// @RATELIMIT(100/min; key=cookie:sid)",
+		"// @IDEMPOTENT(ttl=forever)",
+	} {
+		if _, err := parser.Parse(line); err == nil {
+			t.Fatalf("expected error for %s", line)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/donutnomad/gotoolkit/lib/swaggen"
 	parsers "github.com/donutnomad/gotoolkit/swagGen/parser"
 	"github.com/samber/lo"
 )
@@ -162,6 +163,16 @@ func (g *SwaggerGenerator) generateMethodComments(method SwaggerMethod, iface Sw
 	successLine := g.generateSuccessComment(method.ResponseType)
 	lines = append(lines, successLine)
 
+	// 限流/幂等的失败响应，按状态码排序
+	if v := method.GetIdempotent(iface); v != nil {
+		policy := swaggen.MustParseIdempotency(v.Header, v.TTL)
+		lines = append(lines, fmt.Sprintf("// @Failure 400 {string} string \"missing header %s\"", policy.Header))
+		lines = append(lines, "// @Failure 409 {string} string \"duplicate request\"")
+	}
+	if v := method.GetRateLimit(iface); v != nil {
+		lines = append(lines, fmt.Sprintf("// @Failure 429 {string} string \"too many requests, limit %s\"", v.Value))
+	}
+
 	prefix := iface.CommonDef.GetPrefix()
 
 	// Router
@@ -213,6 +224,22 @@ func (g *SwaggerGenerator) generateParameterComments(method SwaggerMethod, param
 			}
 		}
 	}
+	// 限流/幂等依赖的请求头
+	if v, ok := FirstDef[*parsers.Idempotent](def, ifaceDef); ok {
+		policy := swaggen.MustParseIdempotency(v.Header, v.TTL)
+		if _, exists := headerMap[policy.Header]; !exists {
+			ttl := lo.Ternary(v.TTL != "", v.TTL, "24h")
+			headerMap[policy.Header] = &parsers.Header{Value: policy.Header, Required: true, Description: "Idempotency key, duplicate requests within " + ttl + " are rejected"}
+			headerNames = append(headerNames, policy.Header)
+		}
+	}
+	if v, ok := FirstDef[*parsers.RateLimit](def, ifaceDef); ok {
+		name := swaggen.MustParseRateLimit(v.Value, v.Key).HeaderName()
+		if _, exists := headerMap[name]; name != "" && !exists {
+			headerMap[name] = &parsers.Header{Value: name, Required: false, Description: "Rate limit key, falls back to client IP"}
+			headerNames = append(headerNames, name)
+		}
+	}
 	for _, key := range headerNames {
 		value := headerMap[key]
 		headerLine := fmt.Sprintf("// @Param %s header string %s \"%s\"", key, lo.Ternary(value.Required, "true", "false"), lo.Ternary(len(value.Description) > 0, value.Description, key))
@@ -313,6 +340,13 @@ func (g *SwaggerGenerator) GenerateImports() string {
 	g.collection.ImportMgr.AddImport("github.com/gin-gonic/gin")
 	g.collection.ImportMgr.AddImport("strings")
 
+	// 限流/幂等中间件运行时
+	if lo.ContainsBy(g.collection.Interfaces, func(iface SwaggerInterface) bool {
+		return iface.HasRateLimit() || iface.HasIdempotent()
+	}) {
+		g.collection.ImportMgr.AddImport("github.com/donutnomad/gotoolkit/lib/swaggen")
+	}
+
 	// 检查是否需要 cast 导入
 	if g.needsCastImport() {
 		g.collection.ImportMgr.AddImport("github.com/spf13/cast")
@@ -0,0 +1,26 @@
+package traffic
+
+import "context"
+
+type OrderResponse struct {
+	ID string `json:"id"`
+}
+
+type CreateOrderReq struct {
+	Name string `json:"name"`
+}
+
+// @RATELIMIT(100/min; key=header:X-Api-Key)
+type IOrderAPI interface {
+	// @GET(/api/v1/order/{id})
+	GetOrder(ctx context.Context, id string) OrderResponse
+
+	// @POST(/api/v1/order)
+	// @IDEMPOTENT(header=Idempotency-Key; ttl=24h)
+	CreateOrder(ctx context.Context, req CreateOrderReq) OrderResponse
+}
+
+type IUserAPI interface {
+	// @GET(/api/v1/user/{id})
+	GetUser(ctx context.Context, id string) OrderResponse
+}
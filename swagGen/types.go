@@ -6,6 +6,7 @@ import (
 
 	"github.com/donutnomad/gotoolkit/internal/xast"
 	parsers "github.com/donutnomad/gotoolkit/swagGen/parser"
+	"github.com/samber/lo"
 )
 
 // TypeInfo 表示类型信息
@@ -66,6 +67,18 @@ func FindDef[T any](inputs ...DefSlice) bool {
 	return false
 }
 
+func FirstDef[T any](inputs ...DefSlice) (T, bool) {
+	for _, input := range inputs {
+		for _, item := range input {
+			if v, ok := item.(T); ok {
+				return v, true
+			}
+		}
+	}
+	var zero T
+	return zero, false
+}
+
 type DefSlice []parsers.Definition
 
 func (s DefSlice) GetPrefix() string {
@@ -115,6 +128,18 @@ func (s DefSlice) GetContentType() (string, bool) {
 	return "json", false
 }
 
+// GetRateLimit 获取限流配置，方法级别优先于接口级别
+func (s SwaggerMethod) GetRateLimit(iface SwaggerInterface) *parsers.RateLimit {
+	v, _ := FirstDef[*parsers.RateLimit](s.Def, iface.CommonDef)
+	return v
+}
+
+// GetIdempotent 获取幂等配置，方法级别优先于接口级别
+func (s SwaggerMethod) GetIdempotent(iface SwaggerInterface) *parsers.Idempotent {
+	v, _ := FirstDef[*parsers.Idempotent](s.Def, iface.CommonDef)
+	return v
+}
+
 func (s SwaggerMethod) GetPaths() []string {
 	var ret []string
 	for _, item := range s.Def {
@@ -178,6 +203,16 @@ func (w SwaggerInterface) GetWrapperName() string {
 	return n
 }
 
+// HasRateLimit 是否有方法使用了 @RATELIMIT
+func (w SwaggerInterface) HasRateLimit() bool {
+	return lo.ContainsBy(w.Methods, func(m SwaggerMethod) bool { return m.GetRateLimit(w) != nil })
+}
+
+// HasIdempotent 是否有方法使用了 @IDEMPOTENT
+func (w SwaggerInterface) HasIdempotent() bool {
+	return lo.ContainsBy(w.Methods, func(m SwaggerMethod) bool { return m.GetIdempotent(w) != nil })
+}
+
 // InterfaceCollection 表示接口集合
 type InterfaceCollection struct {
 	Interfaces []SwaggerInterface     // 接口列表